@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/arcana-network/dkgnode/server"
+)
+
+const (
+	contentType = "application/json; charset=utf-8"
+	// maxBodyBytes caps the size of admin request bodies.
+	maxBodyBytes = 4096
+)
+
+// AdminService serves the admin API, on which operators inspect the running
+// node and act on it. It listens apart from the JSON-RPC server and every
+// request has to be signed by one of the configured operators.
+type AdminService struct {
+	bus     eventbus.Bus
+	broker  *common.MessageBroker
+	server  *http.Server
+	auth    *authenticator
+	running atomic.Bool
+}
+
+func New(bus eventbus.Bus) *AdminService {
+	return &AdminService{
+		bus:    bus,
+		broker: common.NewServiceBroker(bus, common.ADMIN_SERVICE_NAME),
+		auth: newAuthenticator(func() []string {
+			return config.Current().AdminOperators
+		}),
+	}
+}
+
+func (s *AdminService) ID() string {
+	return common.ADMIN_SERVICE_NAME
+}
+
+// Start serves the admin API, over TLS with the certificate of the RPC
+// server when one is configured.
+func (s *AdminService) Start() error {
+	s.running.Store(true)
+	conf := config.Current()
+	addr := conf.AdminListenAddress
+	if addr == "" {
+		log.Info("admin API disabled")
+		return nil
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if conf.TLSCertFile != "" {
+		tlsConfig, err := server.TLSConfig(conf.TLSCertFile, conf.TLSKeyFile, conf.TLSClientCAFile)
+		if err != nil {
+			return err
+		}
+		s.server.TLSConfig = tlsConfig
+	}
+	go func() {
+		var err error
+		if s.server.TLSConfig != nil {
+			err = s.server.ListenAndServeTLS("", "")
+		} else {
+			err = s.server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Error("AdminService.ListenAndServe()")
+			s.running.Store(false)
+		}
+	}()
+	log.WithField("addr", addr).Info("admin API listening")
+	return nil
+}
+
+func (s *AdminService) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(context.Background())
+}
+
+func (s *AdminService) IsRunning() bool {
+	return s.running.Load()
+}
+
+func (s *AdminService) Call(method string, args ...interface{}) (interface{}, error) {
+	return nil, errors.New("admin service has no methods")
+}
+
+type handlerFunc func(broker *common.MessageBroker, r *http.Request, body []byte) (interface{}, error)
+
+func (s *AdminService) router() http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/sessions", s.handle(sessions)).Methods(http.MethodGet)
+	router.Handle("/sessions/{id}/cancel", s.handle(cancelSession)).Methods(http.MethodPost)
+	router.Handle("/peers", s.handle(peers)).Methods(http.MethodGet)
+	router.Handle("/epoch", s.handle(epoch)).Methods(http.MethodGet)
+	router.Handle("/epoch/refresh", s.handle(refreshEpoch)).Methods(http.MethodPost)
+	router.Handle("/key-buffer", s.handle(keyBuffer)).Methods(http.MethodGet)
+	router.Handle("/key-buffer/policy", s.handle(voteBufferPolicy)).Methods(http.MethodPut)
+	router.Handle("/queue", s.handle(queue)).Methods(http.MethodGet)
+	router.Handle("/log-level", s.handle(logLevel)).Methods(http.MethodGet)
+	router.Handle("/log-level", s.handle(setLogLevel)).Methods(http.MethodPut)
+	return router
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Error("admin: could not write response")
+	}
+}
+
+// handle authenticates a request before serving it.
+func (s *AdminService) handle(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"could not read request body"})
+			return
+		}
+		operator, err := s.auth.verify(r, body, time.Now())
+		if err != nil {
+			log.WithError(err).WithField("RemoteAddr", r.RemoteAddr).Warn("admin: request rejected")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{err.Error()})
+			return
+		}
+		log.WithFields(log.Fields{
+			"operator": operator.Hex(),
+			"method":   r.Method,
+			"path":     r.URL.Path,
+		}).Info("admin: request")
+
+		result, err := h(s.broker.WithContext(r.Context()), r, body)
+		if err != nil {
+			var badRequest badRequestError
+			if errors.As(err, &badRequest) {
+				writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	TimestampHeader = "X-Admin-Timestamp"
+	SignatureHeader = "X-Admin-Signature"
+
+	// maxClockSkew is how far the timestamp of a signed request may be from
+	// the node clock. Requests are not accepted twice within it.
+	maxClockSkew = time.Minute
+)
+
+// signedMessage is what an operator signs for a request, as an Ethereum
+// signed message so that any wallet can sign it.
+func signedMessage(method, uri, timestamp string, body []byte) []byte {
+	bodyHash := hex.EncodeToString(crypto.Keccak256(body))
+	return []byte(strings.Join([]string{method, uri, timestamp, bodyHash}, "\n"))
+}
+
+// SignRequest signs an admin API request with an operator key.
+func SignRequest(r *http.Request, body []byte, key *ecdsa.PrivateKey) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	hash := accounts.TextHash(signedMessage(r.Method, r.URL.RequestURI(), timestamp, body))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return err
+	}
+	// Wallets produce v as 27 or 28
+	sig[crypto.RecoveryIDOffset] += 27
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(SignatureHeader, "0x"+hex.EncodeToString(sig))
+	return nil
+}
+
+// authenticator checks that requests are signed by an operator.
+type authenticator struct {
+	operators func() []string
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newAuthenticator(operators func() []string) *authenticator {
+	return &authenticator{operators: operators, seen: make(map[string]time.Time)}
+}
+
+// verify returns the operator that signed a request.
+func (a *authenticator) verify(r *http.Request, body []byte, now time.Time) (ethCommon.Address, error) {
+	timestamp := r.Header.Get(TimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ethCommon.Address{}, errors.New("missing or invalid timestamp")
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return ethCommon.Address{}, errors.New("timestamp too far from the node clock")
+	}
+	sigHex := r.Header.Get(SignatureHeader)
+	sig, err := hex.DecodeString(strings.TrimPrefix(sigHex, "0x"))
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return ethCommon.Address{}, errors.New("missing or invalid signature")
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	// Only low s signatures are accepted, so that a request has one valid
+	// signature.
+	rValue, sValue := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(sig[crypto.RecoveryIDOffset], rValue, sValue, true) {
+		return ethCommon.Address{}, errors.New("invalid signature values")
+	}
+	hash := accounts.TextHash(signedMessage(r.Method, r.URL.RequestURI(), timestamp, body))
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return ethCommon.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if !a.isOperator(signer) {
+		return ethCommon.Address{}, fmt.Errorf("%s is not an operator", signer)
+	}
+	// The signed message is unique to a request, as opposed to the
+	// signature encoding.
+	if !a.firstUse(hex.EncodeToString(hash), now) {
+		return ethCommon.Address{}, errors.New("request already served")
+	}
+	return signer, nil
+}
+
+func (a *authenticator) isOperator(signer ethCommon.Address) bool {
+	for _, operator := range a.operators() {
+		if ethCommon.HexToAddress(operator) == signer {
+			return true
+		}
+	}
+	return false
+}
+
+// firstUse records the hash of a signed request and reports whether it was
+// not seen before.
+func (a *authenticator) firstUse(hash string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for seen, at := range a.seen {
+		if now.Sub(at) > 2*maxClockSkew {
+			delete(a.seen, seen)
+		}
+	}
+	if _, ok := a.seen[hash]; ok {
+		return false
+	}
+	a.seen[hash] = now
+	return true
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/eventbus"
+)
+
+func TestAuthenticatorVerify(t *testing.T) {
+	operatorKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	operator := crypto.PubkeyToAddress(operatorKey.PublicKey)
+	auth := newAuthenticator(func() []string { return []string{operator.Hex()} })
+
+	body := []byte(`{"level":"debug"}`)
+	signed := func(key *ecdsa.PrivateKey) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "/log-level", bytes.NewReader(body))
+		if err := SignRequest(r, body, key); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	now := time.Now()
+
+	r := signed(operatorKey)
+	signer, err := auth.verify(r, body, now)
+	if err != nil || signer != operator {
+		t.Fatalf("verify() = %s, %v", signer.Hex(), err)
+	}
+	if _, err := auth.verify(r, body, now); err == nil {
+		t.Error("replayed request was accepted")
+	}
+	// The same signature with s negated is valid for the same message.
+	sig, _ := hex.DecodeString(strings.TrimPrefix(r.Header.Get(SignatureHeader), "0x"))
+	highS := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+	highS.FillBytes(sig[32:64])
+	sig[crypto.RecoveryIDOffset] ^= 1
+	r.Header.Set(SignatureHeader, "0x"+hex.EncodeToString(sig))
+	if _, err := auth.verify(r, body, now); err == nil {
+		t.Error("replayed request with a malleated signature was accepted")
+	}
+	if _, err := auth.verify(signed(otherKey), body, now); err == nil {
+		t.Error("request signed by another key was accepted")
+	}
+	if _, err := auth.verify(signed(operatorKey), []byte(`{"level":"trace"}`), now); err == nil {
+		t.Error("request with another body was accepted")
+	}
+	if _, err := auth.verify(signed(operatorKey), body, now.Add(2*maxClockSkew)); err == nil {
+		t.Error("stale request was accepted")
+	}
+	unsigned := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	unsigned.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	if _, err := auth.verify(unsigned, nil, now); err == nil {
+		t.Error("unsigned request was accepted")
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	operatorKey, _ := crypto.GenerateKey()
+	conf := config.GetDefaultConfig()
+	conf.AdminOperators = []string{crypto.PubkeyToAddress(operatorKey.PublicKey).Hex()}
+	previousConf, previousLevel := config.GlobalConfig, log.GetLevel()
+	config.GlobalConfig = conf
+	defer func() {
+		config.GlobalConfig = previousConf
+		log.SetLevel(previousLevel)
+	}()
+
+	server := httptest.NewServer(New(eventbus.New()).router())
+	defer server.Close()
+
+	body := []byte(`{"level":"debug"}`)
+	put := func(sign bool) int {
+		r, _ := http.NewRequest(http.MethodPut, server.URL+"/log-level", bytes.NewReader(body))
+		if sign {
+			if err := SignRequest(r, body, operatorKey); err != nil {
+				t.Fatal(err)
+			}
+		}
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := put(false); code != http.StatusUnauthorized {
+		t.Errorf("unsigned request = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := put(true); code != http.StatusOK {
+		t.Fatalf("signed request = %d", code)
+	}
+	if log.GetLevel() != log.DebugLevel {
+		t.Errorf("log level = %s, want debug", log.GetLevel())
+	}
+}
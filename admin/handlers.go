@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+// badRequestError is returned by handlers for invalid input.
+type badRequestError struct {
+	msg string
+}
+
+func (e badRequestError) Error() string {
+	return e.msg
+}
+
+type EpochResult struct {
+	Current int              `json:"current"`
+	Info    common.EpochInfo `json:"info"`
+}
+
+type LogLevelParams struct {
+	Level string `json:"level"`
+}
+
+// BufferPolicyVoteParams is the key buffer policy an operator votes for.
+type BufferPolicyVoteParams struct {
+	Curve common.CurveName `json:"curve"`
+	config.KeyBufferPolicy
+}
+
+type BufferPolicyVoteResult struct {
+	TxHash string `json:"tx_hash"`
+}
+
+type BufferPolicyVoteTx struct {
+	Curve  common.CurveName
+	Policy config.KeyBufferPolicy
+}
+
+func sessions(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	return broker.KeygenMethods().Sessions()
+}
+
+func cancelSession(broker *common.MessageBroker, r *http.Request, _ []byte) (interface{}, error) {
+	id := common.ADKGID(mux.Vars(r)["id"])
+	if _, err := id.GetIndex(); err != nil {
+		return nil, badRequestError{"invalid session id"}
+	}
+	if err := broker.KeygenMethods().CancelSession(id); err != nil {
+		return nil, err
+	}
+	return map[string]common.ADKGID{"cancelled": id}, nil
+}
+
+func peers(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	return broker.P2PMethods().Peers()
+}
+
+func epoch(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	current, err := broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return nil, err
+	}
+	info, err := broker.ChainMethods().GetEpochInfo(current, false)
+	if err != nil {
+		return nil, err
+	}
+	return EpochResult{Current: current, Info: info}, nil
+}
+
+func refreshEpoch(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	info, err := broker.ChainMethods().RefreshEpoch()
+	if err != nil {
+		return nil, err
+	}
+	return EpochResult{Current: int(info.Id.Int64()), Info: info}, nil
+}
+
+func keyBuffer(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	return broker.ABCIMethods().KeyBufferStatus()
+}
+
+// voteBufferPolicy casts the vote of this node for the key buffer policy of
+// a curve. The policy applies on every node once a threshold voted for it.
+func voteBufferPolicy(broker *common.MessageBroker, _ *http.Request, body []byte) (interface{}, error) {
+	var p BufferPolicyVoteParams
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, badRequestError{"invalid body"}
+	}
+	if p.Curve != common.SECP256K1 && p.Curve != common.ED25519 {
+		return nil, badRequestError{"unknown curve"}
+	}
+	if err := p.KeyBufferPolicy.Verify(string(p.Curve)); err != nil {
+		return nil, badRequestError{err.Error()}
+	}
+	hash, err := broker.TendermintMethods().Broadcast(BufferPolicyVoteTx{Curve: p.Curve, Policy: p.KeyBufferPolicy})
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"curve":  p.Curve,
+		"policy": p.KeyBufferPolicy,
+	}).Warn("admin: key buffer policy vote cast")
+	return BufferPolicyVoteResult{TxHash: hash.String()}, nil
+}
+
+func queue(broker *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	return broker.TendermintMethods().QueueStatus()
+}
+
+func logLevel(_ *common.MessageBroker, _ *http.Request, _ []byte) (interface{}, error) {
+	return LogLevelParams{Level: log.GetLevel().String()}, nil
+}
+
+// setLogLevel changes the log level until the next restart or config reload.
+func setLogLevel(_ *common.MessageBroker, _ *http.Request, body []byte) (interface{}, error) {
+	var p LogLevelParams
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, badRequestError{"invalid body"}
+	}
+	level, err := log.ParseLevel(p.Level)
+	if err != nil {
+		return nil, badRequestError{err.Error()}
+	}
+	log.SetLevel(level)
+	log.WithField("level", level).Warn("admin: log level changed")
+	return LogLevelParams{Level: level.String()}, nil
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/eventbus"
+)
+
+// AuditService keeps the audit log of share requests. Records are signed
+// with the node key through the chain service.
+type AuditService struct {
+	bus    eventbus.Bus
+	broker *common.MessageBroker
+	log    *Log
+}
+
+func New(bus eventbus.Bus) *AuditService {
+	return &AuditService{
+		bus:    bus,
+		broker: common.NewServiceBroker(bus, common.AUDIT_SERVICE_NAME),
+	}
+}
+
+// LogPath is where the audit log of a node with the given data directory is.
+func LogPath(basePath string) string {
+	return fmt.Sprintf("%s/audit/share_releases.jsonl", basePath)
+}
+
+func (a *AuditService) ID() string {
+	return common.AUDIT_SERVICE_NAME
+}
+
+func (a *AuditService) Start() error {
+	pubKey, err := a.broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return err
+	}
+	signer := common.PointToEthAddress(pubKey)
+	l, err := Open(LogPath(config.Current().BasePath), signer, a.broker.ChainMethods().SignHash)
+	if err != nil {
+		return err
+	}
+	a.log = l
+	log.WithField("path", LogPath(config.Current().BasePath)).Info("audit log opened")
+	return nil
+}
+
+func (a *AuditService) Stop() error {
+	if a.log == nil {
+		return nil
+	}
+	return a.log.Close()
+}
+
+func (a *AuditService) IsRunning() bool {
+	return a.log != nil
+}
+
+func (a *AuditService) Call(method string, args ...interface{}) (interface{}, error) {
+	switch method {
+	case "record_share_release":
+		var release common.ShareRelease
+		_ = common.CastOrUnmarshal(args[0], &release)
+		_, err := a.log.Append(release)
+		return nil, err
+	}
+	return nil, fmt.Errorf("audit service method %v not found", method)
+}
@@ -0,0 +1,285 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+// GenesisHash is the previous hash of the first record of a log.
+var GenesisHash = "0x" + strings.Repeat("0", 64)
+
+// maxRecordBytes bounds the length of a line when reading a log.
+const maxRecordBytes = 64 * 1024
+
+// Record is an entry of the audit log. Every record holds the hash of the
+// one before it, and is signed by the node that wrote it, so that removing,
+// reordering or editing records breaks the chain.
+type Record struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	common.ShareRelease
+	PrevHash  string `json:"prev_hash"`
+	Signer    string `json:"signer"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+}
+
+// ComputeHash returns the hash of a record, which covers every field but
+// the hash and the signature.
+func (r Record) ComputeHash() ([]byte, error) {
+	r.Hash, r.Signature = "", ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(data), nil
+}
+
+// SignFunc signs a 32 byte digest with the node key.
+type SignFunc func(hash []byte) ([]byte, error)
+
+// logFile is the file a log is written to.
+type logFile interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+	Name() string
+}
+
+// Log appends records to a JSON lines file.
+type Log struct {
+	mu   sync.Mutex
+	file logFile
+	// offset is the end of the last complete record.
+	offset   int64
+	signer   ethCommon.Address
+	sign     SignFunc
+	nextSeq  uint64
+	lastHash string
+	now      func() time.Time
+}
+
+// Open opens the log at path, creating it if needed, and checks the records
+// already in it. A last line left incomplete by a crash is dropped, as it
+// was never acknowledged.
+func Open(path string, signer ethCommon.Address, sign SignFunc) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{file: file, signer: signer, sign: sign, lastHash: GenesisHash, now: time.Now}
+	if err := l.recover(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) recover() error {
+	data, err := io.ReadAll(l.file)
+	if err != nil {
+		return err
+	}
+	complete := len(data)
+	if i := bytes.LastIndexByte(data, '\n'); i+1 != len(data) {
+		complete = i + 1
+		log.WithField("bytes", len(data)-complete).Warn("audit: dropping incomplete last record")
+		if err := l.file.Truncate(int64(complete)); err != nil {
+			return err
+		}
+	}
+	result, err := Verify(bytes.NewReader(data[:complete]), nil)
+	if err != nil {
+		return fmt.Errorf("audit log %s is invalid: %w", l.file.Name(), err)
+	}
+	if result.Records > 0 {
+		l.nextSeq = result.LastSeq + 1
+		l.lastHash = result.LastHash
+	}
+	l.offset = int64(complete)
+	_, err = l.file.Seek(l.offset, io.SeekStart)
+	return err
+}
+
+// Append signs a record of release and writes it to the log. It returns once
+// the record is on disk.
+func (l *Log) Append(release common.ShareRelease) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record := Record{
+		Seq:          l.nextSeq,
+		Time:         l.now().UTC(),
+		ShareRelease: release,
+		PrevHash:     l.lastHash,
+		Signer:       l.signer.Hex(),
+	}
+	hash, err := record.ComputeHash()
+	if err != nil {
+		return Record{}, err
+	}
+	sig, err := l.sign(hash)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not sign audit record: %w", err)
+	}
+	record.Hash = "0x" + hex.EncodeToString(hash)
+	record.Signature = "0x" + hex.EncodeToString(sig)
+	line, err := json.Marshal(record)
+	if err != nil {
+		return Record{}, err
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return Record{}, l.rollback(err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return Record{}, l.rollback(err)
+	}
+	l.offset += int64(len(line))
+	l.nextSeq++
+	l.lastHash = record.Hash
+	return record, nil
+}
+
+// rollback drops what a failed write left after the last complete record, so
+// that the next record does not follow a fragment.
+func (l *Log) rollback(err error) error {
+	if terr := l.file.Truncate(l.offset); terr != nil {
+		return fmt.Errorf("%v, and could not drop the partial record: %w", err, terr)
+	}
+	if _, serr := l.file.Seek(l.offset, io.SeekStart); serr != nil {
+		return fmt.Errorf("%v, and could not drop the partial record: %w", err, serr)
+	}
+	return err
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// VerifyResult sums up a verified log.
+type VerifyResult struct {
+	Records  int
+	FirstSeq uint64
+	LastSeq  uint64
+	LastHash string
+}
+
+// Verify reads records and checks that each is signed, hashes to its hash
+// and links to the one before it. The records may be a range exported from
+// a log; a range starting at the first record has to link to GenesisHash.
+// If signer is set, every record has to be signed by it.
+func Verify(r io.Reader, signer *ethCommon.Address) (VerifyResult, error) {
+	var result VerifyResult
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxRecordBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := verifyRecord(record, signer); err != nil {
+			return result, fmt.Errorf("line %d, seq %d: %w", line, record.Seq, err)
+		}
+		switch {
+		case result.Records == 0 && record.Seq == 0 && record.PrevHash != GenesisHash:
+			return result, fmt.Errorf("line %d: first record does not start the chain", line)
+		case result.Records > 0 && record.Seq != result.LastSeq+1:
+			return result, fmt.Errorf("line %d: seq %d follows %d", line, record.Seq, result.LastSeq)
+		case result.Records > 0 && record.PrevHash != result.LastHash:
+			return result, fmt.Errorf("line %d, seq %d: chain broken", line, record.Seq)
+		}
+		if result.Records == 0 {
+			result.FirstSeq = record.Seq
+		}
+		result.Records++
+		result.LastSeq = record.Seq
+		result.LastHash = record.Hash
+	}
+	return result, scanner.Err()
+}
+
+func verifyRecord(record Record, signer *ethCommon.Address) error {
+	hash, err := record.ComputeHash()
+	if err != nil {
+		return err
+	}
+	if record.Hash != "0x"+hex.EncodeToString(hash) {
+		return errors.New("hash does not match the record")
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(record.Signature, "0x"))
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return errors.New("invalid signature")
+	}
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	recovered := crypto.PubkeyToAddress(*pubKey)
+	if !ethCommon.IsHexAddress(record.Signer) || recovered != ethCommon.HexToAddress(record.Signer) {
+		return fmt.Errorf("signed by %s, not by %s", recovered, record.Signer)
+	}
+	if signer != nil && recovered != *signer {
+		return fmt.Errorf("signed by %s, want %s", recovered, signer)
+	}
+	return nil
+}
+
+// Filter selects the records to export. Zero values do not filter.
+type Filter struct {
+	FromSeq, ToSeq uint64
+	Since, Until   time.Time
+}
+
+func (f Filter) match(r Record) bool {
+	return r.Seq >= f.FromSeq &&
+		(f.ToSeq == 0 || r.Seq <= f.ToSeq) &&
+		(f.Since.IsZero() || !r.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || r.Time.Before(f.Until))
+}
+
+// Export copies the records matching filter from r to w unchanged, so that
+// the range can be verified on its own. It returns the number of records
+// written.
+func Export(r io.Reader, w io.Writer, filter Filter) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxRecordBytes)
+	written := 0
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return written, fmt.Errorf("record %d: %w", written, err)
+		}
+		if !filter.match(record) {
+			continue
+		}
+		if _, err := w.Write(append(scanner.Bytes(), '\n')); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, scanner.Err()
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+func TestLogChain(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(hash []byte) ([]byte, error) { return crypto.Sign(hash, key) }
+	path := filepath.Join(t.TempDir(), "audit", "share_releases.jsonl")
+
+	l, err := Open(path, signer, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		l.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		if _, err := l.Append(common.ShareRelease{KeyIndex: "1", AppID: "app", Outcome: common.ShareReleased}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	// Reopening continues the chain, even after a crash mid-write.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	_, _ = f.WriteString(`{"seq":3,"ti`)
+	f.Close()
+	l, err = Open(path, signer, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return start.Add(3 * time.Hour) }
+	if record, err := l.Append(common.ShareRelease{KeyIndex: "2", Outcome: common.ShareRefused}); err != nil || record.Seq != 3 {
+		t.Fatalf("Append() = %d, %v", record.Seq, err)
+	}
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	result, err := Verify(bytes.NewReader(data), &signer)
+	if err != nil || result.Records != 4 {
+		t.Fatalf("Verify() = %+v, %v", result, err)
+	}
+
+	other := crypto.PubkeyToAddress(key.PublicKey)
+	other[0]++
+	if _, err := Verify(bytes.NewReader(data), &other); err == nil {
+		t.Error("log signed by another node was accepted")
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	edited := strings.Replace(string(data), `"app_id":"app"`, `"app_id":"other"`, 1)
+	if _, err := Verify(strings.NewReader(edited), nil); err == nil {
+		t.Error("edited record was accepted")
+	}
+	if _, err := Verify(strings.NewReader(lines[0]+lines[2]+lines[3]), nil); err == nil {
+		t.Error("log with a removed record was accepted")
+	}
+	if _, err := Verify(strings.NewReader(lines[1]+lines[2]), nil); err != nil {
+		t.Errorf("range in the middle of the log was rejected: %v", err)
+	}
+
+	var exported bytes.Buffer
+	n, err := Export(bytes.NewReader(data), &exported, Filter{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)})
+	if err != nil || n != 2 {
+		t.Fatalf("Export() = %d, %v", n, err)
+	}
+	if result, err := Verify(&exported, &signer); err != nil || result.FirstSeq != 1 || result.LastSeq != 2 {
+		t.Errorf("Verify(export) = %+v, %v", result, err)
+	}
+}
+
+// shortFile writes half of the next write and fails it.
+type shortFile struct {
+	*os.File
+	fail bool
+}
+
+func (f *shortFile) Write(p []byte) (int, error) {
+	if f.fail {
+		f.fail = false
+		n, _ := f.File.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return f.File.Write(p)
+}
+
+func TestLogFailedAppend(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(hash []byte) ([]byte, error) { return crypto.Sign(hash, key) }
+	path := filepath.Join(t.TempDir(), "share_releases.jsonl")
+
+	l, err := Open(path, signer, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(common.ShareRelease{KeyIndex: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	file := &shortFile{File: l.file.(*os.File), fail: true}
+	l.file = file
+	if _, err := l.Append(common.ShareRelease{KeyIndex: "2"}); err == nil {
+		t.Fatal("failed write was acknowledged")
+	}
+	if record, err := l.Append(common.ShareRelease{KeyIndex: "3"}); err != nil || record.Seq != 1 {
+		t.Fatalf("Append() after a failed write = %d, %v", record.Seq, err)
+	}
+	l.Close()
+
+	if l, err = Open(path, signer, sign); err != nil {
+		t.Fatalf("log after a failed write is invalid: %v", err)
+	}
+	l.Close()
+}
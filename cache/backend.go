@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/telemetry"
+)
+
+// Namespaces of the cache service. Each has its own TTL and size cap.
+const (
+	NamespaceToken     = "token"
+	NamespaceSigner    = "signer"
+	NamespaceVerifier  = "verifier"
+	NamespacePartition = "partition"
+	NamespaceBuffer    = "buffer"
+)
+
+var (
+	// ErrExists is returned by Add when the key is already set.
+	ErrExists = errors.New("cache entry already exists")
+	// ErrFull is returned when a new entry is refused by a full namespace
+	// that does not evict.
+	ErrFull = errors.New("cache namespace is full")
+)
+
+// noEviction are the namespaces that guard against replays. Evicting one of
+// their entries before it expires would let the replay through, so new
+// entries are refused while they are full.
+var noEviction = map[string]bool{
+	NamespaceToken:  true,
+	NamespaceSigner: true,
+}
+
+// DefaultLimits are the limits of the namespaces without configured ones.
+var DefaultLimits = map[string]config.CacheLimit{
+	NamespaceToken:     {TTL: 90 * 60, MaxEntries: 100000},
+	NamespaceSigner:    {TTL: 60, MaxEntries: 100000},
+	NamespaceVerifier:  {TTL: 5 * 60, MaxEntries: 10000},
+	NamespacePartition: {TTL: 5 * 60, MaxEntries: 10000},
+	NamespaceBuffer:    {TTL: 60 * 60, MaxEntries: 1},
+}
+
+// Backend stores the entries of the cache service. Entries expire after the
+// TTL of their namespace, and the least recently used entries are evicted
+// when a namespace is full, except in the replay guard namespaces which
+// return ErrFull instead.
+type Backend interface {
+	Get(namespace, key string) ([]byte, bool)
+	// Set stores a value, replacing the one set before.
+	Set(namespace, key string, value []byte) error
+	// Add stores a value unless the key is set, in which case it returns
+	// ErrExists.
+	Add(namespace, key string, value []byte) error
+	Close() error
+}
+
+// Limits returns the limits of every namespace, the configured ones taking
+// precedence over DefaultLimits field by field.
+func Limits(configured map[string]config.CacheLimit) map[string]config.CacheLimit {
+	limits := make(map[string]config.CacheLimit, len(DefaultLimits))
+	for namespace, limit := range DefaultLimits {
+		if c, ok := configured[namespace]; ok {
+			if c.TTL > 0 {
+				limit.TTL = c.TTL
+			}
+			if c.MaxEntries > 0 {
+				limit.MaxEntries = c.MaxEntries
+			}
+		}
+		limits[namespace] = limit
+	}
+	return limits
+}
+
+type entry struct {
+	namespace string
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lru holds the entries of a namespace, most recently used first.
+type lru struct {
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newLRU() *lru {
+	return &lru{ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// MemoryBackend keeps entries in memory, bounded by the size caps.
+type MemoryBackend struct {
+	mu     sync.Mutex
+	limits map[string]config.CacheLimit
+	spaces map[string]*lru
+	now    func() time.Time
+	// onInsert and onRemove are called with every entry that is stored or
+	// removed, under mu.
+	onInsert func(e *entry) error
+	onRemove func(e *entry)
+}
+
+func NewMemoryBackend(limits map[string]config.CacheLimit) *MemoryBackend {
+	m := &MemoryBackend{
+		limits: limits,
+		spaces: make(map[string]*lru),
+		now:    time.Now,
+	}
+	for namespace := range limits {
+		m.spaces[namespace] = newLRU()
+	}
+	return m
+}
+
+func (m *MemoryBackend) space(namespace string) *lru {
+	s, ok := m.spaces[namespace]
+	if !ok {
+		s = newLRU()
+		m.spaces[namespace] = s
+	}
+	return s
+}
+
+// lookup returns the live entry of a key, removing it if it expired.
+func (m *MemoryBackend) lookup(namespace, key string) *list.Element {
+	s := m.space(namespace)
+	el, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if !m.now().Before(el.Value.(*entry).expiresAt) {
+		m.remove(s, el, "expired")
+		return nil
+	}
+	return el
+}
+
+func (m *MemoryBackend) remove(s *lru, el *list.Element, reason string) {
+	e := el.Value.(*entry)
+	s.ll.Remove(el)
+	delete(s.items, e.key)
+	telemetry.CountCacheEviction(e.namespace, reason)
+	telemetry.SetCacheEntries(e.namespace, s.ll.Len())
+	if m.onRemove != nil {
+		m.onRemove(e)
+	}
+}
+
+func (m *MemoryBackend) Get(namespace, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el := m.lookup(namespace, key)
+	telemetry.CountCacheLookup(namespace, el != nil)
+	if el == nil {
+		return nil, false
+	}
+	m.space(namespace).ll.MoveToFront(el)
+	return el.Value.(*entry).value, true
+}
+
+func (m *MemoryBackend) Set(namespace, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.insert(m.newEntry(namespace, key, value))
+}
+
+func (m *MemoryBackend) Add(namespace, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookup(namespace, key) != nil {
+		return ErrExists
+	}
+	return m.insert(m.newEntry(namespace, key, value))
+}
+
+func (m *MemoryBackend) newEntry(namespace, key string, value []byte) *entry {
+	return &entry{
+		namespace: namespace,
+		key:       key,
+		value:     value,
+		expiresAt: m.now().Add(time.Duration(m.limits[namespace].TTL) * time.Second),
+	}
+}
+
+// insert stores an entry, evicting the least recently used entries of its
+// namespace beyond the size cap.
+func (m *MemoryBackend) insert(e *entry) error {
+	s := m.space(e.namespace)
+	max := m.limits[e.namespace].MaxEntries
+	if _, ok := s.items[e.key]; !ok && noEviction[e.namespace] && max > 0 && s.ll.Len() >= max {
+		m.removeExpired(s)
+		if s.ll.Len() >= max {
+			telemetry.CountCacheRefusal(e.namespace)
+			return ErrFull
+		}
+	}
+	if m.onInsert != nil {
+		if err := m.onInsert(e); err != nil {
+			return err
+		}
+	}
+	if el, ok := s.items[e.key]; ok {
+		el.Value = e
+		s.ll.MoveToFront(el)
+	} else {
+		s.items[e.key] = s.ll.PushFront(e)
+	}
+	if max > 0 {
+		for s.ll.Len() > max {
+			m.remove(s, s.ll.Back(), "full")
+		}
+	}
+	telemetry.SetCacheEntries(e.namespace, s.ll.Len())
+	return nil
+}
+
+// removeExpired removes the expired entries of a namespace.
+func (m *MemoryBackend) removeExpired(s *lru) {
+	now := m.now()
+	for el := s.ll.Back(); el != nil; {
+		prev := el.Prev()
+		if !now.Before(el.Value.(*entry).expiresAt) {
+			m.remove(s, el, "expired")
+		}
+		el = prev
+	}
+}
+
+func (m *MemoryBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/config"
+)
+
+func testLimits() map[string]config.CacheLimit {
+	return Limits(map[string]config.CacheLimit{
+		NamespaceSigner:   {TTL: 60, MaxEntries: 2},
+		NamespaceVerifier: {TTL: 60, MaxEntries: 2},
+	})
+}
+
+func TestMemoryBackendEvictsLeastRecentlyUsed(t *testing.T) {
+	b := NewMemoryBackend(testLimits())
+	for _, key := range []string{"a", "b"} {
+		if err := b.Set(NamespaceVerifier, key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Get(NamespaceVerifier, "a")
+	if err := b.Set(NamespaceVerifier, "c", []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := b.Get(NamespaceVerifier, "b"); found {
+		t.Error("least recently used entry was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found := b.Get(NamespaceVerifier, key); !found {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+	if err := b.Add(NamespaceVerifier, "a", nil); err != ErrExists {
+		t.Errorf("Add of a set key = %v", err)
+	}
+}
+
+func TestMemoryBackendRefusesWhenReplayGuardFull(t *testing.T) {
+	now := time.Now()
+	b := NewMemoryBackend(testLimits())
+	b.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b"} {
+		if err := b.Add(NamespaceSigner, key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Add(NamespaceSigner, "c", []byte("c")); err != ErrFull {
+		t.Errorf("Add to a full namespace = %v", err)
+	}
+	if err := b.Set(NamespaceSigner, "a", []byte("a")); err != nil {
+		t.Errorf("Set of a stored key in a full namespace = %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, found := b.Get(NamespaceSigner, key); !found {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Add(NamespaceSigner, "c", []byte("c")); err != nil {
+		t.Errorf("Add once the entries expired = %v", err)
+	}
+}
+
+func TestMemoryBackendExpires(t *testing.T) {
+	now := time.Now()
+	b := NewMemoryBackend(testLimits())
+	b.now = func() time.Time { return now }
+	if err := b.Set(NamespaceVerifier, "a", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if _, found := b.Get(NamespaceVerifier, "a"); found {
+		t.Error("expired entry was returned")
+	}
+	if err := b.Add(NamespaceVerifier, "a", []byte("a")); err != nil {
+		t.Errorf("Add of an expired key = %v", err)
+	}
+}
+
+func TestLevelDBBackendPersists(t *testing.T) {
+	path := t.TempDir()
+	b, err := OpenLevelDBBackend(path, testLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b", "c"} {
+		if err := b.Set(NamespaceVerifier, key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Set(NamespaceToken, "t", []byte("t")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = OpenLevelDBBackend(path, testLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if _, found := b.Get(NamespaceVerifier, "a"); found {
+		t.Error("evicted entry was loaded")
+	}
+	for _, key := range []string{"b", "c"} {
+		if value, found := b.Get(NamespaceVerifier, key); !found || string(value) != key {
+			t.Errorf("entry %s = %q, %v", key, value, found)
+		}
+	}
+
+	b.now = func() time.Time { return now.Add(time.Hour) }
+	if _, found := b.Get(NamespaceVerifier, "b"); found {
+		t.Error("expired entry was returned")
+	}
+	if _, found := b.Get(NamespaceToken, "t"); !found {
+		t.Error("entry was removed before it expired")
+	}
+}
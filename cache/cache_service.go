@@ -3,68 +3,61 @@ package cache
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
-	"sync"
-	"time"
+	"sync/atomic"
 
-	"github.com/arcana-network/dkgnode/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
 )
 
-type syncMap struct {
-	sync.Map
-}
-
-func (s *syncMap) get(key string) *cache.Cache {
-	valueInterface, ok := s.Map.Load(key)
-	if !ok {
-		return nil
-	}
-
-	value, ok := valueInterface.(*cache.Cache)
-	if !ok {
-		return nil
-	}
-
-	return value
-}
-
-func (s *syncMap) set(key string, value *cache.Cache) {
-	s.Map.Store(key, value)
-}
-func (s *syncMap) exists(key string) (ok bool) {
-	_, ok = s.Map.Load(key)
-	return
-}
-
 func New() *CacheService {
 	cacheService := CacheService{}
 	return &cacheService
 }
 
 type CacheService struct {
-	tokenCache    *syncMap
-	signerCache   *cache.Cache
-	verifierCache *cache.Cache
+	backend Backend
+	running atomic.Bool
 }
 
 func (*CacheService) ID() string {
 	return common.CACHE_SERVICE_NAME
 }
 
+// Start opens the configured backend, the memory one when there is no config.
 func (c *CacheService) Start() error {
-	c.tokenCache = &syncMap{}
-	c.signerCache = cache.New(cache.NoExpiration, time.Minute)
-	c.verifierCache = cache.New(cache.NoExpiration, time.Minute)
+	conf := config.Current()
+	if conf == nil {
+		conf = config.GetDefaultConfig()
+	}
+	limits := Limits(conf.CacheLimits)
+	if conf.CacheBackend == "leveldb" {
+		backend, err := OpenLevelDBBackend(filepath.Join(conf.BasePath, "cache"), limits)
+		if err != nil {
+			return fmt.Errorf("could not open cache: %w", err)
+		}
+		c.backend = backend
+		c.running.Store(true)
+		return nil
+	}
+	c.backend = NewMemoryBackend(limits)
+	c.running.Store(true)
 	return nil
 }
 
 func (c *CacheService) Stop() error {
-	return nil
+	c.running.Store(false)
+	if c.backend == nil {
+		return nil
+	}
+	return c.backend.Close()
 }
 func (c *CacheService) IsRunning() bool {
-	return true
+	return c.running.Load()
 }
 
 func (c *CacheService) Call(method string, args ...interface{}) (interface{}, error) {
@@ -93,8 +86,7 @@ func (c *CacheService) Call(method string, args ...interface{}) (interface{}, er
 		_ = common.CastOrUnmarshal(args[1], &args1)
 		_ = common.CastOrUnmarshal(args[2], &args2)
 
-		c.recordTokenCommit(args0, args1, args2)
-		return nil, nil
+		return nil, c.recordTokenCommit(args0, args1, args2)
 	case "signer_sig_exists":
 
 		var args0 string
@@ -146,84 +138,99 @@ func (c *CacheService) Call(method string, args ...interface{}) (interface{}, er
 	return nil, fmt.Errorf("cache service method %v not found", method)
 }
 
+// get decodes the value of a key into v, reporting whether it was found.
+func (c *CacheService) get(namespace, key string, v interface{}) bool {
+	data, found := c.backend.Get(namespace, key)
+	if !found {
+		return false
+	}
+	if err := bijson.Unmarshal(data, v); err != nil {
+		log.WithError(err).WithField("namespace", namespace).Error("could not decode cache entry")
+		return false
+	}
+	return true
+}
+
+func (c *CacheService) set(namespace, key string, v interface{}) {
+	data, err := bijson.Marshal(v)
+	if err == nil {
+		err = c.backend.Set(namespace, key, data)
+	}
+	if err != nil {
+		log.WithError(err).WithField("namespace", namespace).Error("could not store cache entry")
+	}
+}
+
 func (c *CacheService) StoreVerifierClientID(appID, verifier string, params *common.VerifierParams) {
 	key := strings.Join([]string{appID, verifier}, common.Delimiter1)
-	c.verifierCache.Set(key, params, time.Minute*5)
+	c.set(NamespaceVerifier, key, params)
 }
 
 func (c *CacheService) setBuffer(buffer int) {
-	c.verifierCache.Set("key_buffer", buffer, time.Hour*1)
+	c.set(NamespaceBuffer, "key_buffer", buffer)
 }
 
 func (c *CacheService) getBuffer() int {
-	val, found := c.verifierCache.Get("key_buffer")
-	if !found {
-		return 0
-	}
-	return val.(int)
+	var buffer int
+	c.get(NamespaceBuffer, "key_buffer", &buffer)
+	return buffer
 }
 
 func (c *CacheService) RetrieveVerifierClientID(appID, verifier string) *common.VerifierParams {
 	key := strings.Join([]string{appID, verifier}, common.Delimiter1)
 
-	value, found := c.verifierCache.Get(key)
-	if !found {
+	var params common.VerifierParams
+	if !c.get(NamespaceVerifier, key, &params) {
 		return nil
 	}
-	params := value.(*common.VerifierParams)
-	return params
+	return &params
 }
 
 func (c *CacheService) StoreAppPartition(appID string, partitioned bool) {
-	c.verifierCache.Set(appID, partitioned, time.Minute*5)
+	c.set(NamespacePartition, appID, partitioned)
 }
 func (c *CacheService) RetrieveAppPartition(appID string) (bool, error) {
-	value, found := c.verifierCache.Get(appID)
-	if !found {
+	var partitioned bool
+	if !c.get(NamespacePartition, appID, &partitioned) {
 		return false, errors.New("not found")
 	}
-	partitioned, ok := value.(bool)
-	if !ok {
-		return false, errors.New("invalid value found")
-	}
 	return partitioned, nil
 }
 
 func (c *CacheService) signerSigExists(signature string) (exists bool) {
-	_, exists = c.signerCache.Get(signature)
+	_, exists = c.backend.Get(NamespaceSigner, signature)
 	return
 }
 
 func (c *CacheService) recordSignerSig(signature string) error {
-	return c.signerCache.Add(signature, true, time.Minute)
+	return c.backend.Add(NamespaceSigner, signature, []byte("true"))
+}
+
+func tokenKey(verifier, tokenCommitment string) string {
+	return strings.Join([]string{verifier, tokenCommitment}, common.Delimiter1)
 }
 
 func (c *CacheService) tokenCommitExists(verifier string, tokenCommitment string) (exists bool) {
-	if !c.tokenCache.exists(verifier) {
-		c.tokenCache.set(verifier, cache.New(cache.NoExpiration, 10*time.Minute))
-	}
-	_, found := c.tokenCache.get(verifier).Get(tokenCommitment)
-	return found
+	_, exists = c.backend.Get(NamespaceToken, tokenKey(verifier, tokenCommitment))
+	return
 }
 
 func (c *CacheService) getTokenCommitKey(verifier string, tokenCommitment string) (pubKey common.Point) {
-	if !c.tokenCache.exists(verifier) {
-		c.tokenCache.set(verifier, cache.New(cache.NoExpiration, 10*time.Minute))
-	}
-	item, found := c.tokenCache.get(verifier).Get(tokenCommitment)
-	if found {
-		tokenCommitmentData := item.(TokenCommitmentData)
+	var tokenCommitmentData TokenCommitmentData
+	if c.get(NamespaceToken, tokenKey(verifier, tokenCommitment), &tokenCommitmentData) {
 		return tokenCommitmentData.PubKey
 	}
 	return common.Point{}
 }
 
-func (c *CacheService) recordTokenCommit(verifier string, tokenCommitment string, pubKey common.Point) {
-	if !c.tokenCache.exists(verifier) {
-		c.tokenCache.set(verifier, cache.New(cache.NoExpiration, 10*time.Minute))
+// recordTokenCommit fails when the token namespace is full, the commitment
+// request has to be refused then.
+func (c *CacheService) recordTokenCommit(verifier string, tokenCommitment string, pubKey common.Point) error {
+	data, err := bijson.Marshal(TokenCommitmentData{Exists: true, PubKey: pubKey})
+	if err != nil {
+		return err
 	}
-	tokenCommitmentData := TokenCommitmentData{Exists: true, PubKey: pubKey}
-	c.tokenCache.get(verifier).Set(tokenCommitment, tokenCommitmentData, 90*time.Minute)
+	return c.backend.Set(NamespaceToken, tokenKey(verifier, tokenCommitment), data)
 }
 
 type TokenCommitmentData struct {
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/arcana-network/dkgnode/config"
+)
+
+// LevelDBBackend keeps the entries in memory like MemoryBackend and writes
+// them through to a leveldb database, so that they survive a restart.
+type LevelDBBackend struct {
+	*MemoryBackend
+	db *leveldb.DB
+}
+
+// OpenLevelDBBackend opens the database at path and loads the entries that
+// have not expired, dropping the others.
+func OpenLevelDBBackend(path string, limits map[string]config.CacheLimit) (*LevelDBBackend, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := &LevelDBBackend{MemoryBackend: NewMemoryBackend(limits), db: db}
+	if err := b.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	b.onInsert = b.put
+	b.onRemove = b.delete
+	return b, nil
+}
+
+// dbKey is the namespace and the key, which namespaces never contain the
+// separator of.
+func dbKey(namespace, key string) []byte {
+	return []byte(namespace + "/" + key)
+}
+
+func (b *LevelDBBackend) put(e *entry) error {
+	value := make([]byte, 8+len(e.value))
+	binary.BigEndian.PutUint64(value, uint64(e.expiresAt.UnixNano()))
+	copy(value[8:], e.value)
+	return b.db.Put(dbKey(e.namespace, e.key), value, nil)
+}
+
+func (b *LevelDBBackend) delete(e *entry) {
+	if err := b.db.Delete(dbKey(e.namespace, e.key), nil); err != nil {
+		log.WithError(err).Error("could not delete cache entry")
+	}
+}
+
+func (b *LevelDBBackend) load() error {
+	now := b.now()
+	var entries []*entry
+	var expired [][]byte
+	it := b.db.NewIterator(nil, nil)
+	for it.Next() {
+		namespace, key, ok := strings.Cut(string(it.Key()), "/")
+		value := it.Value()
+		if !ok || len(value) < 8 {
+			expired = append(expired, append([]byte(nil), it.Key()...))
+			continue
+		}
+		expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(value)))
+		if !now.Before(expiresAt) {
+			expired = append(expired, append([]byte(nil), it.Key()...))
+			continue
+		}
+		entries = append(entries, &entry{
+			namespace: namespace,
+			key:       key,
+			value:     append([]byte(nil), value[8:]...),
+			expiresAt: expiresAt,
+		})
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := b.db.Delete(key, nil); err != nil {
+			return err
+		}
+	}
+
+	// Entries that expire last were stored last, so they are loaded as the
+	// most recently used. Entries beyond the caps are evicted as they load.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].expiresAt.Before(entries[j].expiresAt) })
+	b.onRemove = b.delete
+	for _, e := range entries {
+		_ = b.insert(e)
+	}
+	log.WithField("entries", len(entries)).Info("cache entries loaded")
+	return nil
+}
+
+func (b *LevelDBBackend) Close() error {
+	return b.db.Close()
+}
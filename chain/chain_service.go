@@ -18,14 +18,14 @@ import (
 	"github.com/arcana-network/dkgnode/eventbus"
 	"github.com/arcana-network/dkgnode/nodelist"
 	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/signer"
 	"github.com/imroc/req/v3"
 
 	"github.com/avast/retry-go"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethCommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	ethCrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	log "github.com/sirupsen/logrus"
 	tmp2p "github.com/tendermint/tendermint/p2p"
 )
@@ -55,12 +55,14 @@ type ChainService struct {
 	bus             eventbus.Bus
 	broker          *common.MessageBroker
 	running         bool
-	client          *ethclient.Client
+	client          *ClientPool
+	txManager       *TxManager
 	pubKey          *ecdsa.PublicKey
 	privKey         *ecdsa.PrivateKey
+	signer          signer.Signer
 	cachedEpochInfo *EpochCache
 	addr            *ethCommon.Address
-	nodeList        *nodelist.NodeList
+	registry        NodeRegistry
 	nodeRegisterMap map[int]*NodeRegister
 	isWhitelisted   bool
 	tmp2pConnection string
@@ -68,6 +70,14 @@ type ChainService struct {
 	isRegistered    bool
 	currentEpoch    int
 	index           int
+	store           *EpochStore
+	stopSync        context.CancelFunc
+	// contractEpoch reads the current epoch from the NodeList contract, it
+	// is nil for other registries.
+	contractEpoch   func() (int, error)
+	whitelisted     chan struct{}
+	whitelistCheck  chan struct{}
+	nodeListRefresh chan struct{}
 }
 
 type EpochCache struct {
@@ -86,6 +96,14 @@ func (s *EpochCache) Set(epoch int, e common.EpochInfo) {
 	s.Map.Store(epoch, e)
 }
 
+// Clear drops the cached epochs, so that they are read again.
+func (s *EpochCache) Clear() {
+	s.Map.Range(func(key, _ interface{}) bool {
+		s.Map.Delete(key)
+		return true
+	})
+}
+
 func New(bus eventbus.Bus) *ChainService {
 	return &ChainService{
 		bus:    bus,
@@ -98,34 +116,135 @@ func (service *ChainService) ID() string {
 }
 
 func (service *ChainService) Start() error {
-	client, err := ethclient.Dial(config.GlobalConfig.EthConnection)
-	if err != nil {
-		return err
+	conf := config.Current()
+	var token string
+	if conf.Signer == signer.Remote {
+		var err error
+		if token, err = remoteSignerToken(conf); err != nil {
+			return err
+		}
 	}
-	service.client = client
-
-	privateKeyECDSA, err := ethCrypto.ToECDSA(config.GlobalConfig.PrivateKey)
+	nodeSigner, err := signer.New(signer.Options{
+		Kind:             conf.Signer,
+		PrivateKey:       conf.PrivateKey,
+		URL:              conf.SignerURL,
+		Token:            token,
+		SecretConfigPath: conf.SecretConfigPath,
+		TransitMount:     conf.SignerTransitMount,
+		TransitKey:       conf.SignerTransitKey,
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("could not set up signer: %w", err)
 	}
-	service.privKey = privateKeyECDSA
-
-	nodePublicKey, err := getPublicKey(privateKeyECDSA)
-	if err != nil {
-		return err
+	service.signer = nodeSigner
+	if remote, ok := nodeSigner.(*signer.RemoteSigner); ok && conf.SignerTokenFile == "" {
+		err := rotateSignerToken(remote, func(name string, onChange func([]byte)) error {
+			return config.WatchSecret(conf.SecretConfigPath, name, onChange)
+		})
+		if err != nil {
+			log.WithError(err).Warn("could not watch remote signer token")
+		}
 	}
+	nodePublicKey := nodeSigner.PublicKey()
 	service.pubKey = nodePublicKey
 
+	// The DKG key agreement and the Tendermint validator need the raw key, so
+	// a local copy is kept and the node does not start without one. A vault
+	// or remote signer does not keep the key out of the node.
+	if exportable, ok := nodeSigner.(signer.Exportable); ok {
+		service.privKey = exportable.PrivateKey()
+	} else if len(conf.PrivateKey) > 0 {
+		privateKeyECDSA, err := ethCrypto.ToECDSA(conf.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if !privateKeyECDSA.PublicKey.Equal(nodePublicKey) {
+			return errors.New("configured private key does not match the signer key")
+		}
+		service.privKey = privateKeyECDSA
+		log.WithField("signer", conf.Signer).Warn("the node private key is kept in memory alongside the signer, for the DKG key agreement and the Tendermint validator")
+	} else {
+		return fmt.Errorf("signer %q needs the node private key as well, the DKG key agreement and the Tendermint validator use it", conf.Signer)
+	}
+
 	nodeAddress := ethCrypto.PubkeyToAddress(*nodePublicKey)
 	service.addr = &nodeAddress
 
-	nodeListAddress := ethCommon.HexToAddress(config.GlobalConfig.ContractAddress)
-	NodeListContract, err := nodelist.NewNodeList(nodeListAddress, client)
-	if err != nil {
-		return err
-	}
-	service.nodeList = NodeListContract
 	service.currentEpoch = 1
+	service.whitelisted = make(chan struct{})
+	service.whitelistCheck = make(chan struct{}, 1)
+	service.nodeListRefresh = make(chan struct{}, 1)
+	var contractSync *contractSync
+	switch conf.NodeRegistry {
+	case FileNodeRegistry:
+		admin, err := ParseRegistryAdmin(conf.NodeRegistryAdmin)
+		if err != nil {
+			return err
+		}
+		registry, err := newFileRegistry(conf.NodeRegistryFile, admin)
+		if err != nil {
+			return fmt.Errorf("could not load node registry file: %w", err)
+		}
+		service.registry = registry
+		service.currentEpoch = registry.CurrentEpoch()
+		// The blockchain is still used for app contracts when it is configured.
+		if urls := conf.RPCURLs(); len(urls) > 0 {
+			client, err := NewClientPool(urls, conf.ChainQuorum)
+			if err != nil {
+				return err
+			}
+			service.client = client
+		}
+	case ContractNodeRegistry, "":
+		client, err := NewClientPool(conf.RPCURLs(), conf.ChainQuorum)
+		if err != nil {
+			return err
+		}
+		service.client = client
+		chainID, err := service.ChainID()
+		if err != nil {
+			return fmt.Errorf("could not get chain id: %w", err)
+		}
+		service.txManager = NewTxManager(client, nodeSigner, chainID)
+
+		nodeListAddress := ethCommon.HexToAddress(conf.ContractAddress)
+		NodeListContract, err := nodelist.NewNodeList(nodeListAddress, client)
+		if err != nil {
+			return err
+		}
+		quorumCaller, err := nodelist.NewNodeListCaller(nodeListAddress, client.QuorumCaller())
+		if err != nil {
+			return err
+		}
+		store, err := OpenEpochStore(conf.BasePath + "/chaindb")
+		if err != nil {
+			return fmt.Errorf("could not open epoch store: %w", err)
+		}
+		service.store = store
+		service.registry = &cachedRegistry{
+			NodeRegistry: &contractRegistry{
+				nodeList:  NodeListContract,
+				quorum:    quorumCaller,
+				callOpts:  service.CallOpts,
+				txManager: service.txManager,
+			},
+			store: store,
+		}
+		service.currentEpoch = initialEpoch(store, NodeListContract)
+		service.contractEpoch = func() (int, error) {
+			epoch, err := quorumCaller.CurrentEpoch(service.CallOpts())
+			if err != nil {
+				return 0, err
+			}
+			return int(epoch.Int64()), nil
+		}
+		contractSync, err = newContractSync(client, nodeListAddress, uint64(conf.ChainConfirmations), store, service)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown node registry %q", conf.NodeRegistry)
+	}
 	service.index = 0
 	service.running = true
 	service.cachedEpochInfo = &EpochCache{}
@@ -135,11 +254,131 @@ func (service *ChainService) Start() error {
 
 	go registerNode(service)
 
-	go currentNodesMonitor(service)
+	go nodeListMonitor(service)
+	service.refreshNodeList()
+
+	if source, ok := service.registry.(EpochSource); ok {
+		go epochMonitor(service, source)
+	}
+	if contractSync != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		service.stopSync = cancel
+		go contractSync.run(ctx)
+	}
 
 	return nil
 }
 
+// initialEpoch returns the epoch the node was in when it stopped, or the
+// current epoch of the contract if the node has not run before.
+func initialEpoch(store *EpochStore, nodeList *nodelist.NodeList) int {
+	if epoch, found := store.CurrentEpoch(); found {
+		return epoch
+	}
+	epoch, err := nodeList.CurrentEpoch(nil)
+	if err != nil || epoch.Sign() == 0 {
+		log.WithError(err).Info("could not get current epoch from contract, starting at epoch 1")
+		return 1
+	}
+	return int(epoch.Int64())
+}
+
+// epochMonitor moves the node to the epochs announced by the registry and
+// connects to the nodes of the new epoch.
+func epochMonitor(e *ChainService, source EpochSource) {
+	for epoch := range source.EpochChanged() {
+		e.setCurrentEpoch(epoch)
+		e.broker.CacheMethods().SetBuffer(0)
+		e.recheckWhitelist()
+		e.refreshNodeList()
+	}
+}
+
+func (e *ChainService) getCurrentEpoch() int {
+	e.Lock()
+	defer e.Unlock()
+	return e.currentEpoch
+}
+
+func (e *ChainService) setCurrentEpoch(epoch int) {
+	e.Lock()
+	e.currentEpoch = epoch
+	e.Unlock()
+	if e.store != nil {
+		e.store.SetCurrentEpoch(epoch)
+	}
+}
+
+// refreshNodeList asks nodeListMonitor to connect to the nodes of the
+// current epoch again.
+func (e *ChainService) refreshNodeList() {
+	select {
+	case e.nodeListRefresh <- struct{}{}:
+	default:
+	}
+}
+
+func (e *ChainService) recheckWhitelist() {
+	select {
+	case e.whitelistCheck <- struct{}{}:
+	default:
+	}
+}
+
+func (e *ChainService) epochChanged(oldEpoch, newEpoch int) {
+	log.WithFields(log.Fields{
+		"oldEpoch": oldEpoch,
+		"newEpoch": newEpoch,
+	}).Info("ContractSync: epoch changed")
+	e.setCurrentEpoch(newEpoch)
+	if _, err := e.GetEpochInfo(newEpoch, true); err != nil {
+		log.WithError(err).Error("ContractSync: could not get new epoch info")
+	}
+	e.recheckWhitelist()
+	e.refreshNodeList()
+}
+
+func (e *ChainService) epochUpdated() {
+	if _, err := e.GetEpochInfo(e.getCurrentEpoch(), true); err != nil {
+		log.WithError(err).Error("ContractSync: could not refresh epoch info")
+	}
+	e.refreshNodeList()
+}
+
+func (e *ChainService) whitelistUpdated(epoch int, node ethCommon.Address, allowed bool) {
+	if node == *e.addr && epoch == e.getCurrentEpoch() {
+		log.WithField("allowed", allowed).Info("ContractSync: whitelist updated for this node")
+		e.recheckWhitelist()
+	}
+}
+
+func (e *ChainService) nodeListed(epoch int, node ethCommon.Address, position int) {
+	if epoch != e.getCurrentEpoch() {
+		return
+	}
+	log.WithFields(log.Fields{
+		"node":     node,
+		"position": position,
+	}).Info("ContractSync: node listed")
+	e.refreshNodeList()
+}
+
+// reorged reads the current epoch from the contract again, as the epoch
+// change the node followed may have been reorged away.
+func (e *ChainService) reorged(fromBlock uint64) {
+	if e.contractEpoch != nil {
+		epoch, err := e.contractEpoch()
+		if err != nil {
+			log.WithError(err).Error("ContractSync: could not read current epoch after reorg")
+		} else if current := e.getCurrentEpoch(); epoch != current {
+			e.epochChanged(current, epoch)
+			return
+		}
+	}
+	e.epochUpdated()
+	e.recheckWhitelist()
+}
+
 func (chain *ChainService) getArcanaContract(appID string) (*appdata.Arcana, error) {
 	appAddress := ethCommon.HexToAddress(appID)
 	appContract, err := appdata.NewArcana(appAddress, chain.client)
@@ -186,9 +425,9 @@ type GatewayIDFromAddressResponse struct {
 }
 
 func GatewayUrl(path, query string) (*url.URL, error) {
-	log.WithField("gatewayUrl", config.GlobalConfig.GatewayURL).Debug("GetGatewayUrl")
+	log.WithField("gatewayUrl", config.Current().GatewayURL).Debug("GetGatewayUrl")
 
-	u, err := url.Parse(config.GlobalConfig.GatewayURL)
+	u, err := url.Parse(config.Current().GatewayURL)
 	if err != nil {
 		return nil, err
 	}
@@ -231,80 +470,59 @@ func VerifierParams(appID, provider string) (vp *common.VerifierParams, err erro
 	return nil, errors.New("ClientID not found")
 }
 
-func getPublicKey(privateKey *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
-	nodePublicKey := privateKey.Public()
-	nodePublicKeyEC, ok := nodePublicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, errors.New("error casting to Public Key")
-	}
-	return nodePublicKeyEC, nil
-}
-
 func (cm *ChainService) ChainID() (chainID *big.Int, err error) {
+	if cm.client == nil {
+		return nil, errors.New("no blockchain connection configured")
+	}
 	ctx := context.Background()
 	chainID, err = cm.client.ChainID(ctx)
 	return
 }
 
-func (s *ChainService) RegisterNode(epoch int, declaredIP string, TMP2PConnection string, P2PConnection string) (*types.Transaction, error) {
-	txOpts, err := s.createTransactionOpts()
-	if err != nil {
-		log.WithError(err).Error("ListNode()")
-		return nil, err
-	}
-	log.WithFields(log.Fields{
-		"DeclaredIP": declaredIP,
-		"Epoch":      epoch,
-	}).Info("RegisterNode()")
-	tx, err := s.nodeList.ListNode(txOpts, big.NewInt(int64(epoch)), declaredIP, s.pubKey.X, s.pubKey.Y, "", "")
-	if err != nil {
-		log.WithError(err).Error("ListNode()")
-		return nil, err
+// CallContract runs a read only call against the latest block.
+func (cm *ChainService) CallContract(to ethCommon.Address, data []byte) ([]byte, error) {
+	if cm.client == nil {
+		return nil, errors.New("no blockchain connection configured")
 	}
-	return tx, nil
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return cm.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
 }
 
-func (s *ChainService) createTransactionOpts() (*bind.TransactOpts, error) {
-	nonce, err := s.client.PendingNonceAt(context.Background(), ethCrypto.PubkeyToAddress(*s.pubKey))
-	if err != nil {
-		log.WithError(err).Error("CreateTransactionOpts.PendingNonceAt()")
-		return nil, err
+// AppOwner returns the owner of the contract of an app, whose ID is the
+// address of the contract.
+func (cm *ChainService) AppOwner(appID string) (ethCommon.Address, error) {
+	if cm.client == nil {
+		return ethCommon.Address{}, errors.New("no blockchain connection configured")
 	}
-
-	chainID, err := s.ChainID()
-	if err != nil {
-		log.WithError(err).Error("CreateTransactionOpts.chainID()")
-		return nil, err
+	if !ethCommon.IsHexAddress(appID) {
+		return ethCommon.Address{}, fmt.Errorf("app id %s is not an address", appID)
 	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(s.privKey, chainID)
+	contract, err := cm.getArcanaContract(appID)
 	if err != nil {
-		log.WithError(err).Error("CreateTransactionOpts.NewKeyedTransactorWithChainID()")
-		return nil, err
+		return ethCommon.Address{}, err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return contract.Owner(&bind.CallOpts{Context: ctx})
+}
 
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)
-	auth.GasLimit = 0
-
-	gasPrice, err := s.client.SuggestGasPrice(context.Background())
+func (s *ChainService) RegisterNode(epoch int, declaredIP string, TMP2PConnection string, P2PConnection string) error {
+	log.WithFields(log.Fields{
+		"DeclaredIP": declaredIP,
+		"Epoch":      epoch,
+	}).Info("RegisterNode()")
+	err := s.registry.Register(epoch, declaredIP, s.pubKey)
 	if err != nil {
-		log.WithError(err).Error("CreateTransactionOpts.SuggestGasPrice()")
-		return nil, err
+		log.WithError(err).Error("ListNode()")
+		return err
 	}
-	auth.GasPrice = gasPrice
-
-	return auth, nil
+	return nil
 }
 
 func registerNode(e *ChainService) {
-	for {
-		if e.isWhitelisted {
-			break
-		}
-		log.Info("Node is not whitelisted yet.")
-		time.Sleep(10 * time.Second)
-	}
+	conf := config.Current()
+	<-e.whitelisted
 	var registered bool
 	err := retry.Do(func() error {
 		res, err := e.IsSelfRegistered(e.currentEpoch)
@@ -318,11 +536,11 @@ func registerNode(e *ChainService) {
 		log.WithError(err).Fatal()
 	}
 
-	externalAddr := "tcp://" + config.GlobalConfig.IPAddress + ":" + strings.Split(config.GlobalConfig.TMP2PListenAddress, ":")[2]
+	externalAddr := "tcp://" + conf.IPAddress + ":" + strings.Split(conf.TMP2PListenAddress, ":")[2]
 	tmp2pNodeKey := e.broker.TendermintMethods().GetNodeKey()
 	p2pHostAddress := e.broker.P2PMethods().GetHostAddress()
 	splitP2PHostAddr := strings.Split(p2pHostAddress, "/")
-	splitP2PHostAddr[2] = config.GlobalConfig.IPAddress
+	splitP2PHostAddr[2] = conf.IPAddress
 	hostP2PAddressWithIP := strings.Join(splitP2PHostAddr, "/")
 
 	e.tmp2pConnection = tmp2p.IDAddressString(tmp2pNodeKey.ID(), externalAddr)
@@ -335,22 +553,22 @@ func registerNode(e *ChainService) {
 	}).Info("BeforeRegisteredContractValues")
 
 	if !registered {
-		port := config.GlobalConfig.HttpServerPort
+		port := conf.HttpServerPort
 		var endpoint string
-		if len(config.GlobalConfig.Domain) > 0 {
-			endpoint = config.GlobalConfig.Domain
+		if len(conf.Domain) > 0 {
+			endpoint = conf.Domain
 		} else {
-			endpoint = config.GlobalConfig.IPAddress + ":" + port
+			endpoint = conf.IPAddress + ":" + port
 		}
 
 		log.WithFields(log.Fields{
-			"IPAddress":       config.GlobalConfig.IPAddress,
+			"IPAddress":       conf.IPAddress,
 			"Port":            port,
 			"IDAddressString": tmp2p.IDAddressString(tmp2pNodeKey.ID(), externalAddr),
 			"PublicEndpoint":  endpoint,
 		}).Info("RegisteredContractValues")
 
-		_, err := e.RegisterNode(
+		err := e.RegisterNode(
 			e.currentEpoch,
 			endpoint,
 			e.tmp2pConnection,
@@ -363,24 +581,28 @@ func registerNode(e *ChainService) {
 	e.isRegistered = true
 }
 
+// whitelistMonitor checks whether the node is whitelisted for the current
+// epoch, and checks again whenever the whitelist may have changed.
 func whitelistMonitor(e *ChainService) {
-	interval := time.NewTicker(10 * time.Second)
-	defer interval.Stop()
-	for range interval.C {
-		isWhitelisted, err := e.nodeList.IsWhitelisted(nil, big.NewInt(int64(e.currentEpoch)), *e.addr)
+	for {
+		isWhitelisted, err := e.registry.IsWhitelisted(e.getCurrentEpoch(), *e.addr)
 		if err != nil {
 			log.WithError(err).Error("WhitelistMonitor.IsWhitelisted()")
+			time.Sleep(10 * time.Second)
+			continue
 		}
 		if isWhitelisted {
 			e.isWhitelisted = true
-			break
+			close(e.whitelisted)
+			return
 		}
 		log.Info("node is not whitelisted yet!")
+		<-e.whitelistCheck
 	}
 }
+
 func (s *ChainService) IsSelfRegistered(epoch int) (bool, error) {
-	opts := s.CallOpts()
-	result, err := s.nodeList.NodeRegistered(opts, big.NewInt(int64(epoch)), *s.addr)
+	result, err := s.registry.IsRegistered(epoch, *s.addr)
 	if err != nil {
 		return false, err
 	}
@@ -394,11 +616,19 @@ func (s *ChainService) CallOpts() *bind.CallOpts {
 	return &auth
 }
 
-func (s *ChainService) Sign(data []byte) []byte {
-	ecSig := crypto.SignData(data, s.privKey)
-	return ecSig.Raw
+func (s *ChainService) Sign(data []byte) ([]byte, error) {
+	return signer.SignData(s.signer, data)
 }
 func (chainService *ChainService) Stop() error {
+	if chainService.stopSync != nil {
+		chainService.stopSync()
+	}
+	if chainService.client != nil {
+		chainService.client.Close()
+	}
+	if chainService.store != nil {
+		return chainService.store.Close()
+	}
 	return nil
 }
 
@@ -411,12 +641,12 @@ func (chainService *ChainService) getBuffer() int {
 	if buffer != 0 {
 		return buffer
 	}
-	b, err := chainService.nodeList.BufferSize(nil)
+	b, err := chainService.registry.BufferSize()
 	if err != nil {
 		return DEFAULT_KEY_BUFFER
 	}
-	chainService.broker.CacheMethods().SetBuffer(int(b.Int64()))
-	return int(b.Int64())
+	chainService.broker.CacheMethods().SetBuffer(b)
+	return b
 }
 
 func (e *ChainService) verifyDataWithNodelist(pk common.Point, sig []byte, data []byte) (senderDetails common.KeygenNodeDetails, err error) {
@@ -478,6 +708,8 @@ func (chainService *ChainService) Call(method string, args ...interface{}) (inte
 	case "get_p2p_connection":
 		return chainService.p2pConnection, nil
 	case "get_current_epoch":
+		chainService.Lock()
+		defer chainService.Unlock()
 		log.WithField("currentEpoch", chainService.currentEpoch).Debug("ChainService")
 		return chainService.currentEpoch, nil
 	case "validate_epoch_pub_key":
@@ -500,6 +732,14 @@ func (chainService *ChainService) Call(method string, args ...interface{}) (inte
 			return true, nil
 		}
 		return false, errors.New("incorrect pubkey")
+	case "refresh_epoch":
+		chainService.cachedEpochInfo.Clear()
+		info, err := chainService.GetEpochInfo(chainService.getCurrentEpoch(), true)
+		if err != nil {
+			return nil, err
+		}
+		chainService.refreshNodeList()
+		return info, nil
 	case "get_previous_epoch":
 		epochInfo, err := chainService.GetEpochInfo(chainService.currentEpoch, false)
 		if err != nil {
@@ -535,12 +775,30 @@ func (chainService *ChainService) Call(method string, args ...interface{}) (inte
 	case "get_address":
 		return chainService.addr, nil
 	case "get_self_private_key":
+		if chainService.privKey == nil {
+			return nil, errors.New("node key is only available to the signer")
+		}
 		return *chainService.privKey.D, nil
 	case "self_sign_data":
 		var args0 []byte
 		_ = common.CastOrUnmarshal(args[0], &args0)
-		rawSig := chainService.Sign(args0)
-		return rawSig, nil
+		return chainService.Sign(args0)
+	case "sign_hash":
+		var args0 []byte
+		_ = common.CastOrUnmarshal(args[0], &args0)
+		return chainService.signer.SignHash(args0)
+	case "get_chain_id":
+		return chainService.ChainID()
+	case "call_contract":
+		var args0 ethCommon.Address
+		var args1 []byte
+		_ = common.CastOrUnmarshal(args[0], &args0)
+		_ = common.CastOrUnmarshal(args[1], &args1)
+		return chainService.CallContract(args0, args1)
+	case "get_app_owner":
+		var args0 string
+		_ = common.CastOrUnmarshal(args[0], &args0)
+		return chainService.AppOwner(args0)
 	case "get_key_buffer":
 		buffer := chainService.getBuffer()
 		return buffer, nil
@@ -588,8 +846,8 @@ func (chainService *ChainService) Call(method string, args ...interface{}) (inte
 		_ = common.CastOrUnmarshal(args[0], &args0)
 
 		nodeEpoch := args0
-		if chainService.nodeList == nil {
-			return nil, errors.New("nodelist contract is undefined")
+		if chainService.registry == nil {
+			return nil, errors.New("node registry is undefined")
 		}
 		first := true
 		for {
@@ -686,26 +944,13 @@ func (e *ChainService) GetEpochInfo(epoch int, skipCache bool) (common.EpochInfo
 		}
 	}
 
-	opts := e.CallOpts()
 	if epoch == 0 {
 		return common.EpochInfo{}, fmt.Errorf("epoch %v is invalid", epoch)
 	}
-	result, err := e.nodeList.GetEpochInfo(opts, big.NewInt(int64(epoch)))
-	log.WithField("info", result).Debug("GetEpochInfo()")
+	eInfo, err := e.registry.EpochInfo(epoch)
 	if err != nil {
 		return common.EpochInfo{}, err
 	}
-	if result.Id.Cmp(big.NewInt(0)) == 0 {
-		return common.EpochInfo{}, fmt.Errorf("epoch %v has not been initialized", epoch)
-	}
-	eInfo := common.EpochInfo{
-		Id:        *result.Id,
-		N:         *result.N,
-		K:         *result.K,
-		T:         *result.T,
-		PrevEpoch: *result.PrevEpoch,
-		NextEpoch: *result.NextEpoch,
-	}
 	e.cachedEpochInfo.Set(epoch, eInfo)
 	return eInfo, nil
 }
@@ -749,14 +994,14 @@ func (chainService *ChainService) verifyDataWithEpoch(pk common.Point, sig []byt
 
 func (e *ChainService) getNodeRefsByEpoch(epoch int) ([]*common.NodeReference, error) {
 	log.WithField("epoch", epoch).Debug("GetNodeRefsByEpoch()")
-	ethList, err := e.nodeList.GetNodes(nil, big.NewInt(int64(epoch)))
+	ethList, err := e.registry.Nodes(epoch)
 	if err != nil {
 		return nil, fmt.Errorf("Could not get node list %v", err.Error())
 	}
 	var currNodeList []*common.NodeReference
 
 	for i := 0; i < len(ethList); i++ {
-		detailsWithPubK, err := e.nodeList.NodeDetails(nil, ethList[i])
+		detailsWithPubK, err := e.registry.NodeDetails(ethList[i])
 		if err != nil {
 			return nil, fmt.Errorf("could not get node details with pub key %v", err.Error())
 		}
@@ -777,7 +1022,7 @@ func (e *ChainService) getNodeRefsByEpoch(epoch int) ([]*common.NodeReference, e
 }
 
 func (e *ChainService) GetNodeRef(nodeAddress ethCommon.Address) (n *common.NodeReference, err error) {
-	details, err := e.nodeList.NodeDetails(nil, nodeAddress)
+	details, err := e.registry.NodeDetails(nodeAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -826,11 +1071,22 @@ func (e *ChainService) GetNodeRef(nodeAddress ethCommon.Address) (n *common.Node
 	}, nil
 }
 
+// nodeListMonitor connects to the nodes of the current epoch every time the
+// node list may have changed.
+func nodeListMonitor(e *ChainService) {
+	for range e.nodeListRefresh {
+		currentNodesMonitor(e)
+	}
+}
+
+// currentNodesMonitor connects to the nodes of the current epoch. It retries
+// while not all nodes of the epoch are listed or reachable, in case the log
+// listing the remaining nodes is missed.
 func currentNodesMonitor(e *ChainService) {
 	interval := time.NewTicker(10 * time.Second)
 	defer interval.Stop()
-	for range interval.C {
-		currEpoch := e.broker.ChainMethods().GetCurrentEpoch()
+	for ; ; <-interval.C {
+		currEpoch := e.getCurrentEpoch()
 		currEpochInfo, err := e.GetEpochInfo(currEpoch, true)
 		if err != nil {
 			log.WithError(err).Error("CurrentNodesMonitor.GetEpochInfo()")
@@ -850,7 +1106,7 @@ func currentNodesMonitor(e *ChainService) {
 			log.WithFields(log.Fields{
 				"currNodeList":  currNodeList,
 				"currEpochInfo": currEpochInfo,
-			}).Error("currentNodeList does not equal in length to expected currEpochInfo")
+			}).Info("waiting for the remaining nodes of the epoch to be listed")
 			continue
 		}
 		allNodesConnected := true
@@ -871,6 +1127,6 @@ func currentNodesMonitor(e *ChainService) {
 		e.Lock()
 		e.nodeRegisterMap[currEpoch].NodeList = currNodeList
 		e.Unlock()
-		break
+		return
 	}
 }
@@ -0,0 +1,410 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	healthCheckInterval = 15 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	// maxHeadLag is how many blocks an endpoint may trail the highest head
+	// seen before it is considered unhealthy.
+	maxHeadLag = 10
+)
+
+// ErrNoHealthyEndpoint is returned when every RPC endpoint failed.
+var ErrNoHealthyEndpoint = errors.New("no healthy blockchain rpc endpoint")
+
+type endpoint struct {
+	url     string
+	client  *ethclient.Client
+	healthy bool
+	head    uint64
+	lastErr error
+}
+
+// ClientPool spreads blockchain RPC calls over several endpoints. Calls go to
+// the first healthy endpoint in configuration order and fail over to the next
+// one when an endpoint cannot be reached. Errors returned by the node itself,
+// such as reverts, are not retried on other endpoints.
+type ClientPool struct {
+	sync.RWMutex
+	endpoints []*endpoint
+	quorum    int
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClientPool dials every endpoint. Dialing does not connect for HTTP
+// endpoints, so unreachable endpoints are only detected by the health checks.
+// quorum is the number of endpoints that have to agree on a quorum read; zero
+// means a majority of the healthy endpoints.
+func NewClientPool(urls []string, quorum int) (*ClientPool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no blockchain rpc endpoint configured")
+	}
+	pool := &ClientPool{quorum: quorum, stop: make(chan struct{})}
+	for _, url := range urls {
+		client, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, fmt.Errorf("could not dial %s: %w", url, err)
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url, client: client, healthy: true})
+	}
+	pool.checkHealth()
+	go pool.healthMonitor()
+	return pool, nil
+}
+
+func (p *ClientPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		for _, e := range p.endpoints {
+			e.client.Close()
+		}
+	})
+}
+
+func (p *ClientPool) healthMonitor() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// checkHealth asks every endpoint for its head. Endpoints that do not answer
+// or trail the highest head by more than maxHeadLag blocks are unhealthy.
+func (p *ClientPool) checkHealth() {
+	heads := make([]uint64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+			heads[i], errs[i] = e.client.BlockNumber(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range heads {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+	p.Lock()
+	defer p.Unlock()
+	for i, e := range p.endpoints {
+		healthy := errs[i] == nil && heads[i]+maxHeadLag >= best
+		if healthy != e.healthy {
+			log.WithFields(log.Fields{
+				"endpoint": e.url,
+				"healthy":  healthy,
+				"head":     heads[i],
+				"best":     best,
+			}).WithError(errs[i]).Warn("ClientPool: endpoint health changed")
+		}
+		e.healthy = healthy
+		e.lastErr = errs[i]
+		if errs[i] == nil {
+			e.head = heads[i]
+		}
+	}
+}
+
+// candidates returns the healthy endpoints followed by the unhealthy ones,
+// so that calls still have a chance when every health check failed.
+func (p *ClientPool) candidates() []*endpoint {
+	p.RLock()
+	defer p.RUnlock()
+	var healthy, unhealthy []*endpoint
+	for _, e := range p.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (p *ClientPool) markUnhealthy(e *endpoint, err error) {
+	p.Lock()
+	defer p.Unlock()
+	if e.healthy {
+		log.WithField("endpoint", e.url).WithError(err).Warn("ClientPool: failing over")
+	}
+	e.healthy = false
+	e.lastErr = err
+}
+
+// isEndpointError reports whether err means the endpoint could not serve the
+// call, rather than the call itself failing.
+func isEndpointError(err error) bool {
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// do runs call on the first endpoint that can serve it.
+func (p *ClientPool) do(ctx context.Context, call func(*ethclient.Client) error) error {
+	err := ErrNoHealthyEndpoint
+	for _, e := range p.candidates() {
+		err = call(e.client)
+		if !isEndpointError(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		p.markUnhealthy(e, err)
+	}
+	return err
+}
+
+// Status reports the health of every endpoint.
+func (p *ClientPool) Status() map[string]bool {
+	p.RLock()
+	defer p.RUnlock()
+	status := make(map[string]bool, len(p.endpoints))
+	for _, e := range p.endpoints {
+		status[e.url] = e.healthy
+	}
+	return status
+}
+
+// quorumCall runs call on every healthy endpoint and returns the result that
+// enough of them agree on.
+func (p *ClientPool) quorumCall(ctx context.Context, call func(*ethclient.Client, *big.Int) ([]byte, error)) ([]byte, error) {
+	var endpoints []*endpoint
+	var pinned uint64
+	p.RLock()
+	for _, e := range p.endpoints {
+		if e.healthy {
+			endpoints = append(endpoints, e)
+			if pinned == 0 || e.head < pinned {
+				pinned = e.head
+			}
+		}
+	}
+	p.RUnlock()
+	if len(endpoints) == 0 {
+		return nil, ErrNoHealthyEndpoint
+	}
+	// Endpoints are read at the same block so that they can agree while
+	// new blocks come in.
+	var block *big.Int
+	if pinned > 0 {
+		block = new(big.Int).SetUint64(pinned)
+	}
+
+	results := make([][]byte, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			results[i], errs[i] = call(e.client, block)
+			if isEndpointError(errs[i]) && ctx.Err() == nil {
+				p.markUnhealthy(e, errs[i])
+			}
+		}(i, e)
+	}
+	wg.Wait()
+
+	quorum := p.quorum
+	if quorum <= 0 {
+		quorum = len(endpoints)/2 + 1
+	}
+	var lastErr error
+	for i := range results {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		agree := 0
+		for j := range results {
+			if errs[j] == nil && bytes.Equal(results[i], results[j]) {
+				agree++
+			}
+		}
+		if agree >= quorum {
+			return results[i], nil
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("no quorum of %d endpoints: %w", quorum, lastErr)
+	}
+	return nil, fmt.Errorf("no quorum of %d endpoints agree on the result", quorum)
+}
+
+// QuorumCaller returns a contract caller whose calls are only answered when
+// enough endpoints agree. It is used for the reads the node list depends on.
+func (p *ClientPool) QuorumCaller() *QuorumCaller {
+	return &QuorumCaller{pool: p}
+}
+
+// QuorumCaller is a bind.ContractCaller doing quorum reads over a pool.
+type QuorumCaller struct {
+	pool *ClientPool
+}
+
+func (q *QuorumCaller) CodeAt(ctx context.Context, contract ethCommon.Address, blockNumber *big.Int) ([]byte, error) {
+	return q.pool.quorumCall(ctx, func(client *ethclient.Client, pinned *big.Int) ([]byte, error) {
+		if blockNumber != nil {
+			pinned = blockNumber
+		}
+		return client.CodeAt(ctx, contract, pinned)
+	})
+}
+
+func (q *QuorumCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return q.pool.quorumCall(ctx, func(client *ethclient.Client, pinned *big.Int) ([]byte, error) {
+		if blockNumber != nil {
+			pinned = blockNumber
+		}
+		return client.CallContract(ctx, call, pinned)
+	})
+}
+
+func (p *ClientPool) CodeAt(ctx context.Context, contract ethCommon.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		result, err = c.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, number)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) PendingCodeAt(ctx context.Context, account ethCommon.Address) (code []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.PendingCodeAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) PendingNonceAt(ctx context.Context, account ethCommon.Address) (nonce uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		nonce, err = c.PendingNonceAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		price, err = c.SuggestGasPrice(ctx)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		tip, err = c.SuggestGasTipCap(ctx)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		gas, err = c.EstimateGas(ctx, call)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return p.do(ctx, func(c *ethclient.Client) error {
+		return c.SendTransaction(ctx, tx)
+	})
+}
+
+func (p *ClientPool) TransactionReceipt(ctx context.Context, txHash ethCommon.Hash) (receipt *types.Receipt, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		receipt, err = c.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		chainID, err = c.ChainID(ctx)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		logs, err = c.FilterLogs(ctx, query)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) (err error) {
+		sub, err = c.SubscribeFilterLogs(ctx, query, ch)
+		return
+	})
+	return
+}
+
+// SubscribeNewHead subscribes on the first endpoint that supports
+// subscriptions. Endpoints without subscription support are not marked
+// unhealthy for it.
+func (p *ClientPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	err := ErrNoHealthyEndpoint
+	for _, e := range p.candidates() {
+		var sub ethereum.Subscription
+		sub, err = e.client.SubscribeNewHead(ctx, ch)
+		if err == nil {
+			return sub, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeRPC answers eth_blockNumber and eth_call with fixed values.
+type fakeRPC struct {
+	head   uint64
+	result []byte
+	calls  int
+}
+
+func (f *fakeRPC) serve(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("could not decode request: %v", err)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = hexutil.Uint64(f.head)
+		case "eth_call":
+			f.calls++
+			result = hexutil.Bytes(f.result)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestPool(t *testing.T, quorum int, urls ...string) *ClientPool {
+	pool, err := NewClientPool(urls, quorum)
+	if err != nil {
+		t.Fatalf("NewClientPool() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestClientPoolFailsOver(t *testing.T) {
+	down := (&fakeRPC{}).serve(t)
+	down.Close()
+	up := &fakeRPC{head: 100, result: []byte{1}}
+	lagging := &fakeRPC{head: 50, result: []byte{2}}
+	pool := newTestPool(t, 0, down.URL, lagging.serve(t).URL, up.serve(t).URL)
+
+	status := pool.Status()
+	if status[down.URL] {
+		t.Error("unreachable endpoint reported healthy")
+	}
+	if n := len(status); n != 3 {
+		t.Fatalf("Status() has %d endpoints, want 3", n)
+	}
+	result, err := pool.CallContract(context.Background(), ethereum.CallMsg{To: &ethCommon.Address{}}, nil)
+	if err != nil {
+		t.Fatalf("CallContract() error = %v", err)
+	}
+	if len(result) != 1 || result[0] != 1 {
+		t.Errorf("CallContract() = %x, want the answer of the endpoint that is not lagging", result)
+	}
+	if lagging.calls != 0 {
+		t.Errorf("lagging endpoint got %d calls, want 0", lagging.calls)
+	}
+}
+
+func TestQuorumCallerNeedsAgreement(t *testing.T) {
+	a := &fakeRPC{head: 10, result: []byte{1}}
+	b := &fakeRPC{head: 10, result: []byte{1}}
+	c := &fakeRPC{head: 10, result: []byte{2}}
+	pool := newTestPool(t, 0, a.serve(t).URL, b.serve(t).URL, c.serve(t).URL)
+	msg := ethereum.CallMsg{To: &ethCommon.Address{}}
+
+	result, err := pool.QuorumCaller().CallContract(context.Background(), msg, nil)
+	if err != nil {
+		t.Fatalf("CallContract() error = %v", err)
+	}
+	if len(result) != 1 || result[0] != 1 {
+		t.Errorf("CallContract() = %x, want the majority answer", result)
+	}
+
+	b.result = []byte{3}
+	if _, err := pool.QuorumCaller().CallContract(context.Background(), msg, nil); err == nil {
+		t.Error("CallContract() returned a result no majority agrees on")
+	}
+}
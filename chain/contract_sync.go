@@ -0,0 +1,290 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/arcana-network/dkgnode/nodelist"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// syncPollInterval is used when the RPC does not support head
+	// subscriptions, and to retry after a failed sync.
+	syncPollInterval = 15 * time.Second
+	// maxLogRange caps the number of blocks requested in one eth_getLogs call.
+	maxLogRange = 1000
+	// reorgWindow is the number of processed blocks remembered to find the
+	// common ancestor after a reorg.
+	reorgWindow = 128
+)
+
+// syncBackend is the part of the blockchain client used to follow contract
+// logs. It is satisfied by ethclient.Client and the simulated backend.
+type syncBackend interface {
+	bind.ContractFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// contractEventHandler is notified of NodeList contract events once they
+// are confirmed.
+type contractEventHandler interface {
+	epochChanged(oldEpoch, newEpoch int)
+	epochUpdated()
+	whitelistUpdated(epoch int, node ethCommon.Address, allowed bool)
+	nodeListed(epoch int, node ethCommon.Address, position int)
+	// reorged is called when blocks whose logs were processed are no longer
+	// canonical. Their logs are processed again from fromBlock.
+	reorged(fromBlock uint64)
+}
+
+// contractSync follows the logs of the NodeList contract. Logs are only
+// processed once they are confirmations blocks deep, and the processed
+// position is persisted so that a restarted node continues where it stopped.
+type contractSync struct {
+	backend       syncBackend
+	address       ethCommon.Address
+	filterer      *nodelist.NodeListFilterer
+	confirmations uint64
+	store         *EpochStore
+	handler       contractEventHandler
+	topics        map[ethCommon.Hash]string
+	recent        []syncCursor
+}
+
+func newContractSync(backend syncBackend, address ethCommon.Address, confirmations uint64, store *EpochStore, handler contractEventHandler) (*contractSync, error) {
+	nodeListFilterer, err := nodelist.NewNodeListFilterer(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(nodelist.NodeListMetaData.ABI))
+	if err != nil {
+		return nil, err
+	}
+	topics := make(map[ethCommon.Hash]string)
+	for _, name := range []string{"EpochChanged", "EpochUpdate", "WhitelistUpdate", "NodeListed"} {
+		topics[parsed.Events[name].ID] = name
+	}
+	c := &contractSync{
+		backend:       backend,
+		address:       address,
+		filterer:      nodeListFilterer,
+		confirmations: confirmations,
+		store:         store,
+		handler:       handler,
+		topics:        topics,
+	}
+	if cursor, found := store.syncCursor(); found {
+		c.recent = []syncCursor{cursor}
+	}
+	return c, nil
+}
+
+// run syncs on every new head until ctx is done.
+func (c *contractSync) run(ctx context.Context) {
+	heads := make(chan *types.Header, 16)
+	var subErr <-chan error
+	sub, err := c.backend.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		log.WithError(err).Info("ContractSync: head subscription unavailable, polling")
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.sync(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Error("ContractSync.sync()")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-heads:
+		case <-ticker.C:
+		case err := <-subErr:
+			log.WithError(err).Warn("ContractSync: head subscription ended, polling")
+			subErr = nil
+		}
+	}
+}
+
+func (c *contractSync) cursor() (syncCursor, bool) {
+	if len(c.recent) == 0 {
+		return syncCursor{}, false
+	}
+	return c.recent[len(c.recent)-1], true
+}
+
+func (c *contractSync) advance(cursor syncCursor) {
+	c.recent = append(c.recent, cursor)
+	if len(c.recent) > reorgWindow {
+		c.recent = c.recent[len(c.recent)-reorgWindow:]
+	}
+	c.store.setSyncCursor(cursor)
+}
+
+func (c *contractSync) headerHash(ctx context.Context, number uint64) (ethCommon.Hash, error) {
+	header, err := c.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return ethCommon.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+// sync processes the logs of all blocks that are confirmed and not yet
+// processed.
+func (c *contractSync) sync(ctx context.Context) error {
+	head, err := c.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if head.Number.Uint64() < c.confirmations {
+		return nil
+	}
+	target := head.Number.Uint64() - c.confirmations
+
+	cursor, found := c.cursor()
+	if !found {
+		// The state up to target is read from the contract directly on start,
+		// so only later logs have to be followed.
+		hash, err := c.headerHash(ctx, target)
+		if err != nil {
+			return err
+		}
+		c.advance(syncCursor{Number: target, Hash: hash})
+		return nil
+	}
+	if err := c.rewindReorg(ctx); err != nil {
+		return err
+	}
+	cursor, _ = c.cursor()
+
+	for from := cursor.Number + 1; from <= target; from += maxLogRange {
+		to := from + maxLogRange - 1
+		if to > target {
+			to = target
+		}
+		if err := c.processRange(ctx, from, to); err != nil {
+			return err
+		}
+		hash, err := c.headerHash(ctx, to)
+		if err != nil {
+			return err
+		}
+		c.advance(syncCursor{Number: to, Hash: hash})
+	}
+	return nil
+}
+
+// rewindReorg moves the cursor back to the last processed block that is
+// still canonical, if the chain reorganised below the confirmation depth.
+func (c *contractSync) rewindReorg(ctx context.Context) error {
+	cursor, _ := c.cursor()
+	hash, err := c.headerHash(ctx, cursor.Number)
+	if err != nil {
+		return err
+	}
+	if hash == cursor.Hash {
+		return nil
+	}
+	for len(c.recent) > 0 {
+		ancestor := c.recent[len(c.recent)-1]
+		hash, err := c.headerHash(ctx, ancestor.Number)
+		if err != nil {
+			return err
+		}
+		if hash == ancestor.Hash {
+			break
+		}
+		c.recent = c.recent[:len(c.recent)-1]
+	}
+	if len(c.recent) == 0 {
+		// No remembered block is canonical anymore; start again from the
+		// oldest block the window covered.
+		start := uint64(0)
+		if cursor.Number > reorgWindow {
+			start = cursor.Number - reorgWindow
+		}
+		hash, err := c.headerHash(ctx, start)
+		if err != nil {
+			return err
+		}
+		c.recent = []syncCursor{{Number: start, Hash: hash}}
+	}
+	ancestor, _ := c.cursor()
+	c.store.setSyncCursor(ancestor)
+	log.WithFields(log.Fields{
+		"processed": cursor.Number,
+		"ancestor":  ancestor.Number,
+	}).Warn("ContractSync: chain reorganised, processing logs again")
+	c.handler.reorged(ancestor.Number + 1)
+	return nil
+}
+
+func (c *contractSync) processRange(ctx context.Context, from, to uint64) error {
+	topics := make([]ethCommon.Hash, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	logs, err := c.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []ethCommon.Address{c.address},
+		Topics:    [][]ethCommon.Hash{topics},
+	})
+	if err != nil {
+		return fmt.Errorf("could not filter logs from %d to %d: %w", from, to, err)
+	}
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+		if err := c.dispatch(l); err != nil {
+			log.WithError(err).WithField("tx", l.TxHash).Error("ContractSync: could not parse log")
+		}
+	}
+	return nil
+}
+
+func (c *contractSync) dispatch(l types.Log) error {
+	switch c.topics[l.Topics[0]] {
+	case "EpochChanged":
+		e, err := c.filterer.ParseEpochChanged(l)
+		if err != nil {
+			return err
+		}
+		c.handler.epochChanged(int(e.OldEpoch.Int64()), int(e.NewEpoch.Int64()))
+	case "EpochUpdate":
+		if _, err := c.filterer.ParseEpochUpdate(l); err != nil {
+			return err
+		}
+		c.handler.epochUpdated()
+	case "WhitelistUpdate":
+		e, err := c.filterer.ParseWhitelistUpdate(l)
+		if err != nil {
+			return err
+		}
+		c.handler.whitelistUpdated(int(e.Epoch.Int64()), e.WhitelistAddress, e.IsAllowed)
+	case "NodeListed":
+		e, err := c.filterer.ParseNodeListed(l)
+		if err != nil {
+			return err
+		}
+		c.handler.nodeListed(int(e.Epoch.Int64()), e.PublicKey, int(e.Position.Int64()))
+	default:
+		return errors.New("unexpected log topic")
+	}
+	return nil
+}
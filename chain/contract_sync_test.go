@@ -0,0 +1,333 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/nodelist"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// logEmitterCode deploys a contract that emits every call as a log with one
+// topic: the first 32 bytes of the calldata are the topic and the rest is
+// the log data. It stands in for the NodeList contract, whose bytecode is
+// not part of the bindings.
+var logEmitterCode = ethCommon.FromHex(
+	// constructor: return the 18 byte runtime below
+	"6012" + "80" + "600b" + "6000" + "39" + "6000" + "f3" +
+		// runtime: calldatacopy(0, 32, size-32); log1(0, size-32, calldataload(0))
+		"6020" + "36" + "03" + "80" + "6020" + "6000" + "37" +
+		"6000" + "35" + "90" + "6000" + "a1" + "00")
+
+type recordedEvent struct {
+	kind  string
+	epoch int
+	node  ethCommon.Address
+	block uint64
+}
+
+type eventRecorder struct {
+	events []recordedEvent
+}
+
+func (r *eventRecorder) epochChanged(oldEpoch, newEpoch int) {
+	r.events = append(r.events, recordedEvent{kind: "epoch_changed", epoch: newEpoch})
+}
+func (r *eventRecorder) epochUpdated() {
+	r.events = append(r.events, recordedEvent{kind: "epoch_updated"})
+}
+func (r *eventRecorder) whitelistUpdated(epoch int, node ethCommon.Address, allowed bool) {
+	r.events = append(r.events, recordedEvent{kind: "whitelist_updated", epoch: epoch, node: node})
+}
+func (r *eventRecorder) nodeListed(epoch int, node ethCommon.Address, position int) {
+	r.events = append(r.events, recordedEvent{kind: "node_listed", epoch: epoch, node: node})
+}
+func (r *eventRecorder) reorged(fromBlock uint64) {
+	r.events = append(r.events, recordedEvent{kind: "reorged", block: fromBlock})
+}
+
+func (r *eventRecorder) kinds() []string {
+	var kinds []string
+	for _, e := range r.events {
+		kinds = append(kinds, e.kind)
+	}
+	return kinds
+}
+
+type simulatedChain struct {
+	t        *testing.T
+	backend  *backends.SimulatedBackend
+	key      *ecdsa.PrivateKey
+	address  ethCommon.Address
+	emitter  ethCommon.Address
+	nodeList abi.ABI
+}
+
+func newSimulatedChain(t *testing.T) *simulatedChain {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ethCrypto.PubkeyToAddress(key.PublicKey)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		address: {Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(100))},
+	}, 8000000)
+	t.Cleanup(func() { backend.Close() })
+	parsed, err := abi.JSON(strings.NewReader(nodelist.NodeListMetaData.ABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &simulatedChain{
+		t:        t,
+		backend:  backend,
+		key:      key,
+		address:  address,
+		nodeList: parsed,
+	}
+	c.send(nil, logEmitterCode)
+	c.emitter = ethCrypto.CreateAddress(address, 0)
+	backend.Commit()
+	return c
+}
+
+func (c *simulatedChain) send(to *ethCommon.Address, data []byte) {
+	ctx := context.Background()
+	nonce, err := c.backend.PendingNonceAt(ctx, c.address)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	gasPrice, err := c.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(nonce, big.NewInt(0), 200000, gasPrice, data)
+	} else {
+		tx = types.NewTransaction(nonce, *to, big.NewInt(0), 200000, gasPrice, data)
+	}
+	signed, err := types.SignTx(tx, types.HomesteadSigner{}, c.key)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := c.backend.SendTransaction(ctx, signed); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// emit makes the emitter log a NodeList event with unindexed arguments.
+func (c *simulatedChain) emit(event string, args ...interface{}) {
+	e := c.nodeList.Events[event]
+	data, err := e.Inputs.NonIndexed().Pack(args...)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.send(&c.emitter, append(e.ID.Bytes(), data...))
+}
+
+func (c *simulatedChain) commit(blocks int) {
+	for i := 0; i < blocks; i++ {
+		c.backend.Commit()
+	}
+}
+
+func (c *simulatedChain) head() uint64 {
+	header, err := c.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return header.Number.Uint64()
+}
+
+func newTestSync(t *testing.T, chain *simulatedChain, store *EpochStore, recorder *eventRecorder) *contractSync {
+	sync, err := newContractSync(chain.backend, chain.emitter, 2, store, recorder)
+	if err != nil {
+		t.Fatalf("newContractSync() error = %v", err)
+	}
+	return sync
+}
+
+func openTestStore(t *testing.T, path string) *EpochStore {
+	store, err := OpenEpochStore(path)
+	if err != nil {
+		t.Fatalf("OpenEpochStore() error = %v", err)
+	}
+	return store
+}
+
+func TestContractSyncWaitsForConfirmations(t *testing.T) {
+	chain := newSimulatedChain(t)
+	path := filepath.Join(t.TempDir(), "chaindb")
+	store := openTestStore(t, path)
+	recorder := &eventRecorder{}
+	sync := newTestSync(t, chain, store, recorder)
+	ctx := context.Background()
+
+	chain.commit(3)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	chain.emit("EpochChanged", big.NewInt(1), big.NewInt(2))
+	chain.commit(2)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if len(recorder.events) != 0 {
+		t.Fatalf("events = %v before the log was confirmed", recorder.kinds())
+	}
+
+	chain.commit(1)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].kind != "epoch_changed" || recorder.events[0].epoch != 2 {
+		t.Fatalf("events = %+v, want epoch 2 once confirmed", recorder.events)
+	}
+
+	// A restarted node continues after the persisted cursor.
+	store.Close()
+	store = openTestStore(t, path)
+	defer store.Close()
+	chain.commit(3)
+	restarted := &eventRecorder{}
+	if err := newTestSync(t, chain, store, restarted).sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if len(restarted.events) != 0 {
+		t.Errorf("events after restart = %v, want the processed log to be skipped", restarted.kinds())
+	}
+	if cursor, _ := store.syncCursor(); cursor.Number != chain.head()-2 {
+		t.Errorf("cursor = %d, want %d", cursor.Number, chain.head()-2)
+	}
+}
+
+func TestContractSyncRewindsOnReorg(t *testing.T) {
+	chain := newSimulatedChain(t)
+	store := openTestStore(t, filepath.Join(t.TempDir(), "chaindb"))
+	defer store.Close()
+	recorder := &eventRecorder{}
+	sync := newTestSync(t, chain, store, recorder)
+	ctx := context.Background()
+
+	chain.commit(2)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	fork, err := chain.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.emit("EpochChanged", big.NewInt(1), big.NewInt(2))
+	chain.commit(4)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if got := recorder.kinds(); len(got) != 1 || got[0] != "epoch_changed" {
+		t.Fatalf("events = %v, want the epoch change", got)
+	}
+
+	// Replace the blocks after the fork point with a longer chain in which
+	// a node is listed instead.
+	if err := chain.backend.Fork(ctx, fork.Hash()); err != nil {
+		t.Fatalf("Fork() error = %v", err)
+	}
+	node := ethCommon.HexToAddress("0x1234")
+	chain.emit("NodeListed", node, big.NewInt(1), big.NewInt(3))
+	chain.commit(6)
+	if err := sync.sync(ctx); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+
+	want := []string{"epoch_changed", "reorged", "node_listed"}
+	got := recorder.kinds()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if recorder.events[1].block > fork.Number.Uint64()+1 {
+		t.Errorf("reorged from block %d, want at most %d", recorder.events[1].block, fork.Number.Uint64()+1)
+	}
+	if recorder.events[2].node != node {
+		t.Errorf("listed node = %v, want %v", recorder.events[2].node, node)
+	}
+}
+
+type flakyRegistry struct {
+	NodeRegistry
+	down bool
+}
+
+func (r *flakyRegistry) EpochInfo(epoch int) (info common.EpochInfo, err error) {
+	if r.down {
+		return info, errors.New("connection refused")
+	}
+	info.Id.SetInt64(int64(epoch))
+	info.N.SetInt64(3)
+	return info, nil
+}
+
+func TestCachedRegistryServesStoredEpochs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chaindb")
+	store := openTestStore(t, path)
+	inner := &flakyRegistry{}
+	if _, err := (&cachedRegistry{NodeRegistry: inner, store: store}).EpochInfo(2); err != nil {
+		t.Fatalf("EpochInfo() error = %v", err)
+	}
+	store.Close()
+
+	// After a restart with the RPC down, the stored epoch is still served.
+	store = openTestStore(t, path)
+	defer store.Close()
+	inner.down = true
+	registry := &cachedRegistry{NodeRegistry: inner, store: store}
+	info, err := registry.EpochInfo(2)
+	if err != nil {
+		t.Fatalf("EpochInfo() error = %v", err)
+	}
+	if info.N.Int64() != 3 {
+		t.Errorf("EpochInfo().N = %v, want 3", info.N.String())
+	}
+	if _, err := registry.EpochInfo(3); err == nil {
+		t.Error("EpochInfo() of an unknown epoch returned no error with the RPC down")
+	}
+}
+
+// epochInfoRegistry serves the info of any epoch.
+type epochInfoRegistry struct {
+	NodeRegistry
+}
+
+func (epochInfoRegistry) EpochInfo(epoch int) (common.EpochInfo, error) {
+	return common.EpochInfo{Id: *big.NewInt(int64(epoch))}, nil
+}
+
+func TestReorgRereadsCurrentEpoch(t *testing.T) {
+	service := &ChainService{
+		registry:        epochInfoRegistry{},
+		cachedEpochInfo: &EpochCache{},
+		currentEpoch:    3,
+		contractEpoch:   func() (int, error) { return 2, nil },
+		whitelistCheck:  make(chan struct{}, 1),
+		nodeListRefresh: make(chan struct{}, 1),
+	}
+	service.reorged(10)
+	if epoch := service.getCurrentEpoch(); epoch != 2 {
+		t.Errorf("current epoch after the epoch change was reorged away = %d, want 2", epoch)
+	}
+	select {
+	case <-service.nodeListRefresh:
+	default:
+		t.Error("node list of the epoch was not refreshed")
+	}
+}
@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/arcana-network/dkgnode/common"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/torusresearch/bijson"
+)
+
+var (
+	epochInfoBytes    = []byte("e")
+	epochNodesBytes   = []byte("n")
+	nodeDetailsBytes  = []byte("d")
+	currentEpochBytes = []byte("c")
+	syncCursorBytes   = []byte("s")
+	bufferSizeBytes   = []byte("b")
+)
+
+// syncCursor is the last block whose contract logs have been processed.
+type syncCursor struct {
+	Number uint64         `json:"number"`
+	Hash   ethCommon.Hash `json:"hash"`
+}
+
+// EpochStore persists what the node knows about epochs and nodes, so that
+// it can serve them after a restart while the blockchain RPC is unavailable.
+type EpochStore struct {
+	db *leveldb.DB
+}
+
+func OpenEpochStore(path string) (*EpochStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EpochStore{db: db}, nil
+}
+
+func (s *EpochStore) Close() error {
+	return s.db.Close()
+}
+
+func epochKey(prefix []byte, epoch int) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], uint64(epoch))
+	return key
+}
+
+func (s *EpochStore) get(key []byte, v interface{}) bool {
+	data, err := s.db.Get(key, nil)
+	if err != nil {
+		if !errors.Is(err, leveldb.ErrNotFound) {
+			log.WithError(err).Error("EpochStore.get()")
+		}
+		return false
+	}
+	if err := bijson.Unmarshal(data, v); err != nil {
+		log.WithError(err).Error("EpochStore.get() could not unmarshal")
+		return false
+	}
+	return true
+}
+
+func (s *EpochStore) put(key []byte, v interface{}) {
+	data, err := bijson.Marshal(v)
+	if err == nil {
+		err = s.db.Put(key, data, nil)
+	}
+	if err != nil {
+		log.WithError(err).Error("EpochStore.put()")
+	}
+}
+
+func (s *EpochStore) EpochInfo(epoch int) (info common.EpochInfo, found bool) {
+	found = s.get(epochKey(epochInfoBytes, epoch), &info)
+	return
+}
+
+func (s *EpochStore) SetEpochInfo(epoch int, info common.EpochInfo) {
+	s.put(epochKey(epochInfoBytes, epoch), info)
+}
+
+func (s *EpochStore) Nodes(epoch int) (nodes []ethCommon.Address, found bool) {
+	found = s.get(epochKey(epochNodesBytes, epoch), &nodes)
+	return
+}
+
+func (s *EpochStore) SetNodes(epoch int, nodes []ethCommon.Address) {
+	s.put(epochKey(epochNodesBytes, epoch), nodes)
+}
+
+func (s *EpochStore) NodeDetails(node ethCommon.Address) (details NodeDetails, found bool) {
+	found = s.get(append(nodeDetailsBytes, node.Bytes()...), &details)
+	return
+}
+
+func (s *EpochStore) SetNodeDetails(node ethCommon.Address, details NodeDetails) {
+	s.put(append(nodeDetailsBytes, node.Bytes()...), details)
+}
+
+func (s *EpochStore) CurrentEpoch() (epoch int, found bool) {
+	found = s.get(currentEpochBytes, &epoch)
+	return
+}
+
+func (s *EpochStore) SetCurrentEpoch(epoch int) {
+	s.put(currentEpochBytes, epoch)
+}
+
+func (s *EpochStore) BufferSize() (size int, found bool) {
+	found = s.get(bufferSizeBytes, &size)
+	return
+}
+
+func (s *EpochStore) SetBufferSize(size int) {
+	s.put(bufferSizeBytes, size)
+}
+
+func (s *EpochStore) syncCursor() (cursor syncCursor, found bool) {
+	found = s.get(syncCursorBytes, &cursor)
+	return
+}
+
+func (s *EpochStore) setSyncCursor(cursor syncCursor) {
+	s.put(syncCursorBytes, cursor)
+}
+
+// cachedRegistry stores every answer of the wrapped registry and serves the
+// stored answer when the registry cannot be reached.
+type cachedRegistry struct {
+	NodeRegistry
+	store *EpochStore
+}
+
+func (r *cachedRegistry) EpochInfo(epoch int) (common.EpochInfo, error) {
+	info, err := r.NodeRegistry.EpochInfo(epoch)
+	if err == nil {
+		r.store.SetEpochInfo(epoch, info)
+		return info, nil
+	}
+	if cached, found := r.store.EpochInfo(epoch); found {
+		log.WithError(err).WithField("epoch", epoch).Warn("serving stored epoch info")
+		return cached, nil
+	}
+	return info, err
+}
+
+func (r *cachedRegistry) Nodes(epoch int) ([]ethCommon.Address, error) {
+	nodes, err := r.NodeRegistry.Nodes(epoch)
+	if err == nil {
+		r.store.SetNodes(epoch, nodes)
+		return nodes, nil
+	}
+	if cached, found := r.store.Nodes(epoch); found {
+		log.WithError(err).WithField("epoch", epoch).Warn("serving stored node list")
+		return cached, nil
+	}
+	return nodes, err
+}
+
+func (r *cachedRegistry) NodeDetails(node ethCommon.Address) (NodeDetails, error) {
+	details, err := r.NodeRegistry.NodeDetails(node)
+	if err == nil {
+		r.store.SetNodeDetails(node, details)
+		return details, nil
+	}
+	if cached, found := r.store.NodeDetails(node); found {
+		log.WithError(err).WithField("node", node).Warn("serving stored node details")
+		return cached, nil
+	}
+	return details, err
+}
+
+func (r *cachedRegistry) BufferSize() (int, error) {
+	size, err := r.NodeRegistry.BufferSize()
+	if err == nil {
+		r.store.SetBufferSize(size)
+		return size, nil
+	}
+	if cached, found := r.store.BufferSize(); found {
+		return cached, nil
+	}
+	return size, err
+}
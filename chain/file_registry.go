@@ -0,0 +1,335 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arcana-network/dkgnode/common"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+const registryReloadInterval = 10 * time.Second
+
+// SignedRegistry is the file read by the file node registry. Signature is
+// the registry admin's signature over the Keccak256 hash of the compacted
+// Registry JSON, so whitespace in the file does not affect the signature.
+type SignedRegistry struct {
+	Registry  json.RawMessage `json:"registry"`
+	Signature hexutil.Bytes   `json:"signature"`
+}
+
+// RegistryFile lists the epochs and nodes of a network without a NodeList
+// contract.
+type RegistryFile struct {
+	CurrentEpoch int                          `json:"currentEpoch"`
+	BufferSize   int                          `json:"bufferSize"`
+	Epochs       []RegistryEpoch              `json:"epochs"`
+	Nodes        map[string]RegistryNodeEntry `json:"nodes"`
+}
+
+type RegistryEpoch struct {
+	ID        int      `json:"id"`
+	N         int      `json:"n"`
+	K         int      `json:"k"`
+	T         int      `json:"t"`
+	PrevEpoch int      `json:"prevEpoch"`
+	NextEpoch int      `json:"nextEpoch"`
+	Nodes     []string `json:"nodes"`
+}
+
+type RegistryNodeEntry struct {
+	DeclaredIp         string        `json:"declaredIp"`
+	Position           int           `json:"position"`
+	PublicKey          hexutil.Bytes `json:"publicKey"`
+	TmP2PListenAddress string        `json:"tmp2pListenAddress"`
+	P2pListenAddress   string        `json:"p2pListenAddress"`
+}
+
+// SignRegistry signs a registry with the admin key.
+func SignRegistry(registry []byte, key *ecdsa.PrivateKey) (*SignedRegistry, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, registry); err != nil {
+		return nil, err
+	}
+	if _, err := parseRegistry(compact.Bytes()); err != nil {
+		return nil, err
+	}
+	sig, err := ethCrypto.Sign(ethCrypto.Keccak256(compact.Bytes()), key)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedRegistry{Registry: compact.Bytes(), Signature: sig}, nil
+}
+
+// Verify checks that the registry was signed by admin and decodes it.
+func (s *SignedRegistry) Verify(admin ethCommon.Address) (*RegistryFile, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, s.Registry); err != nil {
+		return nil, err
+	}
+	pubKey, err := ethCrypto.SigToPub(ethCrypto.Keccak256(compact.Bytes()), s.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry signature: %w", err)
+	}
+	if signer := ethCrypto.PubkeyToAddress(*pubKey); signer != admin {
+		return nil, fmt.Errorf("registry signed by %s, want %s", signer, admin)
+	}
+	return parseRegistry(compact.Bytes())
+}
+
+func parseRegistry(data []byte) (*RegistryFile, error) {
+	var registry RegistryFile
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, err
+	}
+	if registry.epoch(registry.CurrentEpoch) == nil {
+		return nil, fmt.Errorf("current epoch %d is not listed", registry.CurrentEpoch)
+	}
+	// Nodes are looked up by address, so an entry has to carry the key of
+	// its address or another key would sign for the node.
+	for node, entry := range registry.Nodes {
+		if !ethCommon.IsHexAddress(node) {
+			return nil, fmt.Errorf("node %s is not an address", node)
+		}
+		pubKey, err := ethCrypto.UnmarshalPubkey(entry.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("node %s has an invalid public key: %w", node, err)
+		}
+		if address := ethCrypto.PubkeyToAddress(*pubKey); address != ethCommon.HexToAddress(node) {
+			return nil, fmt.Errorf("public key of node %s belongs to %s", node, address)
+		}
+	}
+	for _, epoch := range registry.Epochs {
+		if epoch.N != len(epoch.Nodes) {
+			return nil, fmt.Errorf("epoch %d lists %d nodes, want %d", epoch.ID, len(epoch.Nodes), epoch.N)
+		}
+		for _, node := range epoch.Nodes {
+			if _, ok := registry.node(ethCommon.HexToAddress(node)); !ok {
+				return nil, fmt.Errorf("node %s of epoch %d has no details", node, epoch.ID)
+			}
+		}
+	}
+	return &registry, nil
+}
+
+func (r *RegistryFile) epoch(id int) *RegistryEpoch {
+	for i := range r.Epochs {
+		if r.Epochs[i].ID == id {
+			return &r.Epochs[i]
+		}
+	}
+	return nil
+}
+
+func (r *RegistryFile) node(address ethCommon.Address) (RegistryNodeEntry, bool) {
+	for key, entry := range r.Nodes {
+		if ethCommon.HexToAddress(key) == address {
+			return entry, true
+		}
+	}
+	return RegistryNodeEntry{}, false
+}
+
+// fileRegistry reads the node registry from a file signed by the registry
+// admin. The admin moves the network to a new epoch by replacing the file.
+type fileRegistry struct {
+	sync.RWMutex
+	path     string
+	admin    ethCommon.Address
+	modTime  time.Time
+	registry *RegistryFile
+	changed  chan int
+}
+
+func newFileRegistry(path string, admin ethCommon.Address) (*fileRegistry, error) {
+	r := &fileRegistry{
+		path:    path,
+		admin:   admin,
+		changed: make(chan int, 1),
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+// reload reads the file if it changed since it was last accepted and
+// reports whether the registry was replaced. A rejected file is read again
+// on every reload until it is fixed.
+func (r *fileRegistry) reload() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+	r.RLock()
+	unchanged := info.ModTime().Equal(r.modTime)
+	r.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return false, err
+	}
+	var signed SignedRegistry
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return false, err
+	}
+	registry, err := signed.Verify(r.admin)
+	if err != nil {
+		return false, err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if r.registry != nil && registry.CurrentEpoch < r.registry.CurrentEpoch {
+		return false, fmt.Errorf("registry moves back from epoch %d to %d", r.registry.CurrentEpoch, registry.CurrentEpoch)
+	}
+	r.modTime = info.ModTime()
+	r.registry = registry
+	return true, nil
+}
+
+func (r *fileRegistry) watch() {
+	ticker := time.NewTicker(registryReloadInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		previous := r.CurrentEpoch()
+		replaced, err := r.reload()
+		if err != nil {
+			log.WithError(err).WithField("path", r.path).Error("could not reload node registry, keeping the current one")
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.WithField("path", r.path).Info("node registry reloaded")
+		if epoch := r.CurrentEpoch(); epoch != previous {
+			log.WithFields(log.Fields{
+				"from": previous,
+				"to":   epoch,
+			}).Info("node registry moved to a new epoch")
+			r.changed <- epoch
+		}
+	}
+}
+
+func (r *fileRegistry) current() *RegistryFile {
+	r.RLock()
+	defer r.RUnlock()
+	return r.registry
+}
+
+func (r *fileRegistry) CurrentEpoch() int {
+	return r.current().CurrentEpoch
+}
+
+func (r *fileRegistry) EpochChanged() <-chan int {
+	return r.changed
+}
+
+func (r *fileRegistry) EpochInfo(epoch int) (common.EpochInfo, error) {
+	e := r.current().epoch(epoch)
+	if e == nil {
+		return common.EpochInfo{}, fmt.Errorf("epoch %v has not been initialized", epoch)
+	}
+	return common.EpochInfo{
+		Id:        *big.NewInt(int64(e.ID)),
+		N:         *big.NewInt(int64(e.N)),
+		K:         *big.NewInt(int64(e.K)),
+		T:         *big.NewInt(int64(e.T)),
+		PrevEpoch: *big.NewInt(int64(e.PrevEpoch)),
+		NextEpoch: *big.NewInt(int64(e.NextEpoch)),
+	}, nil
+}
+
+func (r *fileRegistry) Nodes(epoch int) ([]ethCommon.Address, error) {
+	e := r.current().epoch(epoch)
+	if e == nil {
+		return nil, fmt.Errorf("epoch %v has not been initialized", epoch)
+	}
+	nodes := make([]ethCommon.Address, 0, len(e.Nodes))
+	for _, node := range e.Nodes {
+		nodes = append(nodes, ethCommon.HexToAddress(node))
+	}
+	return nodes, nil
+}
+
+func (r *fileRegistry) NodeDetails(node ethCommon.Address) (NodeDetails, error) {
+	entry, ok := r.current().node(node)
+	if !ok {
+		return NodeDetails{}, fmt.Errorf("node %s is not in the registry", node)
+	}
+	pubKey, err := ethCrypto.UnmarshalPubkey(entry.PublicKey)
+	if err != nil {
+		return NodeDetails{}, err
+	}
+	return NodeDetails{
+		DeclaredIp:         entry.DeclaredIp,
+		Position:           big.NewInt(int64(entry.Position)),
+		PubKx:              pubKey.X,
+		PubKy:              pubKey.Y,
+		TmP2PListenAddress: entry.TmP2PListenAddress,
+		P2pListenAddress:   entry.P2pListenAddress,
+	}, nil
+}
+
+// IsWhitelisted reports whether the node is listed for the epoch. Listing a
+// node in the file both whitelists and registers it.
+func (r *fileRegistry) IsWhitelisted(epoch int, node ethCommon.Address) (bool, error) {
+	e := r.current().epoch(epoch)
+	if e == nil {
+		return false, nil
+	}
+	for _, n := range e.Nodes {
+		if ethCommon.HexToAddress(n) == node {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fileRegistry) IsRegistered(epoch int, node ethCommon.Address) (bool, error) {
+	return r.IsWhitelisted(epoch, node)
+}
+
+// Register only checks that the node is listed, as the file can only be
+// changed by the registry admin.
+func (r *fileRegistry) Register(epoch int, declaredIP string, pubKey *ecdsa.PublicKey) error {
+	listed, err := r.IsWhitelisted(epoch, ethCrypto.PubkeyToAddress(*pubKey))
+	if err != nil {
+		return err
+	}
+	if !listed {
+		return errors.New("node is not listed in the registry file")
+	}
+	return nil
+}
+
+func (r *fileRegistry) BufferSize() (int, error) {
+	size := r.current().BufferSize
+	if size == 0 {
+		return 0, errors.New("registry does not set a buffer size")
+	}
+	return size, nil
+}
+
+// ParseRegistryAdmin parses the configured registry admin address.
+func ParseRegistryAdmin(admin string) (ethCommon.Address, error) {
+	if !ethCommon.IsHexAddress(admin) {
+		return ethCommon.Address{}, fmt.Errorf("invalid node registry admin address %q", admin)
+	}
+	return ethCommon.HexToAddress(admin), nil
+}
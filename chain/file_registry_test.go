@@ -0,0 +1,164 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func testRegistry(t *testing.T, currentEpoch int, nodes []*ecdsa.PrivateKey) []byte {
+	registry := RegistryFile{
+		CurrentEpoch: currentEpoch,
+		BufferSize:   100,
+		Nodes:        make(map[string]RegistryNodeEntry),
+	}
+	var addresses []string
+	for i, key := range nodes {
+		address := ethCrypto.PubkeyToAddress(key.PublicKey).Hex()
+		addresses = append(addresses, address)
+		registry.Nodes[address] = RegistryNodeEntry{
+			DeclaredIp: fmt.Sprintf("10.0.0.%d:8080", i+1),
+			Position:   i + 1,
+			PublicKey:  hexutil.Bytes(ethCrypto.FromECDSAPub(&key.PublicKey)),
+		}
+	}
+	for epoch := 1; epoch <= currentEpoch; epoch++ {
+		registry.Epochs = append(registry.Epochs, RegistryEpoch{
+			ID: epoch, N: len(nodes), K: 1, T: 1,
+			PrevEpoch: epoch - 1, NextEpoch: epoch + 1,
+			Nodes: addresses,
+		})
+	}
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeSignedRegistry(t *testing.T, path string, registry []byte, admin *ecdsa.PrivateKey) {
+	signed, err := SignRegistry(registry, admin)
+	if err != nil {
+		t.Fatalf("SignRegistry() error = %v", err)
+	}
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generateKeys(t *testing.T, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := ethCrypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+func TestFileRegistryRejectsForeignSignature(t *testing.T) {
+	keys := generateKeys(t, 5)
+	admin, other, nodes := keys[0], keys[1], keys[2:]
+	path := filepath.Join(t.TempDir(), "registry.json")
+
+	writeSignedRegistry(t, path, testRegistry(t, 1, nodes), other)
+	if _, err := newFileRegistry(path, ethCrypto.PubkeyToAddress(admin.PublicKey)); err == nil {
+		t.Fatal("newFileRegistry() accepted a registry signed by another key")
+	}
+
+	writeSignedRegistry(t, path, testRegistry(t, 1, nodes), admin)
+	registry, err := newFileRegistry(path, ethCrypto.PubkeyToAddress(admin.PublicKey))
+	if err != nil {
+		t.Fatalf("newFileRegistry() error = %v", err)
+	}
+	self := ethCrypto.PubkeyToAddress(nodes[1].PublicKey)
+	if listed, _ := registry.IsWhitelisted(1, self); !listed {
+		t.Error("IsWhitelisted() = false for a listed node")
+	}
+	details, err := registry.NodeDetails(self)
+	if err != nil {
+		t.Fatalf("NodeDetails() error = %v", err)
+	}
+	if details.Position.Int64() != 2 || details.PubKx.Cmp(nodes[1].PublicKey.X) != 0 {
+		t.Errorf("NodeDetails() = %+v, want position 2 and the node public key", details)
+	}
+	info, err := registry.EpochInfo(1)
+	if err != nil || info.N.Int64() != 3 {
+		t.Errorf("EpochInfo(1) = %+v, %v, want N = 3", info, err)
+	}
+}
+
+func TestFileRegistryReloadSwitchesEpoch(t *testing.T) {
+	keys := generateKeys(t, 4)
+	admin, nodes := keys[0], keys[1:]
+	path := filepath.Join(t.TempDir(), "registry.json")
+	adminAddress := ethCrypto.PubkeyToAddress(admin.PublicKey)
+
+	writeSignedRegistry(t, path, testRegistry(t, 1, nodes), admin)
+	registry := &fileRegistry{path: path, admin: adminAddress, changed: make(chan int, 1)}
+	if _, err := registry.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	writeSignedRegistry(t, path, testRegistry(t, 2, nodes), admin)
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := registry.reload()
+	if err != nil || !replaced {
+		t.Fatalf("reload() = %v, %v, want the registry replaced", replaced, err)
+	}
+	if got := registry.CurrentEpoch(); got != 2 {
+		t.Errorf("CurrentEpoch() = %d, want 2", got)
+	}
+
+	writeSignedRegistry(t, path, testRegistry(t, 1, nodes), admin)
+	latest := later.Add(time.Second)
+	if err := os.Chtimes(path, latest, latest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.reload(); err == nil {
+		t.Error("reload() accepted a registry that moves back an epoch")
+	}
+	if _, err := registry.reload(); err == nil {
+		t.Error("second reload() skipped the rejected registry")
+	}
+	if got := registry.CurrentEpoch(); got != 2 {
+		t.Errorf("CurrentEpoch() = %d after a rejected reload, want 2", got)
+	}
+}
+
+func TestFileRegistryRejectsKeyOfAnotherNode(t *testing.T) {
+	keys := generateKeys(t, 4)
+	admin, nodes := keys[0], keys[1:]
+
+	var registry RegistryFile
+	if err := json.Unmarshal(testRegistry(t, 1, nodes), &registry); err != nil {
+		t.Fatal(err)
+	}
+	victim := ethCrypto.PubkeyToAddress(nodes[0].PublicKey).Hex()
+	entry := registry.Nodes[victim]
+	entry.PublicKey = ethCrypto.FromECDSAPub(&nodes[1].PublicKey)
+	registry.Nodes[victim] = entry
+	data, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignRegistry(data, admin); err == nil {
+		t.Error("SignRegistry() accepted an entry with the key of another node")
+	}
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/nodelist"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ContractNodeRegistry = "contract"
+	FileNodeRegistry     = "file"
+)
+
+// NodeDetails is a node as listed in the node registry.
+type NodeDetails struct {
+	DeclaredIp         string
+	Position           *big.Int
+	PubKx              *big.Int
+	PubKy              *big.Int
+	TmP2PListenAddress string
+	P2pListenAddress   string
+}
+
+// NodeRegistry is the source of epochs, node lists, thresholds and the key
+// buffer size.
+type NodeRegistry interface {
+	EpochInfo(epoch int) (common.EpochInfo, error)
+	Nodes(epoch int) ([]ethCommon.Address, error)
+	NodeDetails(node ethCommon.Address) (NodeDetails, error)
+	IsWhitelisted(epoch int, node ethCommon.Address) (bool, error)
+	IsRegistered(epoch int, node ethCommon.Address) (bool, error)
+	Register(epoch int, declaredIP string, pubKey *ecdsa.PublicKey) error
+	BufferSize() (int, error)
+}
+
+// EpochSource is implemented by registries that move the network to a new
+// epoch themselves. Registries that do not implement it stay at the genesis
+// epoch.
+type EpochSource interface {
+	CurrentEpoch() int
+	// EpochChanged receives the new epoch every time it changes.
+	EpochChanged() <-chan int
+}
+
+// contractRegistry reads the node registry from the NodeList contract. Epochs,
+// node lists and node details are read through quorum, since the network
+// setup depends on them.
+type contractRegistry struct {
+	nodeList  *nodelist.NodeList
+	quorum    *nodelist.NodeListCaller
+	callOpts  func() *bind.CallOpts
+	txManager *TxManager
+}
+
+func (r *contractRegistry) EpochInfo(epoch int) (common.EpochInfo, error) {
+	result, err := r.quorum.GetEpochInfo(r.callOpts(), big.NewInt(int64(epoch)))
+	log.WithField("info", result).Debug("GetEpochInfo()")
+	if err != nil {
+		return common.EpochInfo{}, err
+	}
+	if result.Id.Cmp(big.NewInt(0)) == 0 {
+		return common.EpochInfo{}, fmt.Errorf("epoch %v has not been initialized", epoch)
+	}
+	return common.EpochInfo{
+		Id:        *result.Id,
+		N:         *result.N,
+		K:         *result.K,
+		T:         *result.T,
+		PrevEpoch: *result.PrevEpoch,
+		NextEpoch: *result.NextEpoch,
+	}, nil
+}
+
+func (r *contractRegistry) Nodes(epoch int) ([]ethCommon.Address, error) {
+	return r.quorum.GetNodes(nil, big.NewInt(int64(epoch)))
+}
+
+func (r *contractRegistry) NodeDetails(node ethCommon.Address) (NodeDetails, error) {
+	details, err := r.quorum.NodeDetails(nil, node)
+	if err != nil {
+		return NodeDetails{}, err
+	}
+	return NodeDetails{
+		DeclaredIp:         details.DeclaredIp,
+		Position:           details.Position,
+		PubKx:              details.PubKx,
+		PubKy:              details.PubKy,
+		TmP2PListenAddress: details.TmP2PListenAddress,
+		P2pListenAddress:   details.P2pListenAddress,
+	}, nil
+}
+
+func (r *contractRegistry) IsWhitelisted(epoch int, node ethCommon.Address) (bool, error) {
+	return r.nodeList.IsWhitelisted(nil, big.NewInt(int64(epoch)), node)
+}
+
+func (r *contractRegistry) IsRegistered(epoch int, node ethCommon.Address) (bool, error) {
+	return r.nodeList.NodeRegistered(r.callOpts(), big.NewInt(int64(epoch)), node)
+}
+
+func (r *contractRegistry) Register(epoch int, declaredIP string, pubKey *ecdsa.PublicKey) error {
+	_, err := r.txManager.Send(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return r.nodeList.ListNode(opts, big.NewInt(int64(epoch)), declaredIP, pubKey.X, pubKey.Y, "", "")
+	})
+	return err
+}
+
+func (r *contractRegistry) BufferSize() (int, error) {
+	b, err := r.nodeList.BufferSize(nil)
+	if err != nil {
+		return 0, err
+	}
+	return int(b.Int64()), nil
+}
@@ -0,0 +1,43 @@
+package chain
+
+import (
+	"errors"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/arcana-network/dkgnode/signer"
+)
+
+// remoteSignerToken reads the shared secret of the remote signer from its
+// file, or from the secret manager when no file is configured.
+func remoteSignerToken(conf *config.Config) (string, error) {
+	if conf.SignerTokenFile != "" {
+		return signer.ReadToken(conf.SignerTokenFile)
+	}
+	b, err := config.GetSecret(conf.SecretConfigPath, secret.SignerToken)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("signer token is empty")
+	}
+	return token, nil
+}
+
+// rotateSignerToken swaps the shared secret of the remote signer every time
+// it is rotated in the secret manager, which watch reports.
+func rotateSignerToken(s *signer.RemoteSigner, watch func(name string, onChange func([]byte)) error) error {
+	return watch(secret.SignerToken, func(value []byte) {
+		token := strings.TrimSpace(string(value))
+		if token == "" {
+			log.Warn("rotated remote signer token is empty, keeping the previous one")
+			return
+		}
+		s.SetToken(token)
+		log.Info("remote signer token rotated")
+	})
+}
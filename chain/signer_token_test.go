@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/arcana-network/dkgnode/signer"
+)
+
+func TestRotatedSignerTokenTakesEffect(t *testing.T) {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := signer.NewLocalSigner(ethCrypto.FromECDSA(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(signer.NewHandler(local, "rotated"))
+	defer server.Close()
+	remote, err := signer.NewRemoteSigner(server.URL, "initial")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rotate func([]byte)
+	err = rotateSignerToken(remote, func(name string, onChange func([]byte)) error {
+		if name != secret.SignerToken {
+			t.Errorf("watched secret %q", name)
+		}
+		rotate = onChange
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := ethCrypto.Keccak256([]byte("tx"))
+	if _, err := remote.SignHash(hash); err == nil {
+		t.Fatal("signer accepted a token it does not know")
+	}
+	rotate([]byte("  \n"))
+	rotate([]byte("rotated\n"))
+	if _, err := remote.SignHash(hash); err != nil {
+		t.Errorf("sign with the rotated token: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcana-network/dkgnode/signer"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	receiptPollInterval = 3 * time.Second
+	// stuckAfter is how long a transaction may stay unmined before it is
+	// replaced with a higher gas price.
+	stuckAfter  = 90 * time.Second
+	maxGasBumps = 5
+	// maxNonceResyncs caps how often a send is retried with a fresh nonce.
+	maxNonceResyncs = 3
+)
+
+// txBackend is the part of the blockchain client used to send transactions.
+// It is satisfied by ClientPool and the simulated backend.
+type txBackend interface {
+	PendingNonceAt(ctx context.Context, account ethCommon.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash ethCommon.Hash) (*types.Receipt, error)
+}
+
+// TxBuilder builds and signs a transaction with the given options, e.g. a
+// contract binding method. The options have NoSend set; the manager sends
+// the transaction itself.
+type TxBuilder func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// TxManager sends the transactions of a single account. It keeps track of
+// the nonce so that concurrent sends do not collide, and replaces
+// transactions that are not mined in time with a higher gas price.
+type TxManager struct {
+	sync.Mutex
+	backend  txBackend
+	signer   signer.Signer
+	from     ethCommon.Address
+	chainID  *big.Int
+	txSigner types.Signer
+	// nonce is the next nonce to use, valid when nonceKnown is set.
+	nonce      uint64
+	nonceKnown bool
+
+	pollInterval time.Duration
+	stuckAfter   time.Duration
+	maxBumps     int
+}
+
+func NewTxManager(backend txBackend, s signer.Signer, chainID *big.Int) *TxManager {
+	return &TxManager{
+		backend:      backend,
+		signer:       s,
+		from:         signer.Address(s),
+		chainID:      chainID,
+		txSigner:     types.LatestSignerForChainID(chainID),
+		pollInterval: receiptPollInterval,
+		stuckAfter:   stuckAfter,
+		maxBumps:     maxGasBumps,
+	}
+}
+
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "nonce too low")
+}
+
+func isAlreadyKnown(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "already known") ||
+		strings.Contains(err.Error(), "known transaction"))
+}
+
+func isUnderpriced(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "underpriced")
+}
+
+// syncNonce takes the pending nonce of the node unless the local nonce is
+// ahead of it, which happens while sent transactions are not yet in the
+// node's pool.
+func (m *TxManager) syncNonce(ctx context.Context, force bool) error {
+	pending, err := m.backend.PendingNonceAt(ctx, m.from)
+	if err != nil {
+		return err
+	}
+	if force || !m.nonceKnown || pending > m.nonce {
+		m.nonce = pending
+	}
+	m.nonceKnown = true
+	return nil
+}
+
+// Send builds the transaction, sends it and waits until it is mined. A
+// transaction that is stuck is replaced with the same nonce and a gas price
+// raised by 12.5% until it is mined or maxBumps is reached.
+func (m *TxManager) Send(ctx context.Context, build TxBuilder) (*types.Receipt, error) {
+	tx, err := m.broadcastNew(ctx, build)
+	if err != nil {
+		return nil, err
+	}
+	return m.waitMined(ctx, tx)
+}
+
+// broadcastNew assigns the next nonce and sends the first version of the
+// transaction. The nonce is only consumed once the transaction is accepted.
+func (m *TxManager) broadcastNew(ctx context.Context, build TxBuilder) (*types.Transaction, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if err := m.syncNonce(ctx, attempt > 0); err != nil {
+			return nil, fmt.Errorf("could not get nonce: %w", err)
+		}
+		gasPrice, err := m.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get gas price: %w", err)
+		}
+		opts := signer.TransactOpts(m.signer, m.chainID)
+		opts.Context = ctx
+		opts.Nonce = new(big.Int).SetUint64(m.nonce)
+		opts.GasPrice = gasPrice
+		opts.Value = big.NewInt(0)
+		opts.NoSend = true
+		tx, err := build(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		err = m.backend.SendTransaction(ctx, tx)
+		if isNonceTooLow(err) && attempt < maxNonceResyncs {
+			log.WithField("nonce", m.nonce).Warn("TxManager: nonce too low, resyncing")
+			continue
+		}
+		if err != nil && !isAlreadyKnown(err) {
+			return nil, err
+		}
+		m.nonce++
+		log.WithFields(log.Fields{
+			"tx":       tx.Hash().Hex(),
+			"nonce":    tx.Nonce(),
+			"gasPrice": tx.GasPrice(),
+		}).Info("TxManager: sent transaction")
+		return tx, nil
+	}
+}
+
+// bump signs a copy of tx with a gas price raised by 12.5%, or to the
+// current suggestion if that is higher.
+func (m *TxManager) bump(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	gasPrice := new(big.Int).Add(tx.GasPrice(), new(big.Int).Div(tx.GasPrice(), big.NewInt(8)))
+	gasPrice.Add(gasPrice, big.NewInt(1))
+	if suggested, err := m.backend.SuggestGasPrice(ctx); err == nil && suggested.Cmp(gasPrice) > 0 {
+		gasPrice = suggested
+	}
+	replacement := types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: gasPrice,
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+	sig, err := m.signer.SignHash(m.txSigner.Hash(replacement).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return replacement.WithSignature(m.txSigner, sig)
+}
+
+// waitMined polls for the receipt of any version of the transaction.
+func (m *TxManager) waitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	sent := []*types.Transaction{tx}
+	lastSent := time.Now()
+	bumps := 0
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, s := range sent {
+			receipt, err := m.backend.TransactionReceipt(ctx, s.Hash())
+			if err == nil {
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					return receipt, fmt.Errorf("transaction %s reverted", s.Hash().Hex())
+				}
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				log.WithError(err).Warn("TxManager: could not get receipt")
+			}
+		}
+
+		if time.Since(lastSent) >= m.stuckAfter {
+			if bumps >= m.maxBumps {
+				return nil, fmt.Errorf("transaction %s not mined after %d gas bumps", tx.Hash().Hex(), bumps)
+			}
+			latest := sent[len(sent)-1]
+			replacement, err := m.bump(ctx, latest)
+			if err != nil {
+				return nil, err
+			}
+			bumps++
+			err = m.backend.SendTransaction(ctx, replacement)
+			switch {
+			case err == nil || isAlreadyKnown(err):
+				sent = append(sent, replacement)
+				lastSent = time.Now()
+				log.WithFields(log.Fields{
+					"tx":       replacement.Hash().Hex(),
+					"nonce":    replacement.Nonce(),
+					"gasPrice": replacement.GasPrice(),
+				}).Warn("TxManager: replaced stuck transaction")
+			case isUnderpriced(err):
+				// Keep the raised price so that the next bump goes above it.
+				sent = append(sent, replacement)
+			case isNonceTooLow(err):
+				// One of the sent versions was mined; its receipt shows up
+				// on the next poll.
+				lastSent = time.Now()
+			default:
+				log.WithError(err).Warn("TxManager: could not send replacement")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
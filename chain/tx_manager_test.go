@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type testSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *testSigner) PublicKey() *ecdsa.PublicKey { return &s.key.PublicKey }
+func (s *testSigner) SignHash(hash []byte) ([]byte, error) {
+	return ethCrypto.Sign(hash, s.key)
+}
+
+// droppingBackend loses the first transaction sent, like an RPC that accepts
+// a transaction and never propagates it, and mines every later one.
+type droppingBackend struct {
+	*backends.SimulatedBackend
+	sent int
+}
+
+func (b *droppingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent++
+	if b.sent == 1 {
+		return nil
+	}
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+func TestTxManagerReplacesStuckTransaction(t *testing.T) {
+	chain := newSimulatedChain(t)
+	backend := &droppingBackend{SimulatedBackend: chain.backend}
+	manager := NewTxManager(backend, &testSigner{chain.key}, params.AllEthashProtocolChanges.ChainID)
+	manager.pollInterval = 10 * time.Millisecond
+	manager.stuckAfter = 50 * time.Millisecond
+
+	transfer := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		tx := types.NewTransaction(opts.Nonce.Uint64(), chain.emitter, big.NewInt(0), 100000, opts.GasPrice, make([]byte, 32))
+		return opts.Signer(opts.From, tx)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	receipt, err := manager.Send(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if backend.sent != 2 {
+		t.Errorf("sent %d transactions, want the dropped one and its replacement", backend.sent)
+	}
+	mined, _, err := chain.backend.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gasPrice, _ := chain.backend.SuggestGasPrice(ctx)
+	if mined.GasPrice().Cmp(gasPrice) <= 0 {
+		t.Errorf("replacement gas price = %v, want above %v", mined.GasPrice(), gasPrice)
+	}
+
+	// The next transaction takes the following nonce.
+	receipt, err = manager.Send(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	mined, _, _ = chain.backend.TransactionByHash(ctx, receipt.TxHash)
+	if mined.Nonce() != 2 {
+		t.Errorf("nonce = %d, want 2", mined.Nonce())
+	}
+}
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	auditExport "github.com/arcana-network/dkgnode/cmd/audit/export"
+	auditVerify "github.com/arcana-network/dkgnode/cmd/audit/verify"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Command to check and export the share release audit log",
+	}
+
+	cmd.AddCommand(auditVerify.GetCommand())
+	cmd.AddCommand(auditExport.GetCommand())
+	return cmd
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/arcana-network/dkgnode/audit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logFlag     = "log"
+	outFlag     = "out"
+	fromSeqFlag = "from-seq"
+	toSeqFlag   = "to-seq"
+	sinceFlag   = "since"
+	untilFlag   = "until"
+)
+
+var logPath string
+var outPath string
+var fromSeq uint64
+var toSeq uint64
+var since string
+var until string
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Used to export a range of an audit log, which can be verified on its own",
+		RunE:  runCommand,
+	}
+
+	setFlags(cmd)
+
+	_ = cmd.MarkFlagRequired(logFlag)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&logPath,
+		logFlag,
+		"",
+		"the path to the audit log",
+	)
+	cmd.Flags().StringVar(
+		&outPath,
+		outFlag,
+		"",
+		"file to write the records to, stdout when empty",
+	)
+	cmd.Flags().Uint64Var(
+		&fromSeq,
+		fromSeqFlag,
+		0,
+		"the first sequence number to export",
+	)
+	cmd.Flags().Uint64Var(
+		&toSeq,
+		toSeqFlag,
+		0,
+		"the last sequence number to export, no limit when 0",
+	)
+	cmd.Flags().StringVar(
+		&since,
+		sinceFlag,
+		"",
+		"export records written at or after this RFC 3339 time",
+	)
+	cmd.Flags().StringVar(
+		&until,
+		untilFlag,
+		"",
+		"export records written before this RFC 3339 time",
+	)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	filter := audit.Filter{FromSeq: fromSeq, ToSeq: toSeq}
+	var err error
+	if since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return fmt.Errorf("invalid --%s: %w", sinceFlag, err)
+		}
+	}
+	if until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return fmt.Errorf("invalid --%s: %w", untilFlag, err)
+		}
+	}
+
+	in, err := os.Open(logPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var out io.Writer = os.Stdout
+	if outPath != "" {
+		file, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	written, err := audit.Export(in, out, filter)
+	if err != nil {
+		return err
+	}
+	if outPath != "" {
+		fmt.Printf("%d records exported to %s\n", written, outPath)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arcana-network/dkgnode/audit"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logFlag    = "log"
+	signerFlag = "signer"
+)
+
+var logPath string
+var signer string
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Used to check the hash chain and the signatures of an audit log or of a range exported from it",
+		RunE:  runCommand,
+	}
+
+	setFlags(cmd)
+
+	_ = cmd.MarkFlagRequired(logFlag)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&logPath,
+		logFlag,
+		"",
+		"the path to the audit log, e.g. <data-dir>/audit/share_releases.jsonl",
+	)
+	cmd.Flags().StringVar(
+		&signer,
+		signerFlag,
+		"",
+		"the address of the node that has to have signed every record",
+	)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	var expected *ethCommon.Address
+	if signer != "" {
+		if !ethCommon.IsHexAddress(signer) {
+			return fmt.Errorf("%q is not an address", signer)
+		}
+		address := ethCommon.HexToAddress(signer)
+		expected = &address
+	}
+	file, err := os.Open(logPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := audit.Verify(file, expected)
+	if err != nil {
+		return fmt.Errorf("%s is invalid after %d records: %w", logPath, result.Records, err)
+	}
+	if result.Records == 0 {
+		fmt.Printf("%s has no records\n", logPath)
+		return nil
+	}
+	fmt.Printf("%s is valid: %d records, seq %d to %d, last hash %s\n",
+		logPath, result.Records, result.FirstSeq, result.LastSeq, result.LastHash)
+	return nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	configValidate "github.com/arcana-network/dkgnode/cmd/config/validate"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Command to check the node config",
+	}
+
+	cmd.AddCommand(configValidate.GetCommand())
+	return cmd
+}
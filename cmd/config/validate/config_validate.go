@@ -0,0 +1,51 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/spf13/cobra"
+)
+
+const configFlag = "config"
+
+var configPath string
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Used to report every invalid value of a config file, with the DKG_ environment overrides applied",
+		RunE:  runCommand,
+	}
+
+	setFlags(cmd)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&configPath,
+		configFlag,
+		"./config.json",
+		"the path to the JSON, YAML or TOML config",
+	)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) error {
+	// The report is the output, usage does not help here.
+	cmd.SilenceUsage = true
+	c, err := config.ReadConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	errs := c.Validate()
+	if len(errs) == 0 {
+		fmt.Printf("%s is valid\n", configPath)
+		return nil
+	}
+	for _, err := range errs {
+		fmt.Printf("- %s\n", err)
+	}
+	return fmt.Errorf("%s has %d invalid values", configPath, len(errs))
+}
@@ -0,0 +1,16 @@
+package registry
+
+import (
+	registrySign "github.com/arcana-network/dkgnode/cmd/registry/sign"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Command to manage the node registry file",
+	}
+
+	cmd.AddCommand(registrySign.GetCommand())
+	return cmd
+}
@@ -0,0 +1,92 @@
+package sign
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arcana-network/dkgnode/chain"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	registryFlag = "registry"
+	keyFlag      = "admin-key"
+	outFlag      = "out"
+)
+
+var registryPath string
+var adminKey string
+var outPath string
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Used to sign a node registry file with the registry admin key",
+		Run:   runCommand,
+	}
+
+	setFlags(cmd)
+
+	_ = cmd.MarkFlagRequired(registryFlag)
+	_ = cmd.MarkFlagRequired(keyFlag)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&registryPath,
+		registryFlag,
+		"",
+		"the path to the unsigned registry JSON",
+	)
+	cmd.Flags().StringVar(
+		&adminKey,
+		keyFlag,
+		"",
+		"the hex encoded private key of the registry admin",
+	)
+	cmd.Flags().StringVar(
+		&outPath,
+		outFlag,
+		"./nodeRegistry.json",
+		"the path the signed registry file is written to",
+	)
+}
+
+func runCommand(cmd *cobra.Command, args []string) {
+	registry, err := os.ReadFile(registryPath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	key, err := ethCrypto.HexToECDSA(strings.TrimPrefix(adminKey, "0x"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	signed, err := chain.SignRegistry(registry, key)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// Replace the file atomically so that nodes never read a partial registry.
+	tmpPath := outPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Fprintf(os.Stdout, "Signed registry written to %s, admin address %s\n", outPath, ethCrypto.PubkeyToAddress(key.PublicKey))
+}
@@ -1,6 +1,9 @@
 package root
 
 import (
+	"github.com/arcana-network/dkgnode/cmd/audit"
+	cmdConfig "github.com/arcana-network/dkgnode/cmd/config"
+	"github.com/arcana-network/dkgnode/cmd/registry"
 	"github.com/arcana-network/dkgnode/cmd/secret"
 	"github.com/arcana-network/dkgnode/cmd/start"
 	"github.com/arcana-network/dkgnode/cmd/version"
@@ -12,6 +15,9 @@ func GetRootCmd() *cobra.Command {
 	var rootCmd = &cobra.Command{}
 	rootCmd.AddCommand(start.GetCommand())
 	rootCmd.AddCommand(secret.GetCommand())
+	rootCmd.AddCommand(registry.GetCommand())
+	rootCmd.AddCommand(cmdConfig.GetCommand())
+	rootCmd.AddCommand(audit.GetCommand())
 	rootCmd.AddCommand(version.GetCommand())
 	return rootCmd
 }
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/arcana-network/dkgnode/secret/backend"
+	"github.com/spf13/cobra"
+)
+
+var configPath string
+var outPath string
+
+const (
+	configFlag = "secret-config"
+	outFlag    = "out"
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-public",
+		Short: "Used to export the public keys of the node as JSON",
+		Run:   runCommand,
+	}
+
+	setFlags(cmd)
+
+	_ = cmd.MarkFlagRequired(configFlag)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&configPath,
+		configFlag,
+		"",
+		"path to secret config file",
+	)
+	cmd.Flags().StringVar(
+		&outPath,
+		outFlag,
+		"",
+		"file to write the public keys to, stdout when empty",
+	)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	manager, err := backend.FromConfigFile(configPath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	data, err := secret.GetResult(manager)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if outPath == "" {
+		fmt.Fprintln(os.Stdout, string(out))
+		return
+	}
+	if err := os.WriteFile(outPath, out, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Printf("Public keys exported to %s\n", outPath)
+}
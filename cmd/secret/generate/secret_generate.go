@@ -13,25 +13,39 @@ const (
 	tokenFlag        = "token"
 	serverURLFlag    = "server-url"
 	namespaceFlag    = "namespace"
+	kindFlag         = "kind"
+	keystoreDirFlag  = "keystore-dir"
+	passwordFileFlag = "password-file"
+	kdfFlag          = "kdf"
+	authMethodFlag   = "auth-method"
+	roleIDFlag       = "role-id"
+	secretIDFileFlag = "secret-id-file"
+	k8sRoleFlag      = "kubernetes-role"
 )
 
 var secretConfig string
 var token string
 var serverURL string
 var namespace string
+var kind string
+var keystoreDir string
+var passwordFile string
+var kdf string
+var authMethod string
+var roleID string
+var secretIDFile string
+var kubernetesRole string
 
 func GetCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "generate",
-		Short: "Used to generate secret config",
-		Run:   runCommand,
+		Use:     "generate",
+		Short:   "Used to generate secret config",
+		PreRunE: preRunE,
+		Run:     runCommand,
 	}
 
 	setFlags(cmd)
 
-	_ = cmd.MarkFlagRequired(tokenFlag)
-	_ = cmd.MarkFlagRequired(serverURLFlag)
-
 	return cmd
 }
 
@@ -62,14 +76,99 @@ func setFlags(cmd *cobra.Command) {
 		"default",
 		"the namespace for the secret service",
 	)
+	cmd.Flags().StringVar(
+		&kind,
+		kindFlag,
+		secret.VaultKind,
+		fmt.Sprintf("the secret manager, %s or %s", secret.VaultKind, secret.KeystoreKind),
+	)
+	cmd.Flags().StringVar(
+		&keystoreDir,
+		keystoreDirFlag,
+		"./keystore",
+		"the directory of the local keystore",
+	)
+	cmd.Flags().StringVar(
+		&passwordFile,
+		passwordFileFlag,
+		"",
+		"the file holding the local keystore password",
+	)
+	cmd.Flags().StringVar(
+		&kdf,
+		kdfFlag,
+		"scrypt",
+		"the local keystore key derivation function, scrypt or argon2id",
+	)
+	cmd.Flags().StringVar(
+		&authMethod,
+		authMethodFlag,
+		"token",
+		"the vault auth method, token, approle or kubernetes",
+	)
+	cmd.Flags().StringVar(
+		&roleID,
+		roleIDFlag,
+		"",
+		"the vault approle role id",
+	)
+	cmd.Flags().StringVar(
+		&secretIDFile,
+		secretIDFileFlag,
+		"",
+		"the file holding the vault approle secret id",
+	)
+	cmd.Flags().StringVar(
+		&kubernetesRole,
+		k8sRoleFlag,
+		"",
+		"the vault role bound to the kubernetes service account",
+	)
+}
+
+func preRunE(cmd *cobra.Command, args []string) error {
+	switch kind {
+	case secret.VaultKind:
+		if serverURL == "" {
+			return fmt.Errorf("--%s is required for %s", serverURLFlag, secret.VaultKind)
+		}
+		switch authMethod {
+		case "token":
+			if token == "" {
+				return fmt.Errorf("--%s is required for token auth", tokenFlag)
+			}
+		case "approle":
+			if roleID == "" || secretIDFile == "" {
+				return fmt.Errorf("--%s and --%s are required for approle auth", roleIDFlag, secretIDFileFlag)
+			}
+		case "kubernetes":
+			if kubernetesRole == "" {
+				return fmt.Errorf("--%s is required for kubernetes auth", k8sRoleFlag)
+			}
+		default:
+			return fmt.Errorf("unknown vault auth method %q", authMethod)
+		}
+	case secret.KeystoreKind:
+	default:
+		return fmt.Errorf("unknown secret manager kind %q", kind)
+	}
+	return nil
 }
 
 func runCommand(cmd *cobra.Command, args []string) {
-	config := secret.SecretConfig{
-		Kind:      "hashicorp-vault",
-		Token:     token,
-		ServerURL: serverURL,
-		Namespace: namespace,
+	config := secret.SecretConfig{Kind: kind}
+	if kind == secret.KeystoreKind {
+		config.Path = keystoreDir
+		config.PasswordFile = passwordFile
+		config.KDF = kdf
+	} else {
+		config.Token = token
+		config.ServerURL = serverURL
+		config.Namespace = namespace
+		config.AuthMethod = authMethod
+		config.RoleID = roleID
+		config.SecretIDFile = secretIDFile
+		config.KubernetesRole = kubernetesRole
 	}
 
 	if err := config.WriteConfig(secretConfig); err != nil {
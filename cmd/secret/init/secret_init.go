@@ -6,7 +6,7 @@ import (
 	"os"
 
 	"github.com/arcana-network/dkgnode/secret"
-	"github.com/arcana-network/dkgnode/secret/vault"
+	"github.com/arcana-network/dkgnode/secret/backend"
 	"github.com/spf13/cobra"
 )
 
@@ -50,18 +50,7 @@ func preRunE(cmd *cobra.Command, args []string) error {
 
 func runCommand(cmd *cobra.Command, _ []string) {
 	// Init secret, get output
-	config, err := secret.ReadConfig(configPath)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	manager, err := vault.NewVaultManager(config)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	err = manager.Setup()
+	manager, err := backend.FromConfigFile(configPath)
 	if err != nil {
 		fmt.Println(err)
 		return
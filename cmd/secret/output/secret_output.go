@@ -5,7 +5,7 @@ import (
 	"os"
 
 	"github.com/arcana-network/dkgnode/secret"
-	"github.com/arcana-network/dkgnode/secret/vault"
+	"github.com/arcana-network/dkgnode/secret/backend"
 	"github.com/spf13/cobra"
 )
 
@@ -39,22 +39,11 @@ func setFlags(cmd *cobra.Command) {
 }
 
 func runCommand(cmd *cobra.Command, args []string) {
-	config, err := secret.ReadConfig(configPath)
+	manager, err := backend.FromConfigFile(configPath)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	manager, err := vault.NewVaultManager(config)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	err = manager.Setup()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
 	data, err := secret.GetResult(manager)
 	if err != nil {
 		fmt.Println(err)
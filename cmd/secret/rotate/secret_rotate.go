@@ -0,0 +1,90 @@
+package rotate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/arcana-network/dkgnode/secret/backend"
+	"github.com/spf13/cobra"
+)
+
+var configPath string
+var key string
+
+const (
+	configFlag = "secret-config"
+	keyFlag    = "key"
+
+	nodeKey       = "node"
+	tendermintKey = "tendermint"
+	allKeys       = "all"
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rotate",
+		Short:   "Used to replace the private keys of the node",
+		PreRunE: preRunE,
+		Run:     runCommand,
+	}
+
+	setFlags(cmd)
+
+	_ = cmd.MarkFlagRequired(configFlag)
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&configPath,
+		configFlag,
+		"",
+		"path to secret config file",
+	)
+	cmd.Flags().StringVar(
+		&key,
+		keyFlag,
+		allKeys,
+		"the key to replace: node, tendermint or all",
+	)
+}
+
+func preRunE(cmd *cobra.Command, args []string) error {
+	switch key {
+	case nodeKey, tendermintKey, allKeys:
+		return nil
+	}
+	return fmt.Errorf("unknown key %q", key)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	manager, err := backend.FromConfigFile(configPath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if key == nodeKey || key == allKeys {
+		if _, _, err := secret.InitNodeKey(manager); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("[Warning] the node address changed, share the new address with Arcana before restarting the node")
+	}
+	if key == tendermintKey || key == allKeys {
+		if _, err := secret.InitTendermintKey(manager); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	data, err := secret.GetResult(manager)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Fprintln(os.Stdout, data.GetOutput())
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"fmt"
 
+	secretExport "github.com/arcana-network/dkgnode/cmd/secret/export"
 	secretGenerate "github.com/arcana-network/dkgnode/cmd/secret/generate"
 	secretInit "github.com/arcana-network/dkgnode/cmd/secret/init"
 	secretOutput "github.com/arcana-network/dkgnode/cmd/secret/output"
+	secretRotate "github.com/arcana-network/dkgnode/cmd/secret/rotate"
 	"github.com/ryanuber/columnize"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +22,8 @@ func GetCommand() *cobra.Command {
 	cmd.AddCommand(secretInit.GetCommand())
 	cmd.AddCommand(secretGenerate.GetCommand())
 	cmd.AddCommand(secretOutput.GetCommand())
+	cmd.AddCommand(secretRotate.GetCommand())
+	cmd.AddCommand(secretExport.GetCommand())
 	return cmd
 }
 
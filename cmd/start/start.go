@@ -6,16 +6,19 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/arcana-network/dkgnode/common"
 	"github.com/arcana-network/dkgnode/config"
 	"github.com/arcana-network/dkgnode/node"
+	"github.com/arcana-network/dkgnode/secret"
 	"github.com/arcana-network/dkgnode/telemetry"
 )
 
 const (
 	configFileFlag         = "config"
 	blockchainRPCURLFlag   = "rpc-url"
+	blockchainRPCURLsFlag  = "rpc-urls"
 	secretConfigPathFlag   = "secret-config"
 	dkgContractAddressFlag = "contract-address"
 	gatewayURLFlag         = "gateway-url"
@@ -23,6 +26,22 @@ const (
 	serverPortFlag         = "server-port"
 	ipAddressFlag          = "ip-address"
 	domainFlag             = "domain"
+	shutdownTimeoutFlag    = "shutdown-timeout"
+	nodeRegistryFlag       = "node-registry"
+	nodeRegistryFileFlag   = "node-registry-file"
+	nodeRegistryAdminFlag  = "node-registry-admin"
+	signerFlag             = "signer"
+	signerURLFlag          = "signer-url"
+	signerTokenFileFlag    = "signer-token-file"
+	signerTransitKeyFlag   = "signer-transit-key"
+	tlsCertFlag            = "tls-cert"
+	tlsKeyFlag             = "tls-key"
+	tlsClientCAFlag        = "tls-client-ca"
+	adminAddressFlag       = "admin-address"
+	adminOperatorsFlag     = "admin-operators"
+	tracingEndpointFlag    = "tracing-endpoint"
+	verifiersFileFlag      = "verifiers-file"
+	cacheBackendFlag       = "cache-backend"
 
 	FlagMissingError = "required flag missing: %q"
 	ConfMissingError = "required config value missing: %q"
@@ -31,6 +50,10 @@ const (
 var cfgFilePath string
 var conf = config.GetDefaultConfig()
 
+// envErr is an invalid DKG_ environment variable found while setting the
+// flag defaults.
+var envErr error
+
 func GetCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "start",
@@ -56,73 +79,230 @@ func setConfigFileFlags(cmd *cobra.Command) {
 		&cfgFilePath,
 		configFileFlag,
 		"./config.json",
-		"Used to specify JSON config file path",
+		"Used to specify the JSON, YAML or TOML config file path",
 	)
 }
 
 func setParamsFlags(cmd *cobra.Command) {
 
+	// The DKG_ environment variables replace the defaults, flags take
+	// precedence over both.
 	d := config.GetDefaultConfig()
-	cmd.Flags().StringVar(
+	d.BasePath = "/tmp/keygen-data"
+	d.HttpServerPort = "80"
+	d.NodeRegistry = "contract"
+	envErr = config.ApplyEnv(d)
+	*conf = *d
+	bindParamsFlags(cmd.Flags(), conf)
+}
+
+// bindParamsFlags binds the node flags to the fields of conf, with their
+// current values as the defaults.
+func bindParamsFlags(flags *pflag.FlagSet, conf *config.Config) {
+	flags.StringVar(
 		&conf.SecretConfigPath,
 		secretConfigPathFlag,
-		"",
+		conf.SecretConfigPath,
 		"Used to specify the secret config path",
 	)
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.EthConnection,
 		blockchainRPCURLFlag,
-		d.EthConnection,
+		conf.EthConnection,
 		"Used to specify the blockchain url",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringSliceVar(
+		&conf.EthConnections,
+		blockchainRPCURLsFlag,
+		conf.EthConnections,
+		"Used to specify further blockchain urls to fail over to",
+	)
+
+	flags.StringVar(
 		&conf.ContractAddress,
 		dkgContractAddressFlag,
-		d.ContractAddress,
+		conf.ContractAddress,
 		"Used to specify the address of the DKG contract",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.GatewayURL,
 		gatewayURLFlag,
-		d.GatewayURL,
+		conf.GatewayURL,
 		"Used to specify the URL for Arcana Gateway",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.BasePath,
 		dataDirFlag,
-		"/tmp/keygen-data",
+		conf.BasePath,
 		"Used to specify the data directory used for storing DKG data. Default: '/tmp/keygen-data'",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.HttpServerPort,
 		serverPortFlag,
-		"80",
+		conf.HttpServerPort,
 		"Used to specify the server port. Default: '80'",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.IPAddress,
 		ipAddressFlag,
-		"",
+		conf.IPAddress,
 		"Used to specify the ip address of the node.",
 	)
 
-	cmd.Flags().StringVar(
+	flags.StringVar(
 		&conf.Domain,
 		domainFlag,
-		"",
+		conf.Domain,
 		"Used to specify the domain name of the current node",
 	)
 
+	flags.StringVar(
+		&conf.NodeRegistry,
+		nodeRegistryFlag,
+		conf.NodeRegistry,
+		"Used to specify where the node list is read from: 'contract' or 'file'",
+	)
+
+	flags.StringVar(
+		&conf.NodeRegistryFile,
+		nodeRegistryFileFlag,
+		conf.NodeRegistryFile,
+		"Used to specify the signed node registry file when the node registry is 'file'",
+	)
+
+	flags.StringVar(
+		&conf.NodeRegistryAdmin,
+		nodeRegistryAdminFlag,
+		conf.NodeRegistryAdmin,
+		"Used to specify the address that signs the node registry file",
+	)
+
+	flags.StringVar(
+		&conf.Signer,
+		signerFlag,
+		conf.Signer,
+		"Used to specify the node key signer: local, vault or remote. The raw node key is still needed with every signer",
+	)
+
+	flags.StringVar(
+		&conf.SignerURL,
+		signerURLFlag,
+		conf.SignerURL,
+		"Used to specify the url of the remote signer",
+	)
+
+	flags.StringVar(
+		&conf.SignerTokenFile,
+		signerTokenFileFlag,
+		conf.SignerTokenFile,
+		"Used to specify the file holding the secret the remote signer authenticates the node by, read from the secret manager when unset",
+	)
+
+	flags.StringVar(
+		&conf.SignerTransitKey,
+		signerTransitKeyFlag,
+		conf.SignerTransitKey,
+		"Used to specify the vault transit key of the vault signer",
+	)
+
+	flags.StringVar(
+		&conf.TLSCertFile,
+		tlsCertFlag,
+		conf.TLSCertFile,
+		"Used to specify the TLS certificate of the server, which enables TLS",
+	)
+
+	flags.StringVar(
+		&conf.TLSKeyFile,
+		tlsKeyFlag,
+		conf.TLSKeyFile,
+		"Used to specify the TLS private key of the server",
+	)
+
+	flags.StringVar(
+		&conf.TLSClientCAFile,
+		tlsClientCAFlag,
+		conf.TLSClientCAFile,
+		"Used to specify the CA that issues the node certificates required for ConnectionDetails",
+	)
+
+	flags.StringVar(
+		&conf.AdminListenAddress,
+		adminAddressFlag,
+		conf.AdminListenAddress,
+		"Used to specify the address the admin API listens on, which enables it",
+	)
+
+	flags.StringSliceVar(
+		&conf.AdminOperators,
+		adminOperatorsFlag,
+		conf.AdminOperators,
+		"Used to specify the addresses allowed to sign admin API requests",
+	)
+
+	flags.StringVar(
+		&conf.VerifiersFile,
+		verifiersFileFlag,
+		conf.VerifiersFile,
+		"Used to specify the file declaring further verifiers",
+	)
+
+	flags.StringVar(
+		&conf.CacheBackend,
+		cacheBackendFlag,
+		conf.CacheBackend,
+		"Used to specify where the cache is kept, memory or leveldb to keep it across restarts",
+	)
+
+	flags.StringVar(
+		&conf.TracingEndpoint,
+		tracingEndpointFlag,
+		conf.TracingEndpoint,
+		"Used to specify the host:port of the OTLP/HTTP collector keygen traces are exported to",
+	)
+
+	flags.IntVar(
+		&conf.ShutdownTimeout,
+		shutdownTimeoutFlag,
+		conf.ShutdownTimeout,
+		"Used to specify how long, in seconds, to wait for keygens and requests in flight on shutdown",
+	)
+}
+
+// flagOverrides returns a function that sets the flags given on the command
+// line on a config, over the values it read from the file and environment.
+func flagOverrides(given *pflag.FlagSet) func(c *config.Config) error {
+	return func(c *config.Config) error {
+		flags := pflag.NewFlagSet("start", pflag.ContinueOnError)
+		bindParamsFlags(flags, c)
+		var err error
+		given.Visit(func(f *pflag.Flag) {
+			target := flags.Lookup(f.Name)
+			if target == nil || err != nil {
+				return
+			}
+			if slice, ok := f.Value.(pflag.SliceValue); ok {
+				err = target.Value.(pflag.SliceValue).Replace(slice.GetSlice())
+				return
+			}
+			err = target.Value.Set(f.Value.String())
+		})
+		return err
+	}
 }
 
 func runCommand(cmd *cobra.Command, _ []string) error {
+	if envErr != nil {
+		return envErr
+	}
 	if common.DoesFileExist(cfgFilePath) {
-		c, err := config.ReadConfigJson(cfgFilePath)
+		config.SetFlagOverrides(flagOverrides(cmd.Flags()))
+		c, err := config.Load(cfgFilePath)
 		if err != nil {
 			log.Infof("Config file parsing error")
 			return err
@@ -153,6 +333,7 @@ func runCommand(cmd *cobra.Command, _ []string) error {
 			return err
 		}
 		conf.TMPrivateKey = tendermintKey
+		watchRotatedKeys(conf.SecretConfigPath)
 	} else {
 		pk, err := hex.DecodeString(conf.RawPrivateKey)
 		if err != nil {
@@ -174,5 +355,49 @@ func VerifyConfigFromFlags(conf *config.Config) error {
 	if conf.IPAddress == "" {
 		return fmt.Errorf(FlagMissingError, ipAddressFlag)
 	}
+	if conf.Signer == "remote" && conf.SignerURL == "" {
+		return fmt.Errorf(FlagMissingError, signerURLFlag)
+	}
+	if conf.Signer == "remote" && conf.SignerTokenFile == "" && conf.SecretConfigPath == "" {
+		return fmt.Errorf(FlagMissingError, signerTokenFileFlag)
+	}
+	if conf.Signer == "vault" && conf.SignerTransitKey == "" {
+		return fmt.Errorf(FlagMissingError, signerTransitKeyFlag)
+	}
+	if conf.TLSCertFile != "" && conf.TLSKeyFile == "" {
+		return fmt.Errorf(FlagMissingError, tlsKeyFlag)
+	}
+	if conf.TLSKeyFile != "" && conf.TLSCertFile == "" {
+		return fmt.Errorf(FlagMissingError, tlsCertFlag)
+	}
+	if conf.AdminListenAddress != "" && len(conf.AdminOperators) == 0 {
+		return fmt.Errorf(FlagMissingError, adminOperatorsFlag)
+	}
+	if conf.NodeRegistry == "file" {
+		if conf.NodeRegistryFile == "" {
+			return fmt.Errorf(FlagMissingError, nodeRegistryFileFlag)
+		}
+		if conf.NodeRegistryAdmin == "" {
+			return fmt.Errorf(FlagMissingError, nodeRegistryAdminFlag)
+		}
+	}
 	return nil
 }
+
+// watchRotatedKeys warns when the node keys are rotated in the secret
+// manager. Neither key can be swapped while the node runs: the node key is
+// registered in the node list and used by the keygens in progress, and the
+// Tendermint key is the validator key of the genesis. A new key only takes
+// effect on restart. The remote signer token is swapped by the chain service,
+// and TLS certificates are reread from disk.
+func watchRotatedKeys(secretConfigPath string) {
+	for _, name := range []string{secret.NodeKey, secret.TendermintKey} {
+		name := name
+		err := config.WatchSecret(secretConfigPath, name, func([]byte) {
+			log.WithField("secret", name).Warn("secret was rotated in the secret manager, restart the node to use it")
+		})
+		if err != nil {
+			log.WithError(err).WithField("secret", name).Warn("could not watch secret")
+		}
+	}
+}
@@ -168,6 +168,18 @@ func (store *ADKGSessionStore) Delete(r ADKGID) {
 	store.Map.Delete(r)
 }
 
+// Open returns the number of sessions that are not complete.
+func (store *ADKGSessionStore) Open() int {
+	open := 0
+	store.Map.Range(func(_, value interface{}) bool {
+		if session, _ := value.(*ADKGSession); session != nil {
+			open++
+		}
+		return true
+	})
+	return open
+}
+
 type SharingStoreMap struct {
 	Map sync.Map
 }
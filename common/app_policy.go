@@ -0,0 +1,135 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AppPolicy holds the rules an app sets on the release of its key shares. It
+// is set with a tx signed by the owner of the app and kept in the BFT state,
+// so every node applies the same rules.
+type AppPolicy struct {
+	AppID string `json:"app_id"`
+	// Version must grow with every update, so that an older policy cannot be
+	// set again.
+	Version uint64 `json:"version"`
+	// RequiredVerifiers must all check out in the share request.
+	RequiredVerifiers []string `json:"required_verifiers,omitempty"`
+	// AllowedOrigins are the origins share requests are accepted from, for
+	// example https://app.example.com. Any origin is accepted when empty.
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// LinkCooldown is the number of seconds after a verifier was linked to
+	// a key during which the share of the key is not released.
+	LinkCooldown int64 `json:"link_cooldown,omitempty"`
+	// Recovery lets users recover their keys through backup verifiers,
+	// after a delay. Keys cannot be recovered when nil.
+	Recovery *RecoveryPolicy `json:"recovery,omitempty"`
+}
+
+// PolicyRequest is what a share request is evaluated on.
+type PolicyRequest struct {
+	// Origin is the Origin header of the request, empty if it had none.
+	Origin string
+	// Verifiers are the verifiers that checked out in the request.
+	Verifiers []string
+	// AssignedAt is when the verifier was linked to the key, zero for keys
+	// assigned before it was recorded.
+	AssignedAt time.Time
+	// Recovery is set for requests through backup verifiers only, which the
+	// delay of the recovery guards instead of the required verifiers.
+	Recovery bool
+	Now      time.Time
+}
+
+// Validate checks that the policy is well formed.
+func (p *AppPolicy) Validate() error {
+	if p.AppID == "" {
+		return errors.New("policy has no app_id")
+	}
+	for _, verifier := range p.RequiredVerifiers {
+		if verifier == "" {
+			return errors.New("required verifier is empty")
+		}
+	}
+	for _, origin := range p.AllowedOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("allowed origin %q is not a scheme and host", origin)
+		}
+	}
+	if p.LinkCooldown < 0 {
+		return errors.New("link_cooldown must not be negative")
+	}
+	if p.Recovery != nil {
+		return p.Recovery.Validate()
+	}
+	return nil
+}
+
+// Evaluate returns why the policy denies a share request, nil if it allows
+// it.
+func (p *AppPolicy) Evaluate(r PolicyRequest) error {
+	for _, required := range p.RequiredVerifiers {
+		if !r.Recovery && !contains(r.Verifiers, required) {
+			return fmt.Errorf("verifier %s is required", required)
+		}
+	}
+	if len(p.AllowedOrigins) > 0 {
+		if r.Origin == "" {
+			return errors.New("request has no origin")
+		}
+		allowed := false
+		for _, origin := range p.AllowedOrigins {
+			if normalizeOrigin(origin) == normalizeOrigin(r.Origin) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("origin %s is not allowed", r.Origin)
+		}
+	}
+	if p.LinkCooldown > 0 && !r.AssignedAt.IsZero() {
+		if until := r.AssignedAt.Add(time.Duration(p.LinkCooldown) * time.Second); r.Now.Before(until) {
+			return fmt.Errorf("verifier was linked recently, shares are released from %s", until.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// AppPolicySigner recovers the address that signed a policy document as a
+// personal message.
+func AppPolicySigner(document, signature []byte) (ethCommon.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return ethCommon.Address{}, errors.New("invalid signature length")
+	}
+	sig := append([]byte(nil), signature...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(document), sig)
+	if err != nil {
+		return ethCommon.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(origin, "/"))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppPolicyEvaluate(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	policy := AppPolicy{
+		AppID:             "app",
+		Version:           1,
+		RequiredVerifiers: []string{"google", PasskeyVerifier},
+		AllowedOrigins:    []string{"https://app.example.com"},
+		LinkCooldown:      24 * 60 * 60,
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	allowed := PolicyRequest{
+		Origin:     "https://APP.example.com/",
+		Verifiers:  []string{"google", PasskeyVerifier},
+		AssignedAt: now.Add(-25 * time.Hour),
+		Now:        now,
+	}
+	if err := policy.Evaluate(allowed); err != nil {
+		t.Errorf("allowed request was denied: %v", err)
+	}
+
+	tests := map[string]func(r *PolicyRequest){
+		"missing verifier": func(r *PolicyRequest) { r.Verifiers = []string{"google"} },
+		"no origin":        func(r *PolicyRequest) { r.Origin = "" },
+		"other origin":     func(r *PolicyRequest) { r.Origin = "https://evil.example.com" },
+		"recently linked":  func(r *PolicyRequest) { r.AssignedAt = now.Add(-time.Hour) },
+	}
+	for name, modify := range tests {
+		r := allowed
+		modify(&r)
+		if err := policy.Evaluate(r); err == nil {
+			t.Errorf("%s: request was allowed", name)
+		}
+	}
+
+	recovery := allowed
+	recovery.Verifiers, recovery.Recovery = []string{"email"}, true
+	if err := policy.Evaluate(recovery); err != nil {
+		t.Errorf("recovery was denied for the required verifiers: %v", err)
+	}
+
+	var none AppPolicy
+	if err := none.Evaluate(PolicyRequest{Now: now}); err != nil {
+		t.Errorf("app without policy denied request: %v", err)
+	}
+}
+
+func TestAppPolicyValidate(t *testing.T) {
+	for name, policy := range map[string]AppPolicy{
+		"no app":            {},
+		"empty verifier":    {AppID: "app", RequiredVerifiers: []string{""}},
+		"origin with path":  {AppID: "app", AllowedOrigins: []string{"https://app.example.com/login"}},
+		"origin w/o scheme": {AppID: "app", AllowedOrigins: []string{"app.example.com"}},
+		"negative cooldown": {AppID: "app", LinkCooldown: -1},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("%s: invalid policy was accepted", name)
+		}
+	}
+}
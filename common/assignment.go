@@ -0,0 +1,35 @@
+package common
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// Tags of the Tendermint event of an assignment tx. The verifier id is only
+// tagged as a hash, so that event queries do not reveal it.
+const (
+	AssignmentEventType = "transfer"
+	AssignmentTag       = "assignment"
+	AssignmentKeyTag    = "assignment_key"
+	KeyIndexTag         = "key_index"
+	CurveTag            = "curve"
+)
+
+// KeyAssignedTopic is the bus topic assignment events are published on.
+const KeyAssignedTopic = "tendermint:key_assigned"
+
+// KeyAssignedEvent is published for every assignment tx in a block, also for
+// the ones that failed.
+type KeyAssignedEvent struct {
+	TxHash        string    `json:"tx_hash"`
+	AssignmentKey string    `json:"assignment_key"`
+	KeyIndex      string    `json:"key_index"`
+	Curve         CurveName `json:"curve"`
+	OK            bool      `json:"ok"`
+}
+
+// AssignmentKey identifies the key assignment of a verifier id in the
+// assignment events.
+func AssignmentKey(provider, userID, appID string, curve CurveName) string {
+	return hex.EncodeToString(Keccak256([]byte(strings.Join([]string{provider, userID, appID, string(curve)}, Delimiter1))))
+}
@@ -0,0 +1,19 @@
+package common
+
+// Outcomes of a share request, as recorded in the audit log.
+const (
+	ShareReleased = "released"
+	ShareRefused  = "refused"
+)
+
+// ShareRelease is an attempt to release the share of a key, as recorded in
+// the audit log. The verifier ID is only kept hashed.
+type ShareRelease struct {
+	KeyIndex        string `json:"key_index"`
+	AppID           string `json:"app_id"`
+	Verifier        string `json:"verifier"`
+	VerifierIDHash  string `json:"verifier_id_hash"`
+	TokenCommitment string `json:"token_commitment"`
+	Outcome         string `json:"outcome"`
+	Reason          string `json:"reason,omitempty"`
+}
@@ -28,6 +28,8 @@ type DKGMessage struct {
 	RoundID RoundID              `json:"round_id"`
 	Method  string               `json:"type"`
 	Data    []byte               `json:"data"`
+	// Trace carries the trace context of the sender for the session.
+	Trace map[string]string `json:"trace,omitempty"`
 }
 
 type NodeDetailsID string
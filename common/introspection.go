@@ -0,0 +1,44 @@
+package common
+
+import "time"
+
+// SessionStatus is the progress of an ADKG session this node takes part in,
+// as reported by the admin API.
+type SessionStatus struct {
+	ID        ADKGID    `json:"id"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// ACSSComplete is the number of dealers whose shares were received.
+	ACSSComplete int   `json:"acss_complete"`
+	TPrime       int   `json:"t_prime"`
+	ABAStarted   []int `json:"aba_started"`
+	ABAComplete  bool  `json:"aba_complete"`
+	// ABARounds is the current ABA round per dealer.
+	ABARounds            map[int]int `json:"aba_rounds"`
+	Decisions            map[int]int `json:"decisions"`
+	KeyderivationStarted bool        `json:"keyderivation_started"`
+	BFTDecided           bool        `json:"bft_decided"`
+	Over                 bool        `json:"over"`
+}
+
+// PeerStatus is a peer in the p2p address book.
+type PeerStatus struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"`
+	Connected bool     `json:"connected"`
+}
+
+// KeyBufferStatus is the fill of the key buffer of a curve.
+type KeyBufferStatus struct {
+	LastCreatedIndex    uint `json:"last_created_index"`
+	LastUnassignedIndex uint `json:"last_unassigned_index"`
+	Available           int  `json:"available"`
+	Target              int  `json:"target"`
+	// AssignmentRate is the smoothed number of keys assigned per block.
+	AssignmentRate float64 `json:"assignment_rate"`
+}
+
+// QueueStatus is the depth of the BFT message queue.
+type QueueStatus struct {
+	Queued  int `json:"queued"`
+	Pending int `json:"pending"`
+}
@@ -0,0 +1,33 @@
+package common
+
+import "github.com/arcana-network/dkgnode/webauthn"
+
+// PasskeyVerifier is the verifier apps register to let users sign in with a
+// passkey. Its domain is the WebAuthn relying party ID of the app.
+const PasskeyVerifier = "passkey"
+
+// PasskeyCredential is a WebAuthn credential registered for a user of an app.
+// The registry is kept in the BFT state, so every node knows every credential.
+type PasskeyCredential struct {
+	ID     string `json:"id"`
+	AppID  string `json:"app_id"`
+	UserID string `json:"user_id"`
+	// PublicKey is the SubjectPublicKeyInfo of the credential.
+	PublicKey []byte `json:"public_key"`
+	// Algorithm is the COSE algorithm of the credential.
+	Algorithm int `json:"algorithm"`
+	// SignCount is the highest signature counter seen, and SignCountToken
+	// the token commitment of the assertion that reported it.
+	SignCount      uint32 `json:"sign_count,omitempty"`
+	SignCountToken string `json:"sign_count_token,omitempty"`
+}
+
+// CheckSignCount checks the signature counter of an assertion over a token
+// commitment. Every node verifies the same assertion of a share request, so
+// the assertion that set the counter is accepted again.
+func (c PasskeyCredential) CheckSignCount(count uint32, tokenCommitment string) error {
+	if count != 0 && count == c.SignCount && tokenCommitment == c.SignCountToken {
+		return nil
+	}
+	return webauthn.CheckSignCount(c.SignCount, count)
+}
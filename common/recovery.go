@@ -0,0 +1,109 @@
+package common
+
+import (
+	"errors"
+	"time"
+)
+
+// RecoveryPolicy lets users recover their keys through backup verifiers. A
+// share request through backup verifiers only starts a recovery of the key,
+// and its share is released once Delay passed without the primary verifiers
+// of the user cancelling the recovery.
+type RecoveryPolicy struct {
+	BackupVerifiers []string `json:"backup_verifiers"`
+	// Delay is the number of seconds a recovery can be cancelled in before
+	// the share is released.
+	Delay int64 `json:"delay"`
+}
+
+// Validate checks that the recovery policy is well formed.
+func (p *RecoveryPolicy) Validate() error {
+	if len(p.BackupVerifiers) == 0 {
+		return errors.New("recovery has no backup verifiers")
+	}
+	for _, verifier := range p.BackupVerifiers {
+		if verifier == "" {
+			return errors.New("backup verifier is empty")
+		}
+	}
+	if p.Delay <= 0 {
+		return errors.New("recovery delay must be positive")
+	}
+	return nil
+}
+
+// IsBackup reports whether a verifier is a backup verifier.
+func (p *RecoveryPolicy) IsBackup(verifier string) bool {
+	return contains(p.BackupVerifiers, verifier)
+}
+
+// OnlyBackups reports whether a request that checked out with the verifiers
+// is a recovery, made through backup verifiers only.
+func (p *RecoveryPolicy) OnlyBackups(verifiers []string) bool {
+	for _, verifier := range verifiers {
+		if !p.IsBackup(verifier) {
+			return false
+		}
+	}
+	return len(verifiers) > 0
+}
+
+// RecoveryState is the state of the recovery of a key.
+type RecoveryState string
+
+const (
+	// RecoveryNone is the state of keys that have no recovery.
+	RecoveryNone RecoveryState = ""
+	// RecoveryPending recoveries can be cancelled, and their share is not
+	// released yet.
+	RecoveryPending RecoveryState = "pending"
+	// RecoveryCancelled recoveries were cancelled by a primary verifier.
+	RecoveryCancelled RecoveryState = "cancelled"
+	// RecoveryReleasable recoveries passed their delay, the share is
+	// released through the backup verifiers.
+	RecoveryReleasable RecoveryState = "releasable"
+	// RecoveryExpired recoveries were releasable for RecoveryReleaseWindow.
+	// A share request through backup verifiers starts a new recovery.
+	RecoveryExpired RecoveryState = "expired"
+)
+
+// RecoveryReleaseWindow is how long the share of a recovery is released for
+// once its delay passed, so that a later recovery waits out the delay again.
+const RecoveryReleaseWindow = 24 * time.Hour
+
+// Recovery is the latest recovery of a key. It is kept in the BFT state, so
+// every node knows whether the share may be released.
+type Recovery struct {
+	AppID    string    `json:"app_id"`
+	KeyIndex string    `json:"key_index"`
+	Curve    CurveName `json:"curve"`
+	// Verifier is the backup verifier the recovery was requested through.
+	Verifier     string    `json:"verifier"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ReleasableAt time.Time `json:"releasable_at"`
+	Cancelled    bool      `json:"cancelled"`
+	// CancelledBy is the primary verifier the recovery was cancelled by.
+	CancelledBy string `json:"cancelled_by,omitempty"`
+}
+
+// State returns the state of the recovery at a time.
+func (r *Recovery) State(now time.Time) RecoveryState {
+	switch {
+	case r.RequestedAt.IsZero():
+		return RecoveryNone
+	case r.Cancelled:
+		return RecoveryCancelled
+	case now.Before(r.ReleasableAt):
+		return RecoveryPending
+	case now.Before(r.ReleasableAt.Add(RecoveryReleaseWindow)):
+		return RecoveryReleasable
+	}
+	return RecoveryExpired
+}
+
+// Started reports whether the recovery is pending or releasable, and a new
+// one cannot be started.
+func (r *Recovery) Started(now time.Time) bool {
+	state := r.State(now)
+	return state == RecoveryPending || state == RecoveryReleasable
+}
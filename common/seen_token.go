@@ -0,0 +1,21 @@
+package common
+
+import "time"
+
+// SeenToken is the use of a token in a commitment flow, shared by all nodes
+// through the BFT state. The zero value is a token that was not used.
+type SeenToken struct {
+	// TempPubKey is the key of the flow the token was first used in.
+	TempPubKey Point     `json:"temp_pub_key"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (s SeenToken) Seen() bool {
+	return !s.ExpiresAt.IsZero()
+}
+
+// SameFlow reports whether a flow encrypting to tempPubKey is the one the
+// token was used in.
+func (s SeenToken) SameFlow(tempPubKey Point) bool {
+	return s.TempPubKey.X.Cmp(&tempPubKey.X) == 0 && s.TempPubKey.Y.Cmp(&tempPubKey.Y) == 0
+}
@@ -1,7 +1,7 @@
 package common
 
 import (
-	"crypto/rand"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -9,6 +9,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/arcana-network/dkgnode/eventbus"
 	"github.com/avast/retry-go"
@@ -22,13 +23,12 @@ import (
 
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/libs/bytes"
-
-	"github.com/arcana-network/dkgnode/secp256k1"
 )
 
 type MessageBroker struct {
 	bus    eventbus.Bus
 	caller string
+	ctx    context.Context
 }
 
 type KeygenNodeDetails struct {
@@ -93,85 +93,94 @@ func NewServiceBroker(bus eventbus.Bus, caller string) *MessageBroker {
 	return &MessageBroker{
 		bus:    bus,
 		caller: caller,
+		ctx:    context.Background(),
 	}
 }
 
-func (broker *MessageBroker) ChainMethods() *ChainMethods {
-	return &ChainMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: CHAIN_SERVICE_NAME,
+// WithContext returns a broker whose calls give up when ctx is done.
+func (broker *MessageBroker) WithContext(ctx context.Context) *MessageBroker {
+	return &MessageBroker{
+		bus:    broker.bus,
+		caller: broker.caller,
+		ctx:    ctx,
 	}
 }
-func (broker *MessageBroker) ServerMethods() *ServerMethods {
-	return &ServerMethods{
+
+func (broker *MessageBroker) methodCaller(service string) methodCaller {
+	return methodCaller{
 		bus:     broker.bus,
 		caller:  broker.caller,
-		service: SERVER_SERVICE_NAME,
+		service: service,
+		ctx:     broker.ctx,
 	}
 }
 
+func (broker *MessageBroker) ChainMethods() *ChainMethods {
+	return &ChainMethods{broker.methodCaller(CHAIN_SERVICE_NAME)}
+}
+func (broker *MessageBroker) ServerMethods() *ServerMethods {
+	return &ServerMethods{broker.methodCaller(SERVER_SERVICE_NAME)}
+}
+
 func (broker *MessageBroker) DBMethods() *DBMethods {
-	return &DBMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: DB_SERVICE_NAME,
-	}
+	return &DBMethods{broker.methodCaller(DB_SERVICE_NAME)}
 }
 func (broker *MessageBroker) KeygenMethods() *KeygenMethods {
-	return &KeygenMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: KEYGEN_SERVICE_NAME,
-	}
+	return &KeygenMethods{broker.methodCaller(KEYGEN_SERVICE_NAME)}
 }
 
 func (broker *MessageBroker) TendermintMethods() *TendermintMethods {
-	return &TendermintMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: TENDERMINT_SERVICE_NAME,
-	}
+	return &TendermintMethods{broker.methodCaller(TENDERMINT_SERVICE_NAME)}
 }
 func (broker *MessageBroker) ABCIMethods() *ABCIMethods {
-	return &ABCIMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: ABCI_SERVICE_NAME,
-	}
+	return &ABCIMethods{broker.methodCaller(ABCI_SERVICE_NAME)}
 }
 
 func (broker *MessageBroker) CacheMethods() *CacheMethods {
-	return &CacheMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: CACHE_SERVICE_NAME,
-	}
+	return &CacheMethods{broker.methodCaller(CACHE_SERVICE_NAME)}
 }
 
 func (broker *MessageBroker) VerifierMethods() *VerifierMethods {
-	return &VerifierMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: VERIFIER_SERVICE_NAME,
-	}
+	return &VerifierMethods{broker.methodCaller(VERIFIER_SERVICE_NAME)}
 }
 func (broker *MessageBroker) KeystoreMethods() *KeystoreMethods {
-	return &KeystoreMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: KEYSTORE_SERVICE_NAME,
-	}
+	return &KeystoreMethods{broker.methodCaller(KEYSTORE_SERVICE_NAME)}
+}
+
+func (broker *MessageBroker) AuditMethods() *AuditMethods {
+	return &AuditMethods{broker.methodCaller(AUDIT_SERVICE_NAME)}
+}
+
+func (broker *MessageBroker) RegistryMethods() *RegistryMethods {
+	return &RegistryMethods{broker.methodCaller(REGISTRY_SERVICE_NAME)}
+}
+
+type RegistryMethods struct {
+	methodCaller
+}
+
+func (rm *RegistryMethods) ServiceStates() (states map[string]ServiceStatus, err error) {
+	states, err = request[map[string]ServiceStatus](rm.methodCaller, "service_states")
+	return
+}
+
+type AuditMethods struct {
+	methodCaller
+}
+
+// RecordShareRelease appends a share request to the audit log. A share must
+// not be released if this fails.
+func (am *AuditMethods) RecordShareRelease(release ShareRelease) error {
+	methodResponse := am.call("record_share_release", release)
+	return methodResponse.Error
 }
 
 type KeystoreMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 func (ksm *KeystoreMethods) StoreShare(id string, share []byte) (err error) {
-	methodResponse := ServiceMethod(ksm.bus, ksm.caller, ksm.service, "store", id, share)
+	methodResponse := ksm.call("store", id, share)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -182,47 +191,25 @@ func (ksm *KeystoreMethods) StoreShare(id string, share []byte) (err error) {
 }
 
 func (ksm *KeystoreMethods) RetrieveShare(id string) (share []byte, err error) {
-	methodResponse := ServiceMethod(ksm.bus, ksm.caller, ksm.service, "retrieve", id)
-	if methodResponse.Error != nil {
-		return share, methodResponse.Error
-	}
-	var data []byte
-	err = CastOrUnmarshal(methodResponse.Data, &data)
-	if err != nil {
-		return data, err
-	}
-	share = data
+	share, err = request[[]byte](ksm.methodCaller, "retrieve", id)
 	return
 }
 
 type ServerMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 func (sm *ServerMethods) RequestConnectionDetails(endpoint string) (connectionDetails ConnectionDetails, err error) {
-	methodResponse := ServiceMethod(sm.bus, sm.caller, sm.service, "request_connection_details", endpoint)
-	if methodResponse.Error != nil {
-		return connectionDetails, methodResponse.Error
-	}
-	var data ConnectionDetails
-	err = CastOrUnmarshal(methodResponse.Data, &data)
-	if err != nil {
-		return connectionDetails, err
-	}
-	connectionDetails = data
+	connectionDetails, err = request[ConnectionDetails](sm.methodCaller, "request_connection_details", endpoint)
 	return
 }
 
 type VerifierMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 func (vm *VerifierMethods) Verify(rawMessage *bijson.RawMessage) (valid bool, userID string, err error) {
-	methodResponse := ServiceMethod(vm.bus, vm.caller, vm.service, "verify", rawMessage)
+	methodResponse := vm.call("verify", rawMessage)
 	if methodResponse.Error != nil {
 		err = methodResponse.Error
 		return
@@ -241,7 +228,7 @@ func (vm *VerifierMethods) Verify(rawMessage *bijson.RawMessage) (valid bool, us
 }
 
 func (vm *VerifierMethods) CleanToken(verifierIdentifier string, idtoken string) (cleanedToken string, err error) {
-	methodResponse := ServiceMethod(vm.bus, vm.caller, vm.service, "clean_token", verifierIdentifier, idtoken)
+	methodResponse := vm.call("clean_token", verifierIdentifier, idtoken)
 	if methodResponse.Error != nil {
 		err = methodResponse.Error
 		return
@@ -256,93 +243,100 @@ func (vm *VerifierMethods) CleanToken(verifierIdentifier string, idtoken string)
 }
 
 type ABCIMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 type KeyAssignmentPublic struct {
 	Index     big.Int
 	PublicKey Point
 	Verifiers map[string][]string // Verifier => VerifierID
+	// AssignedAt is the time of the block the verifier was linked to the key
+	// in, zero for keys assigned before it was recorded.
+	AssignedAt time.Time
 }
 
 func (am *ABCIMethods) RetrieveKeyMapping(keyIndex big.Int, curve CurveName) (keyDetails KeyAssignmentPublic, err error) {
-	methodResponse := ServiceMethod(am.bus, am.caller, am.service, "retrieve_key_mapping", keyIndex, curve)
-	if methodResponse.Error != nil {
-		return keyDetails, methodResponse.Error
-	}
-	var data KeyAssignmentPublic
-	err = CastOrUnmarshal(methodResponse.Data, &data)
-	if err != nil {
-		return keyDetails, err
-	}
-	keyDetails = data
+	keyDetails, err = request[KeyAssignmentPublic](am.methodCaller, "retrieve_key_mapping", keyIndex, curve)
+	return
+}
+
+func (am *ABCIMethods) KeyBufferStatus() (status map[CurveName]KeyBufferStatus, err error) {
+	status, err = request[map[CurveName]KeyBufferStatus](am.methodCaller, "key_buffer_status")
 	return
 }
 
 func (am *ABCIMethods) GetIndexesFromVerifierID(verifier, verifierID, appID string, curve CurveName) (keyIndexes []big.Int, err error) {
-	methodResponse := ServiceMethod(am.bus, am.caller, am.service, "get_indexes_from_verifier_id", verifier, verifierID, appID, curve)
-	if methodResponse.Error != nil {
-		return keyIndexes, methodResponse.Error
-	}
-	var data []big.Int
-	err = CastOrUnmarshal(methodResponse.Data, &data)
-	if err != nil {
-		return keyIndexes, err
-	}
-	keyIndexes = data
+	keyIndexes, err = request[[]big.Int](am.methodCaller, "get_indexes_from_verifier_id", verifier, verifierID, appID, curve)
+	return
+}
+
+func (am *ABCIMethods) RetrievePasskeyCredential(appID, credentialID string) (credential PasskeyCredential, err error) {
+	credential, err = request[PasskeyCredential](am.methodCaller, "retrieve_passkey_credential", appID, credentialID)
+	return
+}
+
+// RetrieveAppPolicy returns the policy of an app, the zero value if the app
+// has none.
+func (am *ABCIMethods) RetrieveAppPolicy(appID string) (policy AppPolicy, err error) {
+	policy, err = request[AppPolicy](am.methodCaller, "retrieve_app_policy", appID)
+	return
+}
+
+// RetrieveRecovery returns the latest recovery of a key, the zero value if
+// the key has none.
+func (am *ABCIMethods) RetrieveRecovery(keyIndex big.Int, curve CurveName) (recovery Recovery, err error) {
+	recovery, err = request[Recovery](am.methodCaller, "retrieve_recovery", keyIndex, curve)
+	return
+}
+
+// LastBlockTime returns the time of the last committed block, which the
+// times recorded by the ABCI app are compared against.
+func (am *ABCIMethods) LastBlockTime() (blockTime time.Time, err error) {
+	blockTime, err = request[time.Time](am.methodCaller, "last_block_time")
+	return
+}
+
+// SeenToken returns the use of a token, the zero value if it was not used.
+func (am *ABCIMethods) SeenToken(tokenCommitment string) (seen SeenToken, err error) {
+	seen, err = request[SeenToken](am.methodCaller, "retrieve_seen_token", tokenCommitment)
 	return
 }
 
 type ChainMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
-func (cm *ChainMethods) GetTMP2PConnection() (tmp2pconnection string) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_tm_p2p_connection")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data string
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cm *ChainMethods) GetTMP2PConnection() (tmp2pconnection string, err error) {
+	err = retry.Do(func() error {
+		data, err := request[string](cm.methodCaller, "get_tm_p2p_connection")
 		if err != nil {
 			return err
 		}
 		tmp2pconnection = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node details by epoch and index")
+		return "", cm.fatal(err, "could not get node details by epoch and index")
 	}
 	return
 }
-func (cm *ChainMethods) GetP2PConnection() (p2pconnection string) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_p2p_connection")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data string
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cm *ChainMethods) GetP2PConnection() (p2pconnection string, err error) {
+	err = retry.Do(func() error {
+		data, err := request[string](cm.methodCaller, "get_p2p_connection")
 		if err != nil {
 			return err
 		}
 		p2pconnection = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node details by epoch and index")
+		return "", cm.fatal(err, "could not get node details by epoch and index")
 	}
 	return
 }
 
 func (cm *ChainMethods) ValidateEpochPubKey(nodeAddress ethCommon.Address, pubK Point) (valid bool) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service,
-		"validate_epoch_pub_key", nodeAddress, pubK)
+	methodResponse := cm.call("validate_epoch_pub_key", nodeAddress, pubK)
 
 	if methodResponse.Error != nil {
 		return false
@@ -357,33 +351,28 @@ func (cm *ChainMethods) ValidateEpochPubKey(nodeAddress ethCommon.Address, pubK
 
 func (cm *ChainMethods) GetSelfAddress() (address ethCommon.Address) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_self_address")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data ethCommon.Address
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[ethCommon.Address](cm.methodCaller, "get_self_address")
 		if err != nil {
 			return err
 		}
 		address = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get self address")
+		cm.fatal(err, "could not get self address")
 	}
 	return
 }
 
 func (cm *ChainMethods) AwaitNodesConnected(epoch int) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "await_nodes_connected", epoch)
+	methodResponse := cm.call("await_nodes_connected", epoch)
 	if methodResponse.Error != nil {
-		log.WithError(methodResponse.Error).Fatal("await nodes connected returned error")
+		cm.fatal(methodResponse.Error, "await nodes connected returned error")
 	}
 }
 
 func (cm *ChainMethods) KeyBuffer() int {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_key_buffer")
+	methodResponse := cm.call("get_key_buffer")
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Info("could not get key buffer")
 	}
@@ -397,19 +386,19 @@ func (cm *ChainMethods) KeyBuffer() int {
 
 func (cm *ChainMethods) SetSelfIndex(index int) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "set_self_index", index)
+		methodResponse := cm.call("set_self_index", index)
 		if methodResponse.Error != nil {
 			return methodResponse.Error
 		}
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not set self index")
+		cm.fatal(err, "could not set self index")
 	}
 }
 
 func (cm *ChainMethods) GetPreviousEpoch() (epoch int, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_previous_epoch", nil)
+	methodResponse := cm.call("get_previous_epoch", nil)
 	if methodResponse.Error != nil {
 		return 0, methodResponse.Error
 	}
@@ -423,7 +412,7 @@ func (cm *ChainMethods) GetPreviousEpoch() (epoch int, err error) {
 	return
 }
 func (cm *ChainMethods) GetNextEpoch() (epoch int, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_next_epoch")
+	methodResponse := cm.call("get_next_epoch")
 	if methodResponse.Error != nil {
 		return 0, methodResponse.Error
 	}
@@ -437,15 +426,9 @@ func (cm *ChainMethods) GetNextEpoch() (epoch int, err error) {
 	return
 }
 
-func (cm *ChainMethods) GetCurrentEpoch() (epoch int) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_current_epoch")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data int
-		log.WithField("GetCurentEpoch", methodResponse.Data).Debug("ServiceMapper")
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cm *ChainMethods) GetCurrentEpoch() (epoch int, err error) {
+	err = retry.Do(func() error {
+		data, err := request[int](cm.methodCaller, "get_current_epoch")
 		if err != nil {
 			return err
 		}
@@ -454,15 +437,21 @@ func (cm *ChainMethods) GetCurrentEpoch() (epoch int) {
 		}
 		epoch = data
 		return nil
-	})
-	if err != nil || epoch == 0 {
-		log.WithError(err).Fatal("could not get current epoch")
+	}, retry.Context(cm.context()))
+	if err != nil {
+		return 0, cm.fatal(err, "could not get current epoch")
 	}
 	return
 }
 
+// RefreshEpoch drops the cached epoch info and reads the current epoch again.
+func (cm *ChainMethods) RefreshEpoch() (eInfo EpochInfo, err error) {
+	eInfo, err = request[EpochInfo](cm.methodCaller, "refresh_epoch")
+	return
+}
+
 func (cm *ChainMethods) GetEpochInfo(epoch int, skipCache bool) (eInfo EpochInfo, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_epoch_info", epoch, skipCache)
+	methodResponse := cm.call("get_epoch_info", epoch, skipCache)
 	if methodResponse.Error != nil {
 		return eInfo, methodResponse.Error
 	}
@@ -482,47 +471,37 @@ func (cm *ChainMethods) GetEpochInfo(epoch int, skipCache bool) (eInfo EpochInfo
 
 func (cm *ChainMethods) GetNodeDetailsByEpochAndIndex(epoch int, index int) (nodeRef NodeReference) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_node_details_by_epoch_and_index", epoch, index)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data SerializedNodeReference
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[SerializedNodeReference](cm.methodCaller, "get_node_details_by_epoch_and_index", epoch, index)
 		if err != nil {
 			return err
 		}
 		nodeRef = NodeReference{}.Deserialize(data)
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node details by epoch and index")
+		cm.fatal(err, "could not get node details by epoch and index")
 	}
 	return
 }
 
 func (cm *ChainMethods) GetNodeDetailsByAddress(address ethCommon.Address) (nodeRef NodeReference) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_node_details_by_address", address)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data SerializedNodeReference
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[SerializedNodeReference](cm.methodCaller, "get_node_details_by_address", address)
 		if err != nil {
 			return err
 		}
 		nodeRef = NodeReference{}.Deserialize(data)
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node details by address")
+		cm.fatal(err, "could not get node details by address")
 	}
 	return
 }
 func (cm *ChainMethods) GetSelfIndex() (index int) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_self_index")
+	methodResponse := cm.call("get_self_index")
 	if methodResponse.Error != nil {
-		log.Fatalf("Get self index returned error, should be blocking until success")
+		cm.fatal(methodResponse.Error, "Get self index returned error, should be blocking until success")
 	}
 	var data int
 	err := CastOrUnmarshal(methodResponse.Data, &data)
@@ -532,47 +511,37 @@ func (cm *ChainMethods) GetSelfIndex() (index int) {
 	index = data
 	return
 }
-func (cm *ChainMethods) GetSelfPublicKey() (pubKey Point) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_self_public_key")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data Point
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cm *ChainMethods) GetSelfPublicKey() (pubKey Point, err error) {
+	err = retry.Do(func() error {
+		data, err := request[Point](cm.methodCaller, "get_self_public_key")
 		if err != nil {
 			log.Error(err)
 			return err
 		}
 		pubKey = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get self public key")
+		return Point{}, cm.fatal(err, "could not get self public key")
 	}
 	return
 }
 func (cm *ChainMethods) GetSelfPrivateKey() (privKey big.Int) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_self_private_key")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data big.Int
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[big.Int](cm.methodCaller, "get_self_private_key")
 		if err != nil {
 			return err
 		}
 		privKey = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get self private key")
+		cm.fatal(err, "could not get self private key")
 	}
 	return
 }
 func (cm *ChainMethods) VerifyDataWithNodelist(pk Point, sig []byte, input []byte) (senderDetails KeygenNodeDetails, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "verify_data_with_nodelist", pk, sig, input)
+	methodResponse := cm.call("verify_data_with_nodelist", pk, sig, input)
 	var data KeygenNodeDetails
 	err = CastOrUnmarshal(methodResponse.Data, &data)
 	if err != nil {
@@ -583,26 +552,21 @@ func (cm *ChainMethods) VerifyDataWithNodelist(pk Point, sig []byte, input []byt
 
 func (cm *ChainMethods) Connect() error {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "connect_to_nodes")
+		methodResponse := cm.call("connect_to_nodes")
 		if methodResponse.Error != nil {
 			return methodResponse.Error
 		}
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node list")
+		cm.fatal(err, "could not get node list")
 		return err
 	}
 	return nil
 }
 func (cm *ChainMethods) GetNodeList(epoch int) (nodeRefs []NodeReference) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_node_list", epoch)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data []SerializedNodeReference
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[[]SerializedNodeReference](cm.methodCaller, "get_node_list", epoch)
 		if err != nil {
 			return err
 		}
@@ -612,15 +576,15 @@ func (cm *ChainMethods) GetNodeList(epoch int) (nodeRefs []NodeReference) {
 		}
 		nodeRefs = deserializedData
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get node list")
+		cm.fatal(err, "could not get node list")
 	}
 	return
 }
 
 func (cm *ChainMethods) VerifyDataWithEpoch(pk Point, sig []byte, input []byte, epoch int) (senderDetails KeygenNodeDetails, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "verify_data_with_epoch", pk, sig, input, epoch)
+	methodResponse := cm.call("verify_data_with_epoch", pk, sig, input, epoch)
 	var data KeygenNodeDetails
 	err = CastOrUnmarshal(methodResponse.Data, &data)
 	if err != nil {
@@ -629,7 +593,7 @@ func (cm *ChainMethods) VerifyDataWithEpoch(pk Point, sig []byte, input []byte,
 	return data, nil
 }
 func (cm *ChainMethods) GetClientIDViaVerifier(appID, verifier string) (*VerifierParams, error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_params_by_verifier", appID, verifier)
+	methodResponse := cm.call("get_params_by_verifier", appID, verifier)
 	var params VerifierParams
 	err := CastOrUnmarshal(methodResponse.Data, &params)
 	if err != nil {
@@ -639,7 +603,7 @@ func (cm *ChainMethods) GetClientIDViaVerifier(appID, verifier string) (*Verifie
 }
 
 func (cm *ChainMethods) GetPartitionForApp(appID string) (partitioned bool, err error) {
-	methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "get_app_partition", appID)
+	methodResponse := cm.call("get_app_partition", appID)
 	err = CastOrUnmarshal(methodResponse.Data, &partitioned)
 	if err != nil {
 		return
@@ -647,14 +611,9 @@ func (cm *ChainMethods) GetPartitionForApp(appID string) (partitioned bool, err
 	return
 }
 
-func (cm *ChainMethods) AwaitCompleteNodeList(epoch int) (nodeRefs []NodeReference) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "await_complete_node_list", epoch)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data []SerializedNodeReference
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cm *ChainMethods) AwaitCompleteNodeList(epoch int) (nodeRefs []NodeReference, err error) {
+	err = retry.Do(func() error {
+		data, err := request[[]SerializedNodeReference](cm.methodCaller, "await_complete_node_list", epoch)
 		if err != nil {
 			return err
 		}
@@ -664,29 +623,49 @@ func (cm *ChainMethods) AwaitCompleteNodeList(epoch int) (nodeRefs []NodeReferen
 		}
 		nodeRefs = deserializedData
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get complete node list")
+		return nil, cm.fatal(err, "could not get complete node list")
 	}
 	return
 }
 
-func (cm *ChainMethods) SelfSignData(input []byte) (rawSig []byte) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cm.bus, cm.caller, cm.service, "self_sign_data", input)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data []byte
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+// ChainID returns the ID of the chain the node is connected to.
+func (cm *ChainMethods) ChainID() (*big.Int, error) {
+	chainID, err := request[big.Int](cm.methodCaller, "get_chain_id")
+	if err != nil {
+		return nil, err
+	}
+	return &chainID, nil
+}
+
+// CallContract runs a read only contract call on the chain.
+func (cm *ChainMethods) CallContract(to ethCommon.Address, data []byte) ([]byte, error) {
+	return request[[]byte](cm.methodCaller, "call_contract", to, data)
+}
+
+// SignHash signs a 32 byte digest with the node identity key.
+// AppOwner returns the owner of the contract of an app.
+func (cm *ChainMethods) AppOwner(appID string) (owner ethCommon.Address, err error) {
+	owner, err = request[ethCommon.Address](cm.methodCaller, "get_app_owner", appID)
+	return
+}
+
+func (cm *ChainMethods) SignHash(hash []byte) (rawSig []byte, err error) {
+	return request[[]byte](cm.methodCaller, "sign_hash", hash)
+}
+
+func (cm *ChainMethods) SelfSignData(input []byte) (rawSig []byte, err error) {
+	err = retry.Do(func() error {
+		data, err := request[[]byte](cm.methodCaller, "self_sign_data", input)
 		if err != nil {
 			return err
 		}
 		rawSig = data
 		return nil
-	})
+	}, retry.Context(cm.context()))
 	if err != nil {
-		log.Fatalf("Could not set self sign data, %v", err.Error())
+		return nil, cm.fatal(err, "Could not set self sign data")
 	}
 	return
 }
@@ -697,6 +676,9 @@ type MethodRequest struct {
 	Method  string
 	ID      string
 	Data    []interface{}
+	// Context is the context of the caller, nil for requests that do not
+	// carry one.
+	Context context.Context
 }
 
 type MethodResponse struct {
@@ -705,33 +687,10 @@ type MethodResponse struct {
 	Data    interface{}
 }
 
+// ServiceMethod calls a method on a service and waits for its response
+// without a deadline. Prefer ServiceMethodContext when the caller can give up.
 func ServiceMethod(eventBus eventbus.Bus, caller string, service string, method string, data ...interface{}) MethodResponse {
-	nonce, err := rand.Int(rand.Reader, secp256k1.GeneratorOrder)
-	if err != nil {
-		return MethodResponse{
-			Error: errors.New("could not generate random nonce"),
-			Data:  nil,
-		}
-	}
-	nonceStr := nonce.Text(16)
-	nonce = nil
-	responseCh := AwaitTopic(eventBus, nonceStr)
-	eventBus.Publish("method", MethodRequest{
-		Caller:  caller,
-		Service: service,
-		Method:  method,
-		ID:      nonceStr,
-		Data:    data,
-	})
-	methodResponseInter := <-responseCh
-	methodResponse, ok := methodResponseInter.(MethodResponse)
-	if !ok {
-		return MethodResponse{
-			Error: errors.New("method response was not of MethodResponse type"),
-			Data:  nil,
-		}
-	}
-	return methodResponse
+	return ServiceMethodContext(context.Background(), eventBus, caller, service, method, data...)
 }
 
 func CastOrUnmarshal(dataInter interface{}, v interface{}, flags ...bool) (err error) {
@@ -772,35 +731,48 @@ func CastOrUnmarshal(dataInter interface{}, v interface{}, flags ...bool) (err e
 	return
 }
 
-func AwaitTopic(eventBus eventbus.Bus, topic string) <-chan interface{} {
-	responseCh := make(chan interface{})
-	err := eventBus.SubscribeOnceAsync(topic, func(res interface{}) {
+// AwaitTopic returns a channel that receives the first event published on
+// topic. If ctx is done before that, the subscription is dropped so that
+// abandoned waits do not leave handlers behind on the bus.
+func AwaitTopic(ctx context.Context, eventBus eventbus.Bus, topic string) <-chan interface{} {
+	// Buffered so the publisher never blocks if the waiter has given up.
+	responseCh := make(chan interface{}, 1)
+	fired := make(chan struct{})
+	handler := func(res interface{}) {
 		responseCh <- res
 		close(responseCh)
-	})
+		close(fired)
+	}
+	err := eventBus.SubscribeOnceAsync(topic, handler)
 	if err != nil {
 		log.WithError(err).Error("could not subscribe async")
+		return responseCh
+	}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-fired:
+			case <-ctx.Done():
+				// The handler may have fired in the meantime, in which case
+				// the bus already removed it.
+				_ = eventBus.Unsubscribe(topic, handler)
+			}
+		}()
 	}
 	return responseCh
 }
 
 type P2PMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 func (broker *MessageBroker) P2PMethods() *P2PMethods {
-	return &P2PMethods{
-		bus:     broker.bus,
-		caller:  broker.caller,
-		service: P2P_SERVICE_NAME,
-	}
+	return &P2PMethods{broker.methodCaller(P2P_SERVICE_NAME)}
 }
 
 func (pm *P2PMethods) ID() (peerID peer.ID) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "id")
+		methodResponse := pm.call("id")
 		if methodResponse.Error != nil {
 			return methodResponse.Error
 		}
@@ -811,21 +783,21 @@ func (pm *P2PMethods) ID() (peerID peer.ID) {
 		}
 		peerID = data
 		return nil
-	})
+	}, retry.Context(pm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get id")
+		pm.fatal(err, "could not get id")
 	}
 	return
 }
 func (pm *P2PMethods) AuthenticateMessage(p2pBasicMsg P2PBasicMsg) (err error) {
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "authenticate_message", p2pBasicMsg)
+	methodResponse := pm.call("authenticate_message", p2pBasicMsg)
 	return methodResponse.Error
 }
 
 func (pm *P2PMethods) NewP2PMessage(messageId string, gossip bool, payload []byte, msgType string) (newMsg P2PBasicMessage) {
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "new_p2p_message", messageId, gossip, payload, msgType)
+	methodResponse := pm.call("new_p2p_message", messageId, gossip, payload, msgType)
 	if methodResponse.Error != nil {
-		log.WithError(methodResponse.Error).Fatal()
+		pm.fatal(methodResponse.Error, "could not create p2p message")
 	}
 	var data P2PBasicMessage
 	err := CastOrUnmarshal(methodResponse.Data, &data)
@@ -837,7 +809,7 @@ func (pm *P2PMethods) NewP2PMessage(messageId string, gossip bool, payload []byt
 }
 
 func (pm *P2PMethods) ConnectToP2PNode(nodeP2PConnection string, nodePeerID peer.ID) error {
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "connect_to_p2p_node", nodeP2PConnection, nodePeerID)
+	methodResponse := pm.call("connect_to_p2p_node", nodeP2PConnection, nodePeerID)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -845,7 +817,7 @@ func (pm *P2PMethods) ConnectToP2PNode(nodeP2PConnection string, nodePeerID peer
 }
 
 func (pm *P2PMethods) SignP2PMessage(message P2PMessage) (signature []byte, err error) {
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "sign_p2p_message", message)
+	methodResponse := pm.call("sign_p2p_message", message)
 	if methodResponse.Error != nil {
 		return signature, methodResponse.Error
 	}
@@ -857,7 +829,7 @@ func (pm *P2PMethods) SignP2PMessage(message P2PMessage) (signature []byte, err
 	return data, nil
 }
 func (pm *P2PMethods) SendP2PMessage(id peer.ID, p protocol.ID, msg P2PMessage) error {
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "send_p2p_message", id, p, msg)
+	methodResponse := pm.call("send_p2p_message", id, p, msg)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -885,41 +857,39 @@ func (pm *P2PMethods) SetStreamHandler(proto string, handler func(StreamMessage)
 	if err != nil {
 		return err
 	}
-	methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "set_stream_handler", proto)
+	methodResponse := pm.call("set_stream_handler", proto)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
 	return nil
 }
 
+func (pm *P2PMethods) Peers() (peers []PeerStatus, err error) {
+	peers, err = request[[]PeerStatus](pm.methodCaller, "peers")
+	return
+}
+
 func (pm *P2PMethods) GetHostAddress() (hostAddress string) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(pm.bus, pm.caller, pm.service, "get_host_address")
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data string
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[string](pm.methodCaller, "get_host_address")
 		if err != nil {
 			return err
 		}
 		hostAddress = data
 		return nil
-	})
+	}, retry.Context(pm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get host address")
+		pm.fatal(err, "could not get host address")
 	}
 	return
 }
 
 type DBMethods struct {
-	bus     eventbus.Bus
-	caller  string
-	service string
+	methodCaller
 }
 
 func (dbm *DBMethods) RetrieveNodePubKey(nodeAddress ethCommon.Address) (pubKey Point, err error) {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "retrieve_node_pub_key", nodeAddress)
+	methodResponse := dbm.call("retrieve_node_pub_key", nodeAddress)
 	if methodResponse.Error != nil {
 		return pubKey, nil
 	}
@@ -932,7 +902,7 @@ func (dbm *DBMethods) RetrieveNodePubKey(nodeAddress ethCommon.Address) (pubKey
 }
 
 func (dbm *DBMethods) StoreConnectionDetails(nodeAddress ethCommon.Address, connectionDetails ConnectionDetails) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_connection_details", nodeAddress, connectionDetails)
+	methodResponse := dbm.call("store_connection_details", nodeAddress, connectionDetails)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -940,7 +910,7 @@ func (dbm *DBMethods) StoreConnectionDetails(nodeAddress ethCommon.Address, conn
 }
 
 func (dbm *DBMethods) StoreNodePubKey(nodeAddress ethCommon.Address, pubKey Point) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_node_pub_key", nodeAddress, pubKey)
+	methodResponse := dbm.call("store_node_pub_key", nodeAddress, pubKey)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -948,7 +918,7 @@ func (dbm *DBMethods) StoreNodePubKey(nodeAddress ethCommon.Address, pubKey Poin
 }
 
 func (dbm *DBMethods) RetrieveConnectionDetails(nodeAddress ethCommon.Address) (connectionDetails ConnectionDetails, err error) {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "retrieve_connection_details", nodeAddress)
+	methodResponse := dbm.call("retrieve_connection_details", nodeAddress)
 	if methodResponse.Error != nil {
 		return connectionDetails, methodResponse.Error
 	}
@@ -961,7 +931,7 @@ func (dbm *DBMethods) RetrieveConnectionDetails(nodeAddress ethCommon.Address) (
 }
 
 func (dbm *DBMethods) StorePSSCommitmentMatrix(keyIndex big.Int, c [][]Point) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_PSS_commitment_matrix", keyIndex, c)
+	methodResponse := dbm.call("store_PSS_commitment_matrix", keyIndex, c)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -969,7 +939,7 @@ func (dbm *DBMethods) StorePSSCommitmentMatrix(keyIndex big.Int, c [][]Point) er
 }
 
 func (dbm *DBMethods) RetrieveCommitmentMatrix(keyIndex big.Int) (c [][]Point, err error) {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "retrieve_commitment_matrix", keyIndex)
+	methodResponse := dbm.call("retrieve_commitment_matrix", keyIndex)
 	if methodResponse.Error != nil {
 		return c, methodResponse.Error
 	}
@@ -981,7 +951,7 @@ func (dbm *DBMethods) RetrieveCommitmentMatrix(keyIndex big.Int) (c [][]Point, e
 	return data, nil
 }
 func (dbm *DBMethods) RetrieveCompletedShare(keyIndex big.Int, curve CurveName) (Si big.Int, Siprime big.Int, err error) {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "retrieve_completed_share", keyIndex, curve)
+	methodResponse := dbm.call("retrieve_completed_share", keyIndex, curve)
 	if methodResponse.Error != nil {
 		err = methodResponse.Error
 		return
@@ -999,7 +969,7 @@ func (dbm *DBMethods) RetrieveCompletedShare(keyIndex big.Int, curve CurveName)
 	return
 }
 func (dbm *DBMethods) SetKeygenStarted(keygenID string, started bool) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "set_keygen_started", keygenID, started)
+	methodResponse := dbm.call("set_keygen_started", keygenID, started)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1007,7 +977,7 @@ func (dbm *DBMethods) SetKeygenStarted(keygenID string, started bool) error {
 }
 
 func (dbm *DBMethods) StoreCompletedPSSShare(keyIndex, si, siprime big.Int, c CurveName) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_completed_PSS_share", keyIndex, si, siprime, c)
+	methodResponse := dbm.call("store_completed_PSS_share", keyIndex, si, siprime, c)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1015,7 +985,7 @@ func (dbm *DBMethods) StoreCompletedPSSShare(keyIndex, si, siprime big.Int, c Cu
 }
 
 func (dbm *DBMethods) StoreCommitment(keyIndex big.Int, T []int, metadata map[string][]Point, c CurveName) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_sharing_commitment", keyIndex, T, metadata, c)
+	methodResponse := dbm.call("store_sharing_commitment", keyIndex, T, metadata, c)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1023,7 +993,7 @@ func (dbm *DBMethods) StoreCommitment(keyIndex big.Int, T []int, metadata map[st
 }
 
 func (dbm *DBMethods) StorePublicKeyToIndex(publicKey Point, keyIndex big.Int, c CurveName) error {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "store_public_key_to_index", publicKey, keyIndex, c)
+	methodResponse := dbm.call("store_public_key_to_index", publicKey, keyIndex, c)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1032,46 +1002,36 @@ func (dbm *DBMethods) StorePublicKeyToIndex(publicKey Point, keyIndex big.Int, c
 
 func (dbm *DBMethods) GetKeygenStarted(keygenID string) (started bool) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "get_keygen_started", keygenID)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data bool
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[bool](dbm.methodCaller, "get_keygen_started", keygenID)
 		if err != nil {
 			return err
 		}
 		started = data
 		return nil
-	})
+	}, retry.Context(dbm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get keygen started")
+		dbm.fatal(err, "could not get keygen started")
 	}
 	return
 }
 
 func (dbm *DBMethods) IndexToPublicKeyExists(keyIndex big.Int, curve CurveName) (exists bool) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "index_to_public_key_exists", keyIndex, curve)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data bool
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+		data, err := request[bool](dbm.methodCaller, "index_to_public_key_exists", keyIndex, curve)
 		if err != nil {
 			return err
 		}
 		exists = data
 		return nil
-	})
+	}, retry.Context(dbm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get index_to_public_key_exists")
+		dbm.fatal(err, "could not get index_to_public_key_exists")
 	}
 	return
 }
 
 func (dbm *DBMethods) RetrievePublicKeyToIndex(publicKey Point) (keyIndex big.Int, err error) {
-	methodResponse := ServiceMethod(dbm.bus, dbm.caller, dbm.service, "retrieve_public_key_to_index", publicKey)
+	methodResponse := dbm.call("retrieve_public_key_to_index", publicKey)
 	if methodResponse.Error != nil {
 		return keyIndex, methodResponse.Error
 	}
@@ -1084,20 +1044,26 @@ func (dbm *DBMethods) RetrievePublicKeyToIndex(publicKey Point) (keyIndex big.In
 }
 
 type KeygenMethods struct {
-	caller  string
-	bus     eventbus.Bus
-	service string
+	methodCaller
 }
 
 func (km *KeygenMethods) ReceiveMessage(keygenMessage DKGMessage) error {
-	methodResponse := ServiceMethod(km.bus, km.caller, km.service, "receive_message", keygenMessage)
+	methodResponse := km.call("receive_message", keygenMessage)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
 	return nil
 }
+func (km *KeygenMethods) Sessions() (sessions []SessionStatus, err error) {
+	sessions, err = request[[]SessionStatus](km.methodCaller, "sessions")
+	return
+}
+func (km *KeygenMethods) CancelSession(id ADKGID) error {
+	methodResponse := km.call("cancel_session", id)
+	return methodResponse.Error
+}
 func (km *KeygenMethods) Cleanup(id ADKGID) error {
-	methodResponse := ServiceMethod(km.bus, km.caller, km.service, "cleanup", id)
+	methodResponse := km.call("cleanup", id)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1105,9 +1071,7 @@ func (km *KeygenMethods) Cleanup(id ADKGID) error {
 }
 
 type TendermintMethods struct {
-	caller  string
-	bus     eventbus.Bus
-	service string
+	methodCaller
 }
 
 type Hash struct {
@@ -1160,7 +1124,7 @@ func (tm *TendermintMethods) DeregisterQuery(query string, handler interface{})
 	if err != nil {
 		return err
 	}
-	methodResponse := ServiceMethod(tm.bus, tm.caller, tm.service, "deregister_query", query)
+	methodResponse := tm.call("deregister_query", query)
 	if methodResponse.Error != nil {
 		return methodResponse.Error
 	}
@@ -1169,7 +1133,7 @@ func (tm *TendermintMethods) DeregisterQuery(query string, handler interface{})
 
 func (tm *TendermintMethods) GetNodeKey() (nodeKey tmp2p.NodeKey) {
 	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(tm.bus, tm.caller, tm.service, "get_node_key")
+		methodResponse := tm.call("get_node_key")
 		if methodResponse.Error != nil {
 			return methodResponse.Error
 		}
@@ -1188,28 +1152,23 @@ func (tm *TendermintMethods) GetNodeKey() (nodeKey tmp2p.NodeKey) {
 		}
 		nodeKey = *newKey
 		return nil
-	})
+	}, retry.Context(tm.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not get nodeKey")
+		tm.fatal(err, "could not get nodeKey")
 	}
 	return
 }
 
 func (tm *TendermintMethods) Broadcast(tx interface{}) (txHash Hash, err error) {
-	methodResponse := ServiceMethod(tm.bus, tm.caller, tm.service, "broadcast", tx)
-	if methodResponse.Error != nil {
-		return txHash, methodResponse.Error
-	}
-	var data Hash
-	err = CastOrUnmarshal(methodResponse.Data, &data)
-	if err != nil {
-		return txHash, err
-	}
-	txHash = data
+	txHash, err = request[Hash](tm.methodCaller, "broadcast", tx)
+	return
+}
+func (tm *TendermintMethods) QueueStatus() (status QueueStatus, err error) {
+	status, err = request[QueueStatus](tm.methodCaller, "queue_status")
 	return
 }
 func (tm *TendermintMethods) TxStatus(hash []byte) (bool, error) {
-	methodResponse := ServiceMethod(tm.bus, tm.caller, tm.service, "tx_status", hash)
+	methodResponse := tm.call("tx_status", hash)
 	if methodResponse.Error != nil {
 		return false, methodResponse.Error
 	}
@@ -1222,54 +1181,48 @@ func (tm *TendermintMethods) TxStatus(hash []byte) (bool, error) {
 }
 
 type CacheMethods struct {
-	caller  string
-	bus     eventbus.Bus
-	service string
+	methodCaller
 }
 
-func (cam *CacheMethods) TokenCommitExists(verifier string, tokenCommitment string) (exists bool) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "token_commit_exists", verifier, tokenCommitment)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data bool
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cam *CacheMethods) TokenCommitExists(verifier string, tokenCommitment string) (exists bool, err error) {
+	err = retry.Do(func() error {
+		data, err := request[bool](cam.methodCaller, "token_commit_exists", verifier, tokenCommitment)
 		if err != nil {
 			return err
 		}
 		exists = data
 		return nil
-	})
+	}, retry.Context(cam.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not check if token commit exists")
+		return false, cam.fatal(err, "could not check if token commit exists")
 	}
 	return
 }
 
-func (cam *CacheMethods) RecordTokenCommit(verifier string, tokenCommitment string, pubKey Point) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "record_token_commit", verifier, tokenCommitment, pubKey)
+func (cam *CacheMethods) RecordTokenCommit(verifier string, tokenCommitment string, pubKey Point) error {
+	methodResponse := cam.call("record_token_commit", verifier, tokenCommitment, pubKey)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("could not record token commit")
 	}
+	return methodResponse.Error
 }
 
 func (cam *CacheMethods) StoreVerifierToClientID(appID, verifier string, params *VerifierParams) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "store_verifier_params", appID, verifier, params)
+	methodResponse := cam.call("store_verifier_params", appID, verifier, params)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("StoreVerifierToClientID")
 	}
 }
 
 func (cam *CacheMethods) SetBuffer(buffer int) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "set_buffer", buffer)
+	methodResponse := cam.call("set_buffer", buffer)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("SetBuffer")
 	}
 }
 
 func (cam *CacheMethods) GetBuffer() (buffer int, err error) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "get_buffer")
+	methodResponse := cam.call("get_buffer")
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("GetBuffer")
 		err = methodResponse.Error
@@ -1284,14 +1237,14 @@ func (cam *CacheMethods) GetBuffer() (buffer int, err error) {
 }
 
 func (cam *CacheMethods) StorePartitionForApp(appID string, partitioned bool) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "store_app_partition", appID, partitioned)
+	methodResponse := cam.call("store_app_partition", appID, partitioned)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("StorePartitionForApp")
 	}
 }
 
 func (cam *CacheMethods) RetrieveClientIDFromVerifier(appID, verifier string) *VerifierParams {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "retrieve_verifier_params", appID, verifier)
+	methodResponse := cam.call("retrieve_verifier_params", appID, verifier)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("RetrieveClientIDFromVerifier")
 	}
@@ -1304,7 +1257,7 @@ func (cam *CacheMethods) RetrieveClientIDFromVerifier(appID, verifier string) *V
 	return &params
 }
 func (cam *CacheMethods) GetPartitionForApp(appID string) (partitioned bool, err error) {
-	methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "retrieve_app_partition", appID)
+	methodResponse := cam.call("retrieve_app_partition", appID)
 	if methodResponse.Error != nil {
 		log.WithError(methodResponse.Error).Error("GetPartitionForApp")
 		err = methodResponse.Error
@@ -1318,22 +1271,17 @@ func (cam *CacheMethods) GetPartitionForApp(appID string) (partitioned bool, err
 	return
 }
 
-func (cam *CacheMethods) GetTokenCommitKey(verifier string, tokenCommitment string) (pubKey Point) {
-	err := retry.Do(func() error {
-		methodResponse := ServiceMethod(cam.bus, cam.caller, cam.service, "get_token_commit_key", verifier, tokenCommitment)
-		if methodResponse.Error != nil {
-			return methodResponse.Error
-		}
-		var data Point
-		err := CastOrUnmarshal(methodResponse.Data, &data)
+func (cam *CacheMethods) GetTokenCommitKey(verifier string, tokenCommitment string) (pubKey Point, err error) {
+	err = retry.Do(func() error {
+		data, err := request[Point](cam.methodCaller, "get_token_commit_key", verifier, tokenCommitment)
 		if err != nil {
 			return err
 		}
 		pubKey = data
 		return nil
-	})
+	}, retry.Context(cam.context()))
 	if err != nil {
-		log.WithError(err).Fatal("could not check if token commit exists")
+		return Point{}, cam.fatal(err, "could not check if token commit exists")
 	}
 	return
 }
@@ -10,3 +10,8 @@ const CACHE_SERVICE_NAME = "cache"
 const SERVER_SERVICE_NAME = "server"
 const VERIFIER_SERVICE_NAME = "verifier"
 const KEYSTORE_SERVICE_NAME = "keystore"
+
+// REGISTRY_SERVICE_NAME routes calls to the service registry itself.
+const REGISTRY_SERVICE_NAME = "registry"
+const ADMIN_SERVICE_NAME = "admin"
+const AUDIT_SERVICE_NAME = "audit"
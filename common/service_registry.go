@@ -1,7 +1,11 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/arcana-network/dkgnode/eventbus"
 	"github.com/avast/retry-go"
@@ -16,50 +20,382 @@ type IService interface {
 	Call(method string, args ...interface{}) (interface{}, error)
 }
 
+// PausableService is implemented by services that can be paused. A paused
+// service does not report IsRunning, but is not restarted either.
+type PausableService interface {
+	IsPaused() bool
+}
+
+// DependentService is implemented by services that need other services to
+// be running before they can start.
+type DependentService interface {
+	Dependencies() []string
+}
+
+// Drainer is implemented by services that have work in flight which should
+// be allowed to finish before the node stops. Drain stops the service from
+// taking on new work and waits for its current work until ctx is done.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+type ServiceState string
+
+const (
+	ServiceStopped  ServiceState = "stopped"
+	ServiceStarting ServiceState = "starting"
+	ServiceRunning  ServiceState = "running"
+	ServiceFailed   ServiceState = "failed"
+)
+
+const (
+	supervisorInterval = 5 * time.Second
+	restartMinBackoff  = time.Second
+	restartMaxBackoff  = time.Minute
+	drainPollInterval  = 200 * time.Millisecond
+)
+
+// ServiceStatus is the state of a service as tracked by the registry.
+type ServiceStatus struct {
+	State     ServiceState `json:"state"`
+	Restarts  int          `json:"restarts"`
+	LastError string       `json:"last_error,omitempty"`
+}
+
+type registeredService struct {
+	service     IService
+	status      ServiceStatus
+	started     chan struct{}
+	backoff     time.Duration
+	nextRestart time.Time
+}
+
 type ServiceRegistry struct {
-	services map[string]IService
+	sync.Mutex
+	services map[string]*registeredService
+	order    []string
+	stopping bool
 	bus      eventbus.Bus
 }
 
 func NewServiceRegistry(bus eventbus.Bus) *ServiceRegistry {
 	return &ServiceRegistry{
-		services: make(map[string]IService),
+		services: make(map[string]*registeredService),
 		bus:      bus,
 	}
 }
 
 func (s *ServiceRegistry) RegisterService(service IService) error {
+	s.Lock()
+	defer s.Unlock()
 	if _, exists := s.services[service.ID()]; exists {
 		return fmt.Errorf("service already exists: %v", service.ID())
 	}
-	s.services[service.ID()] = service
+	s.services[service.ID()] = &registeredService{
+		service: service,
+		status:  ServiceStatus{State: ServiceStopped},
+		started: make(chan struct{}),
+	}
+	return nil
+}
+
+func dependenciesOf(service IService) []string {
+	if d, ok := service.(DependentService); ok {
+		return d.Dependencies()
+	}
 	return nil
 }
 
+// startOrder sorts the services so that every service comes after its
+// dependencies. Services without an ordering constraint are sorted by name.
+func (s *ServiceRegistry) startOrder() ([]string, error) {
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for name, r := range s.services {
+		pending[name] += 0
+		for _, dep := range dependenciesOf(r.service) {
+			if _, ok := s.services[dep]; !ok {
+				return nil, fmt.Errorf("service %s depends on unknown service %s", name, dep)
+			}
+			pending[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var ready, order []string
+	for name, count := range pending {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(order) != len(s.services) {
+		return nil, fmt.Errorf("service dependencies contain a cycle")
+	}
+	return order, nil
+}
+
+// StartAll starts the services in dependency order. Each service is started
+// in its own goroutine once all of its dependencies are running, so a
+// service that takes long to start only holds back its dependents.
 func (s *ServiceRegistry) StartAll() error {
-	for name, service := range s.services {
-		go func(n string, s IService) {
-			log.Info(fmt.Sprintf(`Starting service: %s`, n))
-			err := s.Start()
-			if err != nil {
-				log.Info(fmt.Sprintf(`Error during starting service: %s %v`, n, err))
+	s.Lock()
+	order, err := s.startOrder()
+	if err != nil {
+		s.Unlock()
+		return err
+	}
+	s.order = order
+	s.stopping = false
+	s.Unlock()
+
+	for _, name := range order {
+		go s.startWhenReady(name)
+	}
+	go s.supervise()
+	return nil
+}
+
+func (s *ServiceRegistry) startWhenReady(name string) {
+	s.Lock()
+	deps := dependenciesOf(s.services[name].service)
+	s.Unlock()
+
+	for _, dep := range deps {
+		s.Lock()
+		started := s.services[dep].started
+		s.Unlock()
+		<-started
+	}
+	s.start(name)
+}
+
+func (s *ServiceRegistry) start(name string) {
+	s.Lock()
+	r := s.services[name]
+	if s.stopping || r.status.State == ServiceStarting || r.status.State == ServiceRunning {
+		s.Unlock()
+		return
+	}
+	r.status.State = ServiceStarting
+	s.Unlock()
+
+	log.Info(fmt.Sprintf(`Starting service: %s`, name))
+	err := r.service.Start()
+
+	s.Lock()
+	defer s.Unlock()
+	if err != nil {
+		log.Info(fmt.Sprintf(`Error during starting service: %s %v`, name, err))
+		s.markFailed(r, err)
+		return
+	}
+	r.status.State = ServiceRunning
+	r.backoff = 0
+	select {
+	case <-r.started:
+	default:
+		close(r.started)
+	}
+}
+
+func (s *ServiceRegistry) markFailed(r *registeredService, err error) {
+	r.backoff *= 2
+	if r.backoff < restartMinBackoff {
+		r.backoff = restartMinBackoff
+	}
+	if r.backoff > restartMaxBackoff {
+		r.backoff = restartMaxBackoff
+	}
+	r.status.State = ServiceFailed
+	r.status.LastError = err.Error()
+	r.nextRestart = time.Now().Add(r.backoff)
+}
+
+// supervise marks running services that stopped reporting IsRunning as
+// failed, and restarts failed services with exponential backoff.
+func (s *ServiceRegistry) supervise() {
+	ticker := time.NewTicker(supervisorInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		names, ok := s.checkServices(time.Now())
+		if !ok {
+			return
+		}
+		for _, name := range names {
+			log.WithField("Service", name).Warn("restarting service")
+			go func(name string, service IService) {
+				if err := service.Stop(); err != nil {
+					log.WithError(err).WithField("Service", name).Debug("stop before restart")
+				}
+				s.start(name)
+			}(name, s.serviceOf(name))
+		}
+	}
+}
+
+// checkServices marks the services that stopped running as failed, and
+// returns the failed services due for a restart. It reports false once the
+// registry is stopping.
+func (s *ServiceRegistry) checkServices(now time.Time) ([]string, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if s.stopping {
+		return nil, false
+	}
+	var restart []string
+	for _, name := range s.order {
+		r := s.services[name]
+		if r.status.State == ServiceRunning && !r.service.IsRunning() && !isPaused(r.service) {
+			log.WithField("Service", name).Error("service stopped running")
+			s.markFailed(r, fmt.Errorf("service %s is not running", name))
+		}
+		if r.status.State == ServiceFailed && now.After(r.nextRestart) {
+			r.status.Restarts++
+			restart = append(restart, name)
+		}
+	}
+	return restart, true
+}
+
+func (s *ServiceRegistry) serviceOf(name string) IService {
+	s.Lock()
+	defer s.Unlock()
+	return s.services[name].service
+}
+
+func isPaused(service IService) bool {
+	pausable, ok := service.(PausableService)
+	return ok && pausable.IsPaused()
+}
+
+// Drain asks every running service that implements Drainer to finish its
+// work in flight. The services are drained concurrently, so that each of
+// them stops taking on new work right away rather than waiting for the
+// others. It returns once every service is drained or ctx is done; services
+// that did not drain in time are logged.
+func (s *ServiceRegistry) Drain(ctx context.Context) {
+	s.Lock()
+	s.stopping = true
+	var drainers []Drainer
+	var names []string
+	for _, name := range s.order {
+		r := s.services[name]
+		if drainer, ok := r.service.(Drainer); ok && r.status.State == ServiceRunning {
+			drainers = append(drainers, drainer)
+			names = append(names, name)
+		}
+	}
+	s.Unlock()
+
+	var wg sync.WaitGroup
+	for i, drainer := range drainers {
+		wg.Add(1)
+		go func(name string, drainer Drainer) {
+			defer wg.Done()
+			log.Info(fmt.Sprintf(`Draining service: %s`, name))
+			if err := drainer.Drain(ctx); err != nil {
+				log.WithError(err).Warnf("Draining service %q", name)
 			}
-		}(name, service)
+		}(names[i], drainer)
+	}
+	wg.Wait()
+}
+
+// Shutdown drains the services for at most timeout and then stops them.
+func (s *ServiceRegistry) Shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	s.Drain(ctx)
+	return s.StopAll()
+}
+
+// WaitUntil polls done until it reports true or ctx is done. It is used by
+// services to wait for their work in flight while draining.
+func WaitUntil(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 	}
 	return nil
 }
 
+// StopAll stops the services in reverse dependency order.
 func (s *ServiceRegistry) StopAll() (err error) {
-	for name, service := range s.services {
+	s.Lock()
+	s.stopping = true
+	order := s.order
+	if order == nil {
+		order, _ = s.startOrder()
+	}
+	s.Unlock()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		s.Lock()
+		r := s.services[name]
+		s.Unlock()
+
 		log.Info(fmt.Sprintf(`Stopping service: %s`, name))
-		err = service.Stop()
-		if err != nil {
-			log.WithError(err).Errorf("Stopping service %q", name)
+		stopErr := r.service.Stop()
+		if stopErr != nil {
+			err = stopErr
+			log.WithError(stopErr).Errorf("Stopping service %q", name)
 		}
+		s.Lock()
+		r.status.State = ServiceStopped
+		s.Unlock()
 	}
 	return err
 }
 
+// States returns the status of every registered service.
+func (s *ServiceRegistry) States() map[string]ServiceStatus {
+	s.Lock()
+	defer s.Unlock()
+	states := make(map[string]ServiceStatus, len(s.services))
+	for name, r := range s.services {
+		states[name] = r.status
+	}
+	return states
+}
+
+// Degraded reports whether any of the services is not running.
+func Degraded(states map[string]ServiceStatus) bool {
+	for _, status := range states {
+		if status.State != ServiceRunning {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ServiceRegistry) lookup(name string) (IService, bool) {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.services[name]
+	if !ok {
+		return nil, false
+	}
+	return r.service, true
+}
+
 func (s *ServiceRegistry) SetupMethodRouting() {
 	err := s.bus.SubscribeAsync("method", func(data interface{}) {
 		methodRequest, ok := data.(MethodRequest)
@@ -67,9 +403,17 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 			log.Error("could not parse data for query")
 			return
 		}
+		ctx := methodRequest.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if methodRequest.Service == REGISTRY_SERVICE_NAME {
+			s.bus.Publish(methodRequest.ID, s.call(methodRequest))
+			return
+		}
 		var baseService IService
 		err := retry.Do(func() error {
-			bs, ok := s.services[methodRequest.Service]
+			bs, ok := s.lookup(methodRequest.Service)
 			if !ok {
 				log.WithField("Service", methodRequest.Service).Error("could not find service")
 				return fmt.Errorf("could not find service %v", methodRequest.Service)
@@ -82,7 +426,7 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 			}
 			baseService = bs
 			return nil
-		})
+		}, retry.Context(ctx), retry.LastErrorOnly(true))
 		if err != nil {
 			s.bus.Publish(methodRequest.ID, MethodResponse{
 				Error: err,
@@ -107,6 +451,11 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 						s.bus.Publish(methodRequest.ID, resp)
 					}
 				}()
+				// The caller stopped waiting while the request was routed.
+				if err := ctx.Err(); err != nil {
+					s.bus.Publish(methodRequest.ID, MethodResponse{Request: methodRequest, Error: err})
+					return
+				}
 				data, err := baseService.Call(methodRequest.Method, methodRequest.Data...)
 				resp := MethodResponse{
 					Request: methodRequest,
@@ -121,3 +470,15 @@ func (s *ServiceRegistry) SetupMethodRouting() {
 		log.WithError(err).Error("could not subscribe async")
 	}
 }
+
+// call answers the methods served by the registry itself.
+func (s *ServiceRegistry) call(methodRequest MethodRequest) MethodResponse {
+	resp := MethodResponse{Request: methodRequest}
+	switch methodRequest.Method {
+	case "service_states":
+		resp.Data = s.States()
+	default:
+		resp.Error = fmt.Errorf("registry method %v not found", methodRequest.Method)
+	}
+	return resp
+}
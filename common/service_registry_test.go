@@ -0,0 +1,209 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/eventbus"
+)
+
+type orderedService struct {
+	id       string
+	deps     []string
+	startErr error
+	log      *startLog
+}
+
+type startLog struct {
+	sync.Mutex
+	started []string
+	stopped []string
+}
+
+func (o *orderedService) ID() string             { return o.id }
+func (o *orderedService) Dependencies() []string { return o.deps }
+func (o *orderedService) IsRunning() bool        { return true }
+func (o *orderedService) Call(string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+func (o *orderedService) Start() error {
+	o.log.Lock()
+	defer o.log.Unlock()
+	o.log.started = append(o.log.started, o.id)
+	return o.startErr
+}
+func (o *orderedService) Stop() error {
+	o.log.Lock()
+	defer o.log.Unlock()
+	o.log.stopped = append(o.log.stopped, o.id)
+	return nil
+}
+
+func newOrderedRegistry(t *testing.T, services ...*orderedService) *ServiceRegistry {
+	registry := NewServiceRegistry(eventbus.New())
+	for _, s := range services {
+		if err := registry.RegisterService(s); err != nil {
+			t.Fatalf("RegisterService() error = %v", err)
+		}
+	}
+	return registry
+}
+
+func TestStartOrder(t *testing.T) {
+	log := &startLog{}
+	registry := newOrderedRegistry(t,
+		&orderedService{id: "keygen", deps: []string{"p2p", "chain", "database"}, log: log},
+		&orderedService{id: "p2p", deps: []string{"chain"}, log: log},
+		&orderedService{id: "chain", log: log},
+		&orderedService{id: "database", log: log},
+	)
+
+	order, err := registry.startOrder()
+	if err != nil {
+		t.Fatalf("startOrder() error = %v", err)
+	}
+	want := []string{"chain", "database", "p2p", "keygen"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("startOrder() = %v, want %v", order, want)
+	}
+}
+
+func TestStartOrderRejectsCyclesAndUnknownServices(t *testing.T) {
+	log := &startLog{}
+	cyclic := newOrderedRegistry(t,
+		&orderedService{id: "a", deps: []string{"b"}, log: log},
+		&orderedService{id: "b", deps: []string{"a"}, log: log},
+	)
+	if _, err := cyclic.startOrder(); err == nil {
+		t.Error("startOrder() with a cycle returned no error")
+	}
+
+	unknown := newOrderedRegistry(t, &orderedService{id: "a", deps: []string{"missing"}, log: log})
+	if _, err := unknown.startOrder(); err == nil {
+		t.Error("startOrder() with an unknown dependency returned no error")
+	}
+}
+
+func TestStartAllWaitsForDependencies(t *testing.T) {
+	log := &startLog{}
+	registry := newOrderedRegistry(t,
+		&orderedService{id: "keygen", deps: []string{"chain"}, log: log},
+		&orderedService{id: "chain", startErr: errors.New("rpc unavailable"), log: log},
+		&orderedService{id: "cache", log: log},
+	)
+	if err := registry.StartAll(); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	states := registry.States()
+	if states["cache"].State != ServiceRunning {
+		t.Errorf("cache state = %v, want %v", states["cache"].State, ServiceRunning)
+	}
+	if states["chain"].State != ServiceFailed || states["chain"].LastError != "rpc unavailable" {
+		t.Errorf("chain status = %+v, want failed with the start error", states["chain"])
+	}
+	if states["keygen"].State != ServiceStopped {
+		t.Errorf("keygen state = %v, want it to wait for chain", states["keygen"].State)
+	}
+	if !Degraded(states) {
+		t.Error("Degraded() = false with a failed service")
+	}
+
+	if err := registry.StopAll(); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+	want := []string{"keygen", "chain", "cache"}
+	if !reflect.DeepEqual(log.stopped, want) {
+		t.Errorf("stop order = %v, want %v", log.stopped, want)
+	}
+}
+
+type drainingService struct {
+	orderedService
+	inFlight chan struct{}
+	drained  bool
+}
+
+func (d *drainingService) Drain(ctx context.Context) error {
+	select {
+	case <-d.inFlight:
+		d.drained = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestShutdownDrainsBeforeStopping(t *testing.T) {
+	log := &startLog{}
+	finishing := &drainingService{orderedService: orderedService{id: "keygen", log: log}, inFlight: make(chan struct{})}
+	stuck := &drainingService{orderedService: orderedService{id: "tendermint", log: log}, inFlight: make(chan struct{})}
+	registry := NewServiceRegistry(eventbus.New())
+	for _, s := range []IService{finishing, stuck} {
+		if err := registry.RegisterService(s); err != nil {
+			t.Fatalf("RegisterService() error = %v", err)
+		}
+	}
+	if err := registry.StartAll(); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(finishing.inFlight)
+	}()
+	start := time.Now()
+	if err := registry.Shutdown(300 * time.Millisecond); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v, want it bounded by the timeout", elapsed)
+	}
+	if !finishing.drained {
+		t.Error("Shutdown() stopped keygen before its work completed")
+	}
+	if len(log.stopped) != 2 {
+		t.Errorf("stopped = %v, want both services stopped after the timeout", log.stopped)
+	}
+}
+
+type stateService struct {
+	orderedService
+	running, paused bool
+}
+
+func (s *stateService) IsRunning() bool { return s.running }
+func (s *stateService) IsPaused() bool  { return s.paused }
+
+func TestSupervisorSkipsPausedServices(t *testing.T) {
+	log := &startLog{}
+	paused := &stateService{orderedService: orderedService{id: "p2p", log: log}, paused: true}
+	stopped := &stateService{orderedService: orderedService{id: "database", log: log}}
+	registry := NewServiceRegistry(eventbus.New())
+	for _, s := range []IService{paused, stopped} {
+		if err := registry.RegisterService(s); err != nil {
+			t.Fatalf("RegisterService() error = %v", err)
+		}
+	}
+	registry.order = []string{"p2p", "database"}
+	for _, r := range registry.services {
+		r.status.State = ServiceRunning
+	}
+
+	restart, ok := registry.checkServices(time.Now().Add(restartMaxBackoff))
+	if !ok {
+		t.Fatal("checkServices() reported the registry as stopping")
+	}
+	if !reflect.DeepEqual(restart, []string{"database"}) {
+		t.Errorf("restarted = %v, want only the service that stopped running", restart)
+	}
+	if state := registry.services["p2p"].status.State; state != ServiceRunning {
+		t.Errorf("paused service state = %v", state)
+	}
+}
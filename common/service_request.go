@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/telemetry"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// methodCaller is embedded by every method family of the MessageBroker. It
+// routes calls to one service and carries the context they run under.
+type methodCaller struct {
+	bus     eventbus.Bus
+	caller  string
+	service string
+	ctx     context.Context
+}
+
+func (m methodCaller) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+func (m methodCaller) call(method string, data ...interface{}) MethodResponse {
+	return ServiceMethodContext(m.context(), m.bus, m.caller, m.service, method, data...)
+}
+
+// fatal stops the node after a call that has to succeed failed. If the
+// failure only happened because the caller's context ended, the error is
+// returned for the caller to give up on instead. Methods that do not return
+// it are only called through brokers without a context.
+func (m methodCaller) fatal(err error, msg string) error {
+	if m.context().Err() != nil {
+		log.WithError(err).Error(msg)
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	log.WithError(err).Fatal(msg)
+	return err
+}
+
+// request calls a method and decodes its result into T.
+func request[T any](m methodCaller, method string, data ...interface{}) (result T, err error) {
+	methodResponse := m.call(method, data...)
+	if methodResponse.Error != nil {
+		return result, methodResponse.Error
+	}
+	err = CastOrUnmarshal(methodResponse.Data, &result)
+	return result, err
+}
+
+// Request calls a method on a service through the event bus and decodes
+// the result into T. It gives up when ctx is done.
+func Request[T any](ctx context.Context, eventBus eventbus.Bus, caller, service, method string, data ...interface{}) (T, error) {
+	return request[T](methodCaller{bus: eventBus, caller: caller, service: service, ctx: ctx}, method, data...)
+}
+
+// ServiceMethodContext publishes a method request and waits for the
+// response or for ctx to be done, whichever happens first. The context is
+// passed along with the request so the registry can drop requests nobody is
+// waiting for anymore.
+func ServiceMethodContext(ctx context.Context, eventBus eventbus.Bus, caller string, service string, method string, data ...interface{}) (methodResponse MethodResponse) {
+	start := time.Now()
+	defer func() {
+		telemetry.ObserveServiceMethod(service, method, time.Since(start), methodResponse.Error)
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return MethodResponse{Error: fmt.Errorf("%s.%s: %w", service, method, err)}
+	}
+	nonce, err := rand.Int(rand.Reader, secp256k1.GeneratorOrder)
+	if err != nil {
+		return MethodResponse{
+			Error: errors.New("could not generate random nonce"),
+			Data:  nil,
+		}
+	}
+	nonceStr := nonce.Text(16)
+	responseCh := AwaitTopic(ctx, eventBus, nonceStr)
+	eventBus.Publish("method", MethodRequest{
+		Caller:  caller,
+		Service: service,
+		Method:  method,
+		ID:      nonceStr,
+		Data:    data,
+		Context: ctx,
+	})
+
+	select {
+	case methodResponseInter := <-responseCh:
+		methodResponse, ok := methodResponseInter.(MethodResponse)
+		if !ok {
+			return MethodResponse{
+				Error: errors.New("method response was not of MethodResponse type"),
+				Data:  nil,
+			}
+		}
+		return methodResponse
+	case <-ctx.Done():
+		log.WithFields(log.Fields{
+			"Caller":  caller,
+			"Service": service,
+			"Method":  method,
+		}).WithError(ctx.Err()).Warn("ServiceMethodContext: gave up waiting for response")
+		return MethodResponse{Error: fmt.Errorf("%s.%s: %w", service, method, ctx.Err())}
+	}
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/eventbus"
+)
+
+type stubService struct {
+	block chan struct{}
+}
+
+func (*stubService) ID() string      { return "stub" }
+func (*stubService) Start() error    { return nil }
+func (*stubService) Stop() error     { return nil }
+func (*stubService) IsRunning() bool { return true }
+func (s *stubService) Call(method string, args ...interface{}) (interface{}, error) {
+	switch method {
+	case "echo":
+		var arg string
+		_ = CastOrUnmarshal(args[0], &arg)
+		return arg, nil
+	case "hang":
+		<-s.block
+		return nil, nil
+	}
+	return nil, errors.New("method not found")
+}
+
+func newStubRegistry(t *testing.T) (eventbus.Bus, *stubService) {
+	bus := eventbus.New()
+	registry := NewServiceRegistry(bus)
+	registry.SetupMethodRouting()
+	service := &stubService{block: make(chan struct{})}
+	registry.RegisterService(service)
+	t.Cleanup(func() { close(service.block) })
+	return bus, service
+}
+
+func TestRequestDecodesResult(t *testing.T) {
+	bus, _ := newStubRegistry(t)
+
+	got, err := Request[string](context.Background(), bus, "test", "stub", "echo", "hello")
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("Request() = %q, want %q", got, "hello")
+	}
+}
+
+func TestRequestHonoursDeadline(t *testing.T) {
+	bus, _ := newStubRegistry(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := Request[string](ctx, bus, "test", "stub", "hang")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Request() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Request() returned after %v, want it to stop at the deadline", elapsed)
+	}
+}
+
+func TestRequestWithCancelledContext(t *testing.T) {
+	bus, _ := newStubRegistry(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	broker := NewServiceBroker(bus, "test").WithContext(ctx)
+	if _, err := request[string](broker.methodCaller("stub"), "echo", "hello"); !errors.Is(err, context.Canceled) {
+		t.Errorf("request() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAwaitTopicUnsubscribesWhenAbandoned(t *testing.T) {
+	bus := eventbus.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	AwaitTopic(ctx, bus, "response")
+	if !bus.HasCallback("response") {
+		t.Fatal("AwaitTopic() did not subscribe")
+	}
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for bus.HasCallback("response") {
+		if time.Now().After(deadline) {
+			t.Fatal("handler of an abandoned wait is still subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
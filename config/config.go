@@ -1,16 +1,15 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
+	"sync"
 
 	"github.com/arcana-network/dkgnode/secret"
-	"github.com/arcana-network/dkgnode/secret/vault"
-	log "github.com/sirupsen/logrus"
+	"github.com/arcana-network/dkgnode/secret/backend"
 )
 
+// GlobalConfig is the running config. It is replaced on reload, so it is
+// read through Current and set through Set.
 var GlobalConfig *Config
 
 type Config struct {
@@ -19,31 +18,174 @@ type Config struct {
 	RawPrivateKey      string `json:"privatekey"`
 	PrivateKey         []byte
 	TMPrivateKey       []byte
-	SecretConfigPath   string `json:"secretConfigPath"`
-	BasePath           string `json:"dataDirectory"`
-	IPAddress          string `json:"ipAddress"`
-	EthConnection      string `json:"blockchainRPCURL"`
-	ContractAddress    string `json:"dkgContractAddress"`
-	HttpServerPort     string `json:"port"`
-	Domain             string `json:"domain"`
-	GatewayURL         string `json:"gatewayUrl"`
-	PasswordlessUrl    string `json:"passwordlessUrl"`
-	OAuthUrl           string `json:"oauthUrl"`
-	GlobalKeyCertPool  string `json:"globalKeyCertPool"`
+	// SecretConfigPath is the secret config the keys are read from at start.
+	// Node keys rotated in the secret manager are only used after a restart,
+	// a rotated remote signer token right away.
+	SecretConfigPath string `json:"secretConfigPath"`
+	BasePath         string `json:"dataDirectory"`
+	IPAddress        string `json:"ipAddress"`
+	EthConnection    string `json:"blockchainRPCURL"`
+	// EthConnections are further blockchain RPC endpoints the node fails
+	// over to when EthConnection is unavailable.
+	EthConnections []string `json:"blockchainRPCURLs"`
+	// ChainQuorum is the number of RPC endpoints that have to agree on epoch
+	// and node list reads. Zero means a majority of the healthy endpoints.
+	ChainQuorum       int    `json:"chainQuorum"`
+	ContractAddress   string `json:"dkgContractAddress"`
+	HttpServerPort    string `json:"port"`
+	Domain            string `json:"domain"`
+	GatewayURL        string `json:"gatewayUrl"`
+	PasswordlessUrl   string `json:"passwordlessUrl"`
+	OAuthUrl          string `json:"oauthUrl"`
+	GlobalKeyCertPool string `json:"globalKeyCertPool"`
+
+	// NodeRegistry selects where epochs and node lists are read from:
+	// "contract" (the default) or "file".
+	NodeRegistry string `json:"nodeRegistry"`
+	// NodeRegistryFile is the signed registry file used by the file registry.
+	NodeRegistryFile string `json:"nodeRegistryFile"`
+	// NodeRegistryAdmin is the address whose signature the registry file must carry.
+	NodeRegistryAdmin string `json:"nodeRegistryAdmin"`
+
+	// Signer selects how the node identity key signs chain txs, p2p
+	// handshakes and node signatures: "local" (the default), "vault" for a
+	// Vault transit key or "remote" for an HTTP signer. No signer keeps the
+	// key out of the node: the DKG key agreement and the Tendermint validator
+	// use the raw node key, so it has to be configured with every signer.
+	Signer string `json:"signer"`
+	// SignerURL is the address of the remote signer.
+	SignerURL string `json:"signerUrl"`
+	// SignerTokenFile holds the shared secret the remote signer authenticates
+	// the node by. Without it, the secret is read from the secret manager, and
+	// swapped when it is rotated there.
+	SignerTokenFile string `json:"signerTokenFile"`
+	// SignerTransitMount and SignerTransitKey name the Vault transit key. The
+	// Vault connection is read from the secret config.
+	SignerTransitMount string `json:"signerTransitMount"`
+	SignerTransitKey   string `json:"signerTransitKey"`
+
+	// ChainConfirmations is the number of blocks a NodeList contract event
+	// has to be buried under before the node acts on it.
+	ChainConfirmations int `json:"chainConfirmations"`
+
+	// ShutdownTimeout is how long, in seconds, the node waits on shutdown for
+	// keygens and requests in flight before stopping the services.
+	ShutdownTimeout int `json:"shutdownTimeout"`
+
+	// KeyBufferPolicy overrides the per-curve key buffer policy, keyed by
+	// curve name. Fields left at zero are derived from the contract buffer
+	// size. The policy the nodes voted for with PUT /key-buffer/policy on the
+	// admin API takes precedence over both.
+	KeyBufferPolicy map[string]KeyBufferPolicy `json:"keyBufferPolicy"`
+
+	// TLSCertFile and TLSKeyFile enable TLS on the JSON-RPC server. The
+	// files are reread when they change, so certificates can be renewed in place.
+	TLSCertFile string `json:"tlsCertFile"`
+	TLSKeyFile  string `json:"tlsKeyFile"`
+	// TLSClientCAFile enables mTLS for ConnectionDetails: callers have to
+	// present a certificate issued by this CA. The node presents its own TLS
+	// certificate when it asks other nodes for their connection details.
+	TLSClientCAFile string `json:"tlsClientCAFile"`
+
+	// MaxRequestBytes caps the size of a JSON-RPC request body.
+	MaxRequestBytes int64 `json:"maxRequestBytes"`
+	// MaxBatchSize caps the number of requests in a JSON-RPC batch.
+	MaxBatchSize int `json:"maxBatchSize"`
+	// RateLimits throttles JSON-RPC methods, keyed by method name. The "*"
+	// entry applies to methods without their own entry. KeyCommitmentRequest
+	// is limited to 1 request per second per client IP, with a burst of 10,
+	// unless it has an entry.
+	RateLimits map[string]RateLimit `json:"rateLimits"`
+
+	// AdminListenAddress enables the admin API on this address, e.g.
+	// "127.0.0.1:5051". It is disabled when empty.
+	AdminListenAddress string `json:"adminListenAddress"`
+	// AdminOperators are the addresses whose signatures the admin API accepts.
+	AdminOperators []string `json:"adminOperators"`
+
+	// VerifiersFile declares further login methods, see VerifierDefinition.
+	VerifiersFile string `json:"verifiersFile"`
+
+	// TracingEndpoint is the host:port of an OTLP/HTTP collector that keygen
+	// traces are exported to. Tracing is off when empty.
+	TracingEndpoint string `json:"tracingEndpoint"`
+
+	// CacheBackend selects where the cache service keeps token commitments
+	// and lookups: "memory" (the default) or "leveldb", which keeps them
+	// across restarts in the data directory.
+	CacheBackend string `json:"cacheBackend"`
+	// CacheLimits overrides the TTL and size cap of cache namespaces, keyed
+	// by namespace: token, signer, verifier, partition.
+	CacheLimits map[string]CacheLimit `json:"cacheLimits"`
+
+	// LogLevel is the logrus level name, info by default.
+	LogLevel string `json:"logLevel"`
+
+	// Path is the file the config was read from, reread on SIGHUP.
+	Path string `json:"-"`
+}
+
+// KeyBufferPolicy controls how many pre-generated keys are kept for a curve
+// and how fast the buffer is refilled.
+type KeyBufferPolicy struct {
+	// Target is the number of unassigned keys the node tries to keep available.
+	Target int `json:"target"`
+	// MinRefill is the number of keygens started per block when demand is low.
+	MinRefill int `json:"minRefill"`
+	// MaxRefill caps the number of keygens started in a single block.
+	MaxRefill int `json:"maxRefill"`
+	// MaxConcurrent caps the number of keygens in flight at once.
+	MaxConcurrent int `json:"maxConcurrent"`
+}
+
+// CacheLimit bounds a cache namespace. Fields left at zero keep the default
+// of the namespace.
+type CacheLimit struct {
+	// TTL is how long entries are kept, in seconds.
+	TTL int `json:"ttl"`
+	// MaxEntries caps the entries kept. The least recently used entries are
+	// evicted beyond it, except in the token and signer namespaces, which
+	// guard against replays and refuse new entries until some expire.
+	MaxEntries int `json:"maxEntries"`
+}
+
+// VerifyRequired returns every invalid config value as a ValidationError.
+// RateLimit is a token bucket per client IP and per verifier. A zero rate
+// does not limit.
+type RateLimit struct {
+	// PerIP is the number of requests per second allowed from one client IP.
+	PerIP      float64 `json:"perIP"`
+	PerIPBurst int     `json:"perIPBurst"`
+	// PerVerifier is the number of requests per second allowed for one
+	// verifier, summed over all clients.
+	PerVerifier      float64 `json:"perVerifier"`
+	PerVerifierBurst int     `json:"perVerifierBurst"`
 }
 
 func (c *Config) VerifyRequired() error {
-	if c.RawPrivateKey == "" && c.SecretConfigPath == "" {
-		return errors.New("required secretConfigPath missing")
-	}
-	if c.IPAddress == "" {
-		return errors.New("required ipAddress missing")
+	if errs := c.Validate(); len(errs) > 0 {
+		return errs
 	}
 	return nil
 }
 
+// RPCURLs returns every configured blockchain RPC endpoint, EthConnection
+// first.
+func (c *Config) RPCURLs() []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, url := range append([]string{c.EthConnection}, c.EthConnections...) {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	return urls
+}
+
 func ConfigFromFile(configPath string) (*Config, error) {
-	config, err := ReadConfigJson(configPath)
+	config, err := ReadConfigFile(configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -60,24 +202,6 @@ func UseIPAdressInListenAddress(config *Config) {
 	config.P2PListenAddress = fmt.Sprintf("/ip4/%s/tcp/1080", config.IPAddress)
 }
 
-func ReadConfigJson(configPath string) (*Config, error) {
-	config := GetDefaultConfig()
-	log.Debugf("ConfigPath=%s", configPath)
-	f, err := os.OpenFile(configPath, os.O_RDONLY|os.O_SYNC, 0)
-	if err != nil {
-		log.WithError(err).Error("OpenConfigFile")
-		return nil, err
-	}
-	defer f.Close()
-
-	err = json.NewDecoder(f).Decode(config)
-	if err != nil {
-		log.WithError(err).Error("DecodeConfig")
-		return nil, fmt.Errorf("error reading config: %w", err)
-	}
-	return config, nil
-}
-
 func GetDefaultConfig() *Config {
 	config := &Config{
 		TMP2PListenAddress: "tcp://0.0.0.0:26656",
@@ -89,30 +213,59 @@ func GetDefaultConfig() *Config {
 		PasswordlessUrl:    DefaultPasswordlessUrl,
 		OAuthUrl:           DefaultOAuthUrl,
 		GlobalKeyCertPool:  DefaultGlobalKeyCertPool,
+		ChainConfirmations: DefaultChainConfirmations,
+		ShutdownTimeout:    DefaultShutdownTimeout,
+		LogLevel:           DefaultLogLevel,
+		MaxRequestBytes:    DefaultMaxRequestBytes,
+		MaxBatchSize:       DefaultMaxBatchSize,
 	}
 	return config
 }
 
 func GetNodePrivateKey(configPath string) (key []byte, err error) {
-	return GetSecretFromVault(configPath, secret.NodeKey)
+	return GetSecret(configPath, secret.NodeKey)
 }
 
 func GetTendermintPrivateKey(configPath string) (key []byte, err error) {
-	return GetSecretFromVault(configPath, secret.TendermintKey)
+	return GetSecret(configPath, secret.TendermintKey)
 }
 
-func GetSecretFromVault(configPath, keyType string) ([]byte, error) {
-	c, err := secret.ReadConfig(configPath)
+var (
+	secretManagersMu sync.Mutex
+	// secretManagers are kept open so that vault tokens stay renewed.
+	secretManagers = make(map[string]secret.SecretManager)
+)
+
+func secretManager(configPath string) (secret.SecretManager, error) {
+	secretManagersMu.Lock()
+	defer secretManagersMu.Unlock()
+	if manager, ok := secretManagers[configPath]; ok {
+		return manager, nil
+	}
+	manager, err := backend.FromConfigFile(configPath)
 	if err != nil {
 		return nil, err
 	}
+	secretManagers[configPath] = manager
+	return manager, nil
+}
 
-	manager, err := vault.NewVaultManager(c)
+// WatchSecret calls onChange when the secret is rotated in the secret
+// manager. Managers that cannot tell are not watched.
+func WatchSecret(configPath, keyType string, onChange func(value []byte)) error {
+	manager, err := secretManager(configPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	watcher, ok := manager.(secret.Watcher)
+	if !ok {
+		return nil
+	}
+	return watcher.Watch(keyType, onChange)
+}
 
-	err = manager.Setup()
+func GetSecret(configPath, keyType string) ([]byte, error) {
+	manager, err := secretManager(configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -1 +1,189 @@
 package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testJSON = `{"ipAddress": "10.0.0.1", "secretConfigPath": "/secret.json", "chainQuorum": 2,
+"blockchainRPCURLs": ["http://a", "http://b"], "keyBufferPolicy": {"secp256k1": {"target": 50}}}`
+	testYAML = `ipAddress: 10.0.0.1
+secretConfigPath: /secret.json
+chainQuorum: 2
+blockchainRPCURLs: [http://a, http://b]
+keyBufferPolicy:
+  secp256k1:
+    target: 50
+`
+	testTOML = `ipAddress = "10.0.0.1"
+secretConfigPath = "/secret.json"
+chainQuorum = 2
+blockchainRPCURLs = ["http://a", "http://b"]
+
+[keyBufferPolicy.secp256k1]
+target = 50
+`
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadConfigFileFormats(t *testing.T) {
+	files := map[string]string{
+		"config.json": testJSON,
+		"config.yaml": testYAML,
+		"config.toml": testTOML,
+	}
+	for name, content := range files {
+		c, err := ReadConfigFile(writeConfig(t, name, content))
+		if err != nil {
+			t.Fatalf("ReadConfigFile(%s) error = %v", name, err)
+		}
+		if c.IPAddress != "10.0.0.1" || c.SecretConfigPath != "/secret.json" || c.ChainQuorum != 2 {
+			t.Errorf("ReadConfigFile(%s) = %+v", name, c)
+		}
+		if !reflect.DeepEqual(c.EthConnections, []string{"http://a", "http://b"}) {
+			t.Errorf("ReadConfigFile(%s) rpc urls = %v", name, c.EthConnections)
+		}
+		if c.KeyBufferPolicy["secp256k1"].Target != 50 {
+			t.Errorf("ReadConfigFile(%s) buffer policy = %+v", name, c.KeyBufferPolicy)
+		}
+		if c.ShutdownTimeout != DefaultShutdownTimeout {
+			t.Errorf("ReadConfigFile(%s) dropped the defaults", name)
+		}
+	}
+}
+
+func TestEnvOverridesConfigFile(t *testing.T) {
+	t.Setenv("DKG_IP_ADDRESS", "10.0.0.2")
+	t.Setenv("DKG_CHAIN_QUORUM", "1")
+	t.Setenv("DKG_BLOCKCHAIN_RPCURLS", "http://c, http://d")
+	t.Setenv("DKG_KEY_BUFFER_POLICY", `{"ed25519": {"maxRefill": 4}}`)
+
+	c, err := ReadConfigFile(writeConfig(t, "config.yaml", testYAML))
+	if err != nil {
+		t.Fatalf("ReadConfigFile() error = %v", err)
+	}
+	if c.IPAddress != "10.0.0.2" || c.ChainQuorum != 1 {
+		t.Errorf("env overrides not applied: %+v", c)
+	}
+	if !reflect.DeepEqual(c.EthConnections, []string{"http://c", "http://d"}) {
+		t.Errorf("rpc urls = %v", c.EthConnections)
+	}
+	if _, ok := c.KeyBufferPolicy["secp256k1"]; ok || c.KeyBufferPolicy["ed25519"].MaxRefill != 4 {
+		t.Errorf("buffer policy = %+v", c.KeyBufferPolicy)
+	}
+
+	t.Setenv("DKG_CHAIN_QUORUM", "two")
+	if _, err := ReadConfigFile(writeConfig(t, "config.yaml", testYAML)); err == nil {
+		t.Error("ReadConfigFile() accepted an invalid DKG_CHAIN_QUORUM")
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	c := GetDefaultConfig()
+	c.IPAddress = "node.example"
+	c.HttpServerPort = "http"
+	c.Signer = "hsm"
+	c.LogLevel = "loud"
+	c.ChainQuorum = 3
+	c.EthConnection = "ftp://rpc"
+	c.ContractAddress = "0x1234"
+	c.KeyBufferPolicy = map[string]KeyBufferPolicy{"secp256k1": {MinRefill: 5, MaxRefill: 2}}
+
+	if errs := c.Validate(); len(errs) != 9 {
+		t.Errorf("Validate() = %d errors, want 9: %v", len(errs), errs)
+	}
+	if c.VerifyRequired() == nil {
+		t.Error("VerifyRequired() accepted an invalid config")
+	}
+
+	c = GetDefaultConfig()
+	c.IPAddress = "10.0.0.1"
+	c.SecretConfigPath = "/secret.json"
+	c.EthConnection = "https://rpc"
+	c.ContractAddress = "0x0000000000000000000000000000000000000001"
+	if err := c.VerifyRequired(); err != nil {
+		t.Errorf("VerifyRequired() error = %v", err)
+	}
+}
+
+func TestReloadKeepsValuesThatNeedRestart(t *testing.T) {
+	path := writeConfig(t, "config.yaml", testYAML+"oauthUrl: https://old\n")
+	c, err := ReadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	GlobalConfig = c
+	defer func() { GlobalConfig = nil }()
+	var reloaded *Config
+	OnReload(func(_, new *Config) { reloaded = new })
+
+	changed := `ipAddress: 10.0.0.9
+secretConfigPath: /secret.json
+blockchainRPCURL: https://rpc
+dkgContractAddress: "0x0000000000000000000000000000000000000001"
+oauthUrl: https://new
+logLevel: debug
+`
+	if err := os.WriteFile(path, []byte(changed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	current := Current()
+	if reloaded != current {
+		t.Error("reload hook was not called with the new config")
+	}
+	if current.OAuthUrl != "https://new" || current.LogLevel != "debug" {
+		t.Errorf("reloadable values not applied: %+v", current)
+	}
+	if current.IPAddress != "10.0.0.1" || current.ChainQuorum != 2 {
+		t.Errorf("values that need a restart were changed: %+v", current)
+	}
+}
+
+func TestReloadUsesFlagsAndEnv(t *testing.T) {
+	// The file leaves the ip address to the flags and the rpc url to the
+	// environment, so it does not validate on its own.
+	file := `secretConfigPath: /secret.json
+dkgContractAddress: "0x0000000000000000000000000000000000000001"
+logLevel: info
+`
+	path := writeConfig(t, "config.yaml", file)
+	t.Setenv("DKG_BLOCKCHAIN_RPCURL", "https://rpc")
+	SetFlagOverrides(func(c *Config) error {
+		c.IPAddress = "10.0.0.1"
+		return nil
+	})
+	defer SetFlagOverrides(nil)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VerifyRequired(); err != nil {
+		t.Fatalf("VerifyRequired() error = %v", err)
+	}
+	GlobalConfig = c
+	defer func() { GlobalConfig = nil }()
+
+	if err := os.WriteFile(path, []byte(strings.Replace(file, "info", "debug", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if current := Current(); current.LogLevel != "debug" || current.IPAddress != "10.0.0.1" {
+		t.Errorf("Current() = %+v, want the new log level and the flag ip address", current)
+	}
+}
@@ -8,3 +8,18 @@ var (
 	DefaultOAuthUrl          = ""
 	DefaultGlobalKeyCertPool = ""
 )
+
+// DefaultShutdownTimeout is the default ShutdownTimeout, in seconds.
+const DefaultShutdownTimeout = 30
+
+// DefaultChainConfirmations is the default ChainConfirmations.
+const DefaultChainConfirmations = 6
+
+// DefaultLogLevel is the default LogLevel.
+const DefaultLogLevel = "info"
+
+// DefaultMaxRequestBytes is the default MaxRequestBytes.
+const DefaultMaxRequestBytes = 1 << 20
+
+// DefaultMaxBatchSize is the default MaxBatchSize.
+const DefaultMaxBatchSize = 20
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variables that override config values.
+// The rest of the name is the JSON key in upper snake case, for example
+// DKG_DATA_DIRECTORY for dataDirectory.
+const EnvPrefix = "DKG_"
+
+// flagOverrides sets the command line flags the node was started with.
+var flagOverrides func(config *Config) error
+
+// SetFlagOverrides registers the function that sets the command line flags
+// on a config read by Load.
+func SetFlagOverrides(apply func(config *Config) error) {
+	flagOverrides = apply
+}
+
+// Load reads a config file the way the node is configured: the defaults,
+// the file, the DKG_ environment variables and the command line flags, each
+// taking precedence over the ones before.
+func Load(configPath string) (*Config, error) {
+	config, err := ReadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if flagOverrides != nil {
+		if err := flagOverrides(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// ReadConfigFile reads a JSON, YAML or TOML config, chosen by the file
+// extension, over the defaults and then applies the environment overrides.
+// YAML and TOML files use the same keys as the JSON config.
+func ReadConfigFile(configPath string) (*Config, error) {
+	config := GetDefaultConfig()
+	log.Debugf("ConfigPath=%s", configPath)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		log.WithError(err).Error("OpenConfigFile")
+		return nil, err
+	}
+	if err := decodeFile(configPath, data, config); err != nil {
+		log.WithError(err).Error("DecodeConfig")
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if err := ApplyEnv(config); err != nil {
+		return nil, err
+	}
+	config.Path = configPath
+	return config, nil
+}
+
+// decodeFile decodes a JSON, YAML or TOML file, chosen by its extension,
+// into v.
+func decodeFile(path string, data []byte, v interface{}) error {
+	var values map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", "":
+		return json.Unmarshal(data, v)
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown config format %q", ext)
+	}
+	// Going through JSON keeps the json tags the only key names to maintain.
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ApplyEnv overrides config values with the DKG_ environment variables that
+// are set. Lists are comma separated and maps are given as JSON.
+func ApplyEnv(config *Config) error {
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := EnvName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable of a config field, or "" when the
+// field is not read from the config file.
+func EnvName(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("json"), ",")[0]
+	if key == "" || key == "-" {
+		return ""
+	}
+	return EnvPrefix + upperSnake(key)
+}
+
+// upperSnake splits camel case words, keeping acronyms together:
+// blockchainRPCURL becomes BLOCKCHAIN_RPCURL.
+func upperSnake(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		var values []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		parsed := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+			return err
+		}
+		field.Set(parsed.Elem())
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	reloadMu sync.RWMutex
+	// reloadHooks are called after every successful reload.
+	reloadHooks []func(old, new *Config)
+)
+
+// reloadable are the JSON keys of the values that take effect without a
+// restart. Other changes are reported and ignored until the node restarts.
+var reloadable = map[string]bool{
+	"logLevel":        true,
+	"keyBufferPolicy": true,
+	"gatewayUrl":      true,
+	"passwordlessUrl": true,
+	"oauthUrl":        true,
+	"rateLimits":      true,
+	"maxRequestBytes": true,
+	"maxBatchSize":    true,
+	"adminOperators":  true,
+	"verifiersFile":   true,
+}
+
+// Current returns the running config. It has to be used instead of reading
+// GlobalConfig, which a reload replaces.
+func Current() *Config {
+	reloadMu.RLock()
+	defer reloadMu.RUnlock()
+	return GlobalConfig
+}
+
+// Set makes c the running config.
+func Set(c *Config) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	GlobalConfig = c
+}
+
+// OnReload registers a hook that is called with the previous and the new
+// config after a reload.
+func OnReload(hook func(old, new *Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload rereads the config file the node was started with, under the same
+// environment and flags. Only the reloadable values are taken over, the
+// running config is otherwise kept.
+func Reload() error {
+	old := Current()
+	if old == nil || old.Path == "" {
+		return errors.New("node was not started from a config file")
+	}
+	next, err := Load(old.Path)
+	if err != nil {
+		return err
+	}
+	if errs := next.Validate(); len(errs) > 0 {
+		return errs
+	}
+
+	updated := *old
+	ov, nv, uv := reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem(), reflect.ValueOf(&updated).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Type().Field(i)
+		key := field.Tag.Get("json")
+		if key == "" || key == "-" || reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		if !reloadable[key] {
+			log.WithField("key", key).Warn("config value changed, restart the node to use it")
+			continue
+		}
+		uv.Field(i).Set(nv.Field(i))
+		log.WithField("key", key).Info("config value reloaded")
+	}
+
+	reloadMu.Lock()
+	GlobalConfig = &updated
+	hooks := append([]func(old, new *Config){}, reloadHooks...)
+	reloadMu.Unlock()
+	for _, hook := range hooks {
+		hook(old, &updated)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+)
+
+// ValidationError lists every invalid value of a config.
+type ValidationError []error
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks the whole config and returns every problem found, rather
+// than stopping at the first one.
+func (c *Config) Validate() ValidationError {
+	var errs ValidationError
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.RawPrivateKey == "" && c.SecretConfigPath == "" {
+		add(errors.New("required secretConfigPath missing"))
+	}
+	if c.IPAddress == "" {
+		add(errors.New("required ipAddress missing"))
+	} else if net.ParseIP(c.IPAddress) == nil {
+		add(fmt.Errorf("ipAddress %q is not an ip address", c.IPAddress))
+	}
+	if c.HttpServerPort != "" {
+		if port, err := strconv.Atoi(c.HttpServerPort); err != nil || port < 1 || port > 65535 {
+			add(fmt.Errorf("port %q is not a valid port", c.HttpServerPort))
+		}
+	}
+	add(c.verifySigner())
+	add(c.verifyNodeRegistry())
+	for _, err := range c.verifyChain() {
+		add(err)
+	}
+	add(verifyURL("gatewayUrl", c.GatewayURL))
+	add(verifyURL("passwordlessUrl", c.PasswordlessUrl))
+	add(verifyURL("oauthUrl", c.OAuthUrl))
+	if c.ShutdownTimeout < 0 {
+		add(errors.New("shutdownTimeout must not be negative"))
+	}
+	if c.LogLevel != "" {
+		if _, err := log.ParseLevel(c.LogLevel); err != nil {
+			add(fmt.Errorf("logLevel: %w", err))
+		}
+	}
+	for curve, policy := range c.KeyBufferPolicy {
+		add(policy.Verify(curve))
+	}
+	add(c.verifyServer())
+	add(c.verifyAdmin())
+	if c.VerifiersFile != "" {
+		if _, err := ReadVerifiersFile(c.VerifiersFile); err != nil {
+			add(fmt.Errorf("verifiersFile: %w", err))
+		}
+	}
+	if c.TracingEndpoint != "" {
+		if _, _, err := net.SplitHostPort(c.TracingEndpoint); err != nil {
+			add(fmt.Errorf("tracingEndpoint %q is invalid: %w", c.TracingEndpoint, err))
+		}
+	}
+	for method, limit := range c.RateLimits {
+		add(limit.verify(method))
+	}
+	switch c.CacheBackend {
+	case "", "memory", "leveldb":
+	default:
+		add(fmt.Errorf("cacheBackend %q is not memory or leveldb", c.CacheBackend))
+	}
+	for namespace, limit := range c.CacheLimits {
+		if limit.TTL < 0 || limit.MaxEntries < 0 {
+			add(fmt.Errorf("cacheLimits.%s must not have negative values", namespace))
+		}
+	}
+	return errs
+}
+
+func (c *Config) verifySigner() error {
+	switch c.Signer {
+	case "", "local":
+	case "remote":
+		if c.SignerURL == "" {
+			return errors.New("required signerUrl missing")
+		}
+		if c.SignerTokenFile == "" && c.SecretConfigPath == "" {
+			return errors.New("required signerTokenFile missing")
+		}
+		return verifyURL("signerUrl", c.SignerURL)
+	case "vault":
+		if c.SignerTransitKey == "" {
+			return errors.New("required signerTransitKey missing")
+		}
+	default:
+		return fmt.Errorf("unknown signer %q", c.Signer)
+	}
+	return nil
+}
+
+func (c *Config) verifyNodeRegistry() error {
+	switch c.NodeRegistry {
+	case "", "contract":
+		return nil
+	case "file":
+	default:
+		return fmt.Errorf("unknown nodeRegistry %q", c.NodeRegistry)
+	}
+	if c.NodeRegistryFile == "" {
+		return errors.New("required nodeRegistryFile missing")
+	}
+	if c.NodeRegistryAdmin == "" {
+		return errors.New("required nodeRegistryAdmin missing")
+	}
+	if !ethCommon.IsHexAddress(c.NodeRegistryAdmin) {
+		return fmt.Errorf("nodeRegistryAdmin %q is not an address", c.NodeRegistryAdmin)
+	}
+	return nil
+}
+
+func (c *Config) verifyChain() []error {
+	var errs []error
+	urls := c.RPCURLs()
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("blockchainRPCURL %q: %w", u, err))
+			continue
+		}
+		switch parsed.Scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			errs = append(errs, fmt.Errorf("blockchainRPCURL %q must be an http or websocket url", u))
+		}
+	}
+	if c.ChainQuorum < 0 || (len(urls) > 0 && c.ChainQuorum > len(urls)) {
+		errs = append(errs, fmt.Errorf("chainQuorum %d must be between 0 and the %d rpc urls", c.ChainQuorum, len(urls)))
+	}
+	if c.ChainConfirmations < 0 {
+		errs = append(errs, errors.New("chainConfirmations must not be negative"))
+	}
+	if c.NodeRegistry == "file" {
+		return errs
+	}
+	if len(urls) == 0 {
+		errs = append(errs, errors.New("required blockchainRPCURL missing"))
+	}
+	if !ethCommon.IsHexAddress(c.ContractAddress) {
+		errs = append(errs, fmt.Errorf("dkgContractAddress %q is not an address", c.ContractAddress))
+	}
+	return errs
+}
+
+func verifyURL(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := url.ParseRequestURI(value); err != nil {
+		return fmt.Errorf("%s %q is not a url", name, value)
+	}
+	return nil
+}
+
+// Verify checks the limits of the policy of a curve. Policies voted by the
+// nodes are checked with it too.
+func (p KeyBufferPolicy) Verify(curve string) error {
+	if p.Target < 0 || p.MinRefill < 0 || p.MaxRefill < 0 || p.MaxConcurrent < 0 {
+		return fmt.Errorf("keyBufferPolicy.%s must not have negative values", curve)
+	}
+	if p.MaxRefill > 0 && p.MinRefill > p.MaxRefill {
+		return fmt.Errorf("keyBufferPolicy.%s minRefill is above maxRefill", curve)
+	}
+	return nil
+}
+
+func (c *Config) verifyAdmin() error {
+	if c.AdminListenAddress == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.AdminListenAddress); err != nil {
+		return fmt.Errorf("adminListenAddress %q is invalid: %w", c.AdminListenAddress, err)
+	}
+	if len(c.AdminOperators) == 0 {
+		return errors.New("adminOperators is required when the admin API is enabled")
+	}
+	for _, operator := range c.AdminOperators {
+		if !ethCommon.IsHexAddress(operator) {
+			return fmt.Errorf("adminOperators entry %q is not an address", operator)
+		}
+	}
+	return nil
+}
+
+func (c *Config) verifyServer() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("tlsCertFile and tlsKeyFile have to be set together")
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		return errors.New("tlsClientCAFile requires tlsCertFile")
+	}
+	if c.MaxRequestBytes < 0 {
+		return errors.New("maxRequestBytes must not be negative")
+	}
+	if c.MaxBatchSize < 0 {
+		return errors.New("maxBatchSize must not be negative")
+	}
+	return nil
+}
+
+func (l RateLimit) verify(method string) error {
+	if l.PerIP < 0 || l.PerIPBurst < 0 || l.PerVerifier < 0 || l.PerVerifierBurst < 0 {
+		return fmt.Errorf("rateLimits.%s must not have negative values", method)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Token types of declared verifiers.
+const (
+	// VerifierJWT tokens are checked against the keys of their issuer.
+	VerifierJWT = "jwt"
+	// VerifierUserinfo tokens are sent to a userinfo endpoint.
+	VerifierUserinfo = "userinfo"
+	// VerifierIntrospection tokens are checked with an RFC 7662
+	// introspection endpoint.
+	VerifierIntrospection = "introspection"
+)
+
+// VerifierDefinition declares a login method, so that one can be added
+// without code.
+type VerifierDefinition struct {
+	// ID is the provider name apps register verifiers under.
+	ID   string `json:"id"`
+	Type string `json:"type"`
+
+	// Issuer is the iss of jwt tokens. Its keys are found through OIDC
+	// discovery unless JWKSURL is set.
+	Issuer  string `json:"issuer"`
+	JWKSURL string `json:"jwksUrl"`
+	// Audience is the aud jwt tokens have to carry, the client ID of the
+	// app when empty.
+	Audience string `json:"audience"`
+
+	// URL is the userinfo or introspection endpoint. "{client_id}" and
+	// "{domain}" are replaced with the verifier params of the app.
+	URL string `json:"url"`
+	// ClientID and the secret in the ClientSecretEnv environment variable
+	// authenticate the node to the introspection endpoint.
+	ClientID        string `json:"clientId"`
+	ClientSecretEnv string `json:"clientSecretEnv"`
+
+	// UserIDPath is the claim, or dotted path into the endpoint response,
+	// that holds the verifier ID.
+	UserIDPath string `json:"userIdPath"`
+	// ClientIDPath is the dotted path into the endpoint response that holds
+	// the client ID the token was issued to, which has to be the client ID
+	// of the app. Userinfo verifiers must set it; introspection responses
+	// are checked on client_id or aud when it is empty.
+	ClientIDPath string `json:"clientIdPath"`
+	// RequiredClaims are claims, or paths, that must have these values.
+	RequiredClaims map[string]interface{} `json:"requiredClaims"`
+
+	// LeewaySeconds is the clock skew allowed on jwt time claims.
+	LeewaySeconds int `json:"leewaySeconds"`
+	// MaxAgeSeconds rejects jwt tokens issued longer ago, if set.
+	MaxAgeSeconds int `json:"maxAgeSeconds"`
+}
+
+type verifiersFile struct {
+	Verifiers []VerifierDefinition `json:"verifiers"`
+}
+
+// ReadVerifiersFile reads the verifier definitions from a JSON, YAML or TOML
+// file with a top level "verifiers" list.
+func ReadVerifiersFile(path string) ([]VerifierDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file verifiersFile
+	if err := decodeFile(path, data, &file); err != nil {
+		return nil, fmt.Errorf("error reading verifiers: %w", err)
+	}
+	ids := make(map[string]bool)
+	for i, def := range file.Verifiers {
+		if err := def.verify(); err != nil {
+			return nil, fmt.Errorf("verifiers[%d]: %w", i, err)
+		}
+		if ids[def.ID] {
+			return nil, fmt.Errorf("verifiers[%d]: id %q is declared twice", i, def.ID)
+		}
+		ids[def.ID] = true
+	}
+	return file.Verifiers, nil
+}
+
+func (d VerifierDefinition) verify() error {
+	if d.ID == "" {
+		return errors.New("id missing")
+	}
+	if d.UserIDPath == "" {
+		return errors.New("userIdPath missing")
+	}
+	if d.LeewaySeconds < 0 || d.MaxAgeSeconds < 0 {
+		return errors.New("leewaySeconds and maxAgeSeconds must not be negative")
+	}
+	switch d.Type {
+	case VerifierJWT:
+		if d.Issuer == "" {
+			return errors.New("issuer missing")
+		}
+		return verifyURL("jwksUrl", d.JWKSURL)
+	case VerifierUserinfo, VerifierIntrospection:
+		if d.URL == "" {
+			return errors.New("url missing")
+		}
+		if d.Type == VerifierIntrospection && d.ClientID == "" {
+			return errors.New("clientId missing")
+		}
+		if d.Type == VerifierUserinfo && d.ClientIDPath == "" {
+			return errors.New("clientIdPath missing")
+		}
+		return verifyURL("url", d.URL)
+	}
+	return fmt.Errorf("unknown type %q", d.Type)
+}
@@ -86,9 +86,15 @@ func SignData(data []byte, ecdsaKey *ecdsa.PrivateKey) Signature {
 		log.Fatal(err)
 	}
 
+	return SignatureFromRaw(data, signature)
+}
+
+// SignatureFromRaw wraps a raw [R || S || V] signature of the keccak256 hash
+// of data, as returned by ChainMethods.SelfSignData.
+func SignatureFromRaw(data []byte, signature []byte) Signature {
 	return Signature{
 		signature,
-		bytes32(hashRaw),
+		bytes32(secp256k1.Keccak256(data)),
 		bytes32(signature[:32]),
 		bytes32(signature[32:64]),
 		uint8(int(signature[64])) + 27, // Yes add 27, weird Ethereum quirk
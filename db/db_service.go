@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	eth "github.com/ethereum/go-ethereum/common"
 
@@ -12,6 +13,7 @@ import (
 
 type DBService struct {
 	dbInstance *DBWrapper
+	running    atomic.Bool
 }
 
 func New() *DBService {
@@ -22,20 +24,26 @@ func (*DBService) ID() string {
 	return common.DB_SERVICE_NAME
 }
 func (service *DBService) Start() error {
-	dbPath := fmt.Sprintf("%s/keygendb", config.GlobalConfig.BasePath)
+	dbPath := fmt.Sprintf("%s/keygendb", config.Current().BasePath)
 	db, err := NewDB(dbPath)
 	if err != nil {
 		return err
 	}
 	service.dbInstance = db
+	service.running.Store(true)
 	return nil
 }
 
+// Stop closes the database, so that a restart can open it again.
 func (service *DBService) Stop() error {
-	return nil
+	service.running.Store(false)
+	if service.dbInstance == nil {
+		return nil
+	}
+	return service.dbInstance.Close()
 }
 func (service *DBService) IsRunning() bool {
-	return true
+	return service.running.Load()
 }
 
 func (d *DBService) Call(method string, args ...interface{}) (interface{}, error) {
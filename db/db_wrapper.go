@@ -49,6 +49,10 @@ func NewDB(path string) (*DBWrapper, error) {
 	}
 	return &DBWrapper{db: db}, nil
 }
+func (t *DBWrapper) Close() error {
+	return t.db.Close()
+}
+
 func (t *DBWrapper) RetrieveCompletedShare(keyIndex big.Int, curve common.CurveName) (*big.Int, *big.Int, error) {
 	keyIndexBytes := keyIndex.Bytes()
 	completedShareKey := append(completedPSSShareBytes, keyIndexBytes...)
@@ -189,7 +189,9 @@ func (bus *EventBus) removeHandler(topic string, idx int) {
 func (bus *EventBus) findHandlerIdx(topic string, callback reflect.Value) int {
 	if _, ok := bus.handlers[topic]; ok {
 		for idx, handler := range bus.handlers[topic] {
-			if reflect.ValueOf(handler.callBack) == reflect.ValueOf(callback) {
+			// Compares the Value structs themselves, which for funcs tells
+			// closures of the same literal apart.
+			if handler.callBack == callback {
 				return idx
 			}
 		}
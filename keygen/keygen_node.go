@@ -14,9 +14,11 @@ import (
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/acss"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/keyderivation"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/keyset"
+	"github.com/arcana-network/dkgnode/telemetry"
 	"github.com/coinbase/kryptology/pkg/core/curves"
 	log "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type KeygenNode struct {
@@ -75,6 +77,8 @@ func (node *KeygenNode) Params() (n, k, t int) {
 func (node *KeygenNode) cleanup(id common.ADKGID) {
 	node.cleanupKeygenStore(id)
 	node.cleanupSessionStore(id)
+	node.tracker.Remove(id)
+	telemetry.EndSession(string(id))
 }
 
 func (node *KeygenNode) remove(id common.ADKGID) {
@@ -93,6 +97,7 @@ func (node *KeygenNode) remove(id common.ADKGID) {
 		node.state.ABAStore.Delete(keysetID)
 	}
 	node.state.SessionStore.Delete(id)
+	telemetry.EndSession(string(id))
 }
 
 func (node *KeygenNode) Cleanup(id common.ADKGID) {
@@ -120,6 +125,67 @@ func (node *KeygenNode) cleanupSessionStore(id common.ADKGID) {
 	node.state.SessionStore.Complete(id)
 }
 
+// Sessions reports the progress of the sessions in progress.
+func (node *KeygenNode) Sessions() []common.SessionStatus {
+	started := node.tracker.StartedAt()
+	ids := make(map[common.ADKGID]bool)
+	for id := range started {
+		ids[id] = true
+	}
+	node.state.SessionStore.Map.Range(func(key, value interface{}) bool {
+		if session, _ := value.(*common.ADKGSession); session != nil {
+			ids[key.(common.ADKGID)] = true
+		}
+		return true
+	})
+
+	sessions := []common.SessionStatus{}
+	for id := range ids {
+		status := common.SessionStatus{
+			ID:        id,
+			StartedAt: started[id],
+			ABARounds: make(map[int]int),
+			Decisions: make(map[int]int),
+		}
+		if session, found := node.state.SessionStore.Get(id); found && session != nil {
+			session.Lock()
+			status.ACSSComplete = len(session.S)
+			status.TPrime = session.TPrime
+			status.ABAStarted = append([]int{}, session.ABAStarted...)
+			status.ABAComplete = session.ABAComplete
+			for dealer, decision := range session.Decisions {
+				status.Decisions[dealer] = decision
+			}
+			status.KeyderivationStarted = session.KeyderivationStarted
+			status.BFTDecided = session.BFTDecided
+			status.Over = session.Over
+			session.Unlock()
+		}
+		for _, n := range node.CurrentNodes.Nodes {
+			keysetID := (&common.RoundDetails{ADKGID: id, Dealer: n.Index, Kind: "keyset"}).ID()
+			if aba, found := node.state.ABAStore.Get(keysetID); found && aba != nil {
+				aba.Lock()
+				status.ABARounds[n.Index] = aba.Round
+				aba.Unlock()
+			}
+		}
+		sessions = append(sessions, status)
+	}
+	return sessions
+}
+
+// CancelSession drops a stuck session. Messages still arriving for it are
+// ignored, as for completed sessions.
+func (node *KeygenNode) CancelSession(id common.ADKGID) error {
+	session, found := node.state.SessionStore.Get(id)
+	if !node.tracker.Has(id) && (!found || session == nil) {
+		return fmt.Errorf("session %s not found", id)
+	}
+	log.WithField("id", id).Warn("cancelling keygen session")
+	node.cleanup(id)
+	return nil
+}
+
 func (node *KeygenNode) BFTDecided(id common.ADKGID) {
 	store, complete := node.state.SessionStore.GetOrSetIfNotComplete(id, common.DefaultADKGSession())
 	if complete {
@@ -127,6 +193,7 @@ func (node *KeygenNode) BFTDecided(id common.ADKGID) {
 	}
 	store.Lock()
 	defer store.Unlock()
+	telemetry.EndPhase(string(id), telemetry.PhaseBFTDecision, "")
 	if store.Over {
 		keyIndex, err := id.GetIndex()
 		if err != nil {
@@ -496,18 +563,57 @@ func (node *KeygenNode) ProcessMessage(sender common.KeygenNodeDetails, keygenMe
 		"RoundID":  keygenMessage.RoundID,
 	}).Debug("KeygenNode:ProcessMessage()")
 
-	switch {
-	case strings.HasPrefix(keygenMessage.Method, "acss"):
+	kind := messageKind(keygenMessage.Method)
+	if kind == "" {
+		log.Infof("No handler found. MsgType=%s", keygenMessage.Method)
+		return fmt.Errorf("KeygenMessage method %v not found", keygenMessage.Method)
+	}
+	if span := node.observeMessage(kind, sender, keygenMessage); span != nil {
+		defer span.End()
+	}
+
+	switch kind {
+	case "acss":
 		node.processACSSMessages(sender, keygenMessage)
-	case strings.HasPrefix(keygenMessage.Method, "keyset"):
+	case "keyset":
 		node.processKeysetMessages(sender, keygenMessage)
-	case strings.HasPrefix(keygenMessage.Method, "aba"):
+	case "aba":
 		node.processABAMessages(sender, keygenMessage)
-	case strings.HasPrefix(keygenMessage.Method, "key_derivation"):
+	case "key_derivation":
 		node.processKeyDerivationMessages(sender, keygenMessage)
-	default:
-		log.Infof("No handler found. MsgType=%s", keygenMessage.Method)
-		return fmt.Errorf("KeygenMessage method %v not found", keygenMessage.Method)
 	}
 	return nil
 }
+
+var messageKinds = []string{"acss", "keyset", "aba", "key_derivation"}
+
+func messageKind(method string) string {
+	for _, kind := range messageKinds {
+		if strings.HasPrefix(method, kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// observeMessage counts a received message and starts the span of its
+// processing. The first ACSS and keyset messages of a dealer start the
+// phases timed for it. Messages of completed sessions are only counted.
+func (node *KeygenNode) observeMessage(kind string, sender common.KeygenNodeDetails, msg common.DKGMessage) trace.Span {
+	telemetry.CountKeygenMessage(kind, msg.Method, sender.Index)
+	round := common.RoundDetails{}
+	if err := round.FromID(msg.RoundID); err != nil {
+		return nil
+	}
+	if session, found := node.state.SessionStore.Get(round.ADKGID); found && session == nil {
+		return nil
+	}
+	session := string(round.ADKGID)
+	switch kind {
+	case "acss":
+		telemetry.StartPhase(session, telemetry.PhaseACSS, strconv.Itoa(round.Dealer))
+	case "keyset":
+		telemetry.StartPhase(session, telemetry.PhaseKeyset, strconv.Itoa(round.Dealer))
+	}
+	return telemetry.StartReceiveSpan(session, msg.Method, sender.Index, msg.Trace)
+}
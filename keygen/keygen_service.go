@@ -1,8 +1,10 @@
 package keygen
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/arcana-network/dkgnode/eventbus"
 	"github.com/coinbase/kryptology/pkg/core/curves"
@@ -17,6 +19,7 @@ type KeygenService struct {
 	bus        eventbus.Bus
 	broker     *common.MessageBroker
 	KeygenNode *KeygenNode
+	running    atomic.Bool
 }
 
 func New(bus eventbus.Bus) *KeygenService {
@@ -31,12 +34,25 @@ func (*KeygenService) ID() string {
 	return common.KEYGEN_SERVICE_NAME
 }
 
+func (*KeygenService) Dependencies() []string {
+	return []string{common.P2P_SERVICE_NAME, common.CHAIN_SERVICE_NAME, common.DB_SERVICE_NAME}
+}
+
 func (service *KeygenService) Start() error {
 	ChainMethods := service.broker.ChainMethods()
 	selfIndex := ChainMethods.GetSelfIndex()
-	selfPubKey := ChainMethods.GetSelfPublicKey()
-	currEpoch := ChainMethods.GetCurrentEpoch()
-	currNodeList := ChainMethods.AwaitCompleteNodeList(currEpoch)
+	selfPubKey, err := ChainMethods.GetSelfPublicKey()
+	if err != nil {
+		return err
+	}
+	currEpoch, err := ChainMethods.GetCurrentEpoch()
+	if err != nil {
+		return err
+	}
+	currNodeList, err := ChainMethods.AwaitCompleteNodeList(currEpoch)
+	if err != nil {
+		return err
+	}
 	currEpochInfo, err := ChainMethods.GetEpochInfo(currEpoch, true)
 	if err != nil {
 		return err
@@ -66,6 +82,7 @@ func (service *KeygenService) Start() error {
 	}
 
 	service.KeygenNode = keygenNode
+	service.running.Store(true)
 	return nil
 }
 
@@ -114,6 +131,15 @@ func (service *KeygenService) Call(method string, args ...interface{}) (interfac
 			"type":  args0.Method,
 		}).Debug("Broker:ReceiveMessage()")
 		return nil, service.KeygenNode.Transport.Receive(details, args0)
+	case "sessions":
+		return service.KeygenNode.Sessions(), nil
+	case "cancel_session":
+		var id common.ADKGID
+		err := common.CastOrUnmarshal(args[0], &id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.KeygenNode.CancelSession(id)
 	case "cleanup":
 		var adkgid common.ADKGID
 		err := common.CastOrUnmarshal(args[0], &adkgid)
@@ -128,10 +154,29 @@ func (service *KeygenService) Call(method string, args ...interface{}) (interfac
 
 func (service *KeygenService) Stop() error {
 	log.Info("Stopping keygen service")
+	service.running.Store(false)
+	return nil
+}
+
+// Drain waits for the keygens this node takes part in to complete, those
+// dealt by other nodes included.
+func (service *KeygenService) Drain(ctx context.Context) error {
+	if service.KeygenNode == nil {
+		return nil
+	}
+	sessions := service.KeygenNode.state.SessionStore
+	log.WithField("keygens", sessions.Open()).Info("waiting for keygens to complete")
+	err := common.WaitUntil(ctx, func() bool {
+		return sessions.Open() == 0
+	})
+	if err != nil {
+		return fmt.Errorf("%d keygens still in progress: %w", sessions.Open(), err)
+	}
 	return nil
 }
+
 func (service *KeygenService) IsRunning() bool {
-	return true
+	return service.running.Load()
 }
 
 type KeygenProtocolPrefix string
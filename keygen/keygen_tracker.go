@@ -51,3 +51,28 @@ func (t *KeygenTracker) Has(id common.ADKGID) bool {
 	_, ok := t.keygens.Load(id)
 	return ok
 }
+
+// Remove stops tracking a keygen once it has completed.
+func (t *KeygenTracker) Remove(id common.ADKGID) {
+	t.keygens.Delete(id)
+}
+
+// StartedAt returns when each keygen in progress was started.
+func (t *KeygenTracker) StartedAt() map[common.ADKGID]time.Time {
+	started := make(map[common.ADKGID]time.Time)
+	t.keygens.Range(func(key, value interface{}) bool {
+		started[key.(common.ADKGID)] = time.Unix(value.(int64), 0)
+		return true
+	})
+	return started
+}
+
+// Len returns the number of keygens in progress.
+func (t *KeygenTracker) Len() int {
+	count := 0
+	t.keygens.Range(func(key, value interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
@@ -136,6 +136,7 @@ func (m Aux2Message) Process(sender common.KeygenNodeDetails, self common.DkgPar
 					return
 				}
 				sessionStore.Decisions[round.Dealer] = w
+				observeDecision(m.RoundID, m.R)
 
 				// If one ABA has outputted 1, then any ABA hasn't started yet, vote 0 for that ABA
 				if w == 1 && !sessionStore.ABAComplete {
@@ -176,6 +177,7 @@ func (m Aux2Message) Process(sender common.KeygenNodeDetails, self common.DkgPar
 						return
 					}
 					sessionStore.KeyderivationStarted = true
+					observeKeyderivationStart(round.ADKGID)
 					go self.ReceiveMessage(self.Details(), *msg)
 				}
 			}
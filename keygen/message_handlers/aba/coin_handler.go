@@ -61,7 +61,8 @@ func (m *CoinMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 		return
 	}
 	store.Lock()
-	coinID := string(m.RoundID) + strconv.Itoa(store.GetRound())
+	r := store.GetRound()
+	coinID := string(m.RoundID) + strconv.Itoa(r)
 	store.Unlock()
 
 	gTilde := curve.Point.Hash([]byte(coinID))
@@ -228,10 +229,12 @@ func (m *CoinMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 			"CompleteCount":        len(sessionStore.Decisions),
 			"ABAComplete":          sessionStore.ABAComplete,
 		}).Info("aba_coin")
+		observeDecision(m.RoundID, r)
 
 		// If all rounds ABA'd to 0 or 1, set ABA complete to true and start key derivation
 		if n == len(sessionStore.Decisions) && !sessionStore.KeyderivationStarted {
 			sessionStore.KeyderivationStarted = true
+			observeKeyderivationStart(adkgid)
 			msg, err := keyderivation.NewInitMessage(m.RoundID, m.Curve)
 			if err != nil {
 				return
@@ -2,8 +2,10 @@ package aba
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/telemetry"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -54,6 +56,7 @@ func (m *InitMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 		return
 	}
 	store.SetStarted(r)
+	observeRoundStart(m.RoundID, r)
 
 	log.Debugf("ABA::Self: %d, Round: %d", self.ID(), r)
 
@@ -66,3 +69,34 @@ func (m *InitMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 		go self.Broadcast(*msg)
 	}
 }
+
+// observeRoundStart times round r of the ABA on roundID, ending the
+// previous round.
+func observeRoundStart(roundID common.RoundID, r int) {
+	round := common.RoundDetails{}
+	if err := round.FromID(roundID); err != nil {
+		return
+	}
+	if r > 0 {
+		telemetry.EndPhase(string(round.ADKGID), telemetry.PhaseABARound, roundInstance(round.Dealer, r-1))
+	}
+	telemetry.StartPhase(string(round.ADKGID), telemetry.PhaseABARound, roundInstance(round.Dealer, r))
+}
+
+// observeDecision ends the timing of the ABA on roundID, decided in round r.
+func observeDecision(roundID common.RoundID, r int) {
+	round := common.RoundDetails{}
+	if err := round.FromID(roundID); err != nil {
+		return
+	}
+	telemetry.EndPhase(string(round.ADKGID), telemetry.PhaseABARound, roundInstance(round.Dealer, r))
+	telemetry.ObserveABARounds(r + 1)
+}
+
+func observeKeyderivationStart(adkgid common.ADKGID) {
+	telemetry.StartPhase(string(adkgid), telemetry.PhaseKeyDerivation, "")
+}
+
+func roundInstance(dealer, r int) string {
+	return strconv.Itoa(dealer) + "/" + strconv.Itoa(r)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/arcana-network/dkgnode/keygen/common/acss"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/keyset"
 	"github.com/arcana-network/dkgnode/keygen/messages"
+	"github.com/arcana-network/dkgnode/telemetry"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -90,6 +91,7 @@ func (m OutputMessage) Process(sender common.KeygenNodeDetails, self common.DkgP
 			sessionStore.S[int(dealer.Int64())] = *share
 			sessionStore.TPrime = kcommon.SetBit(sessionStore.TPrime, int(dealer.Int64()))
 			sessionStore.C[int(dealer.Int64())] = verifier.Commitments
+			telemetry.EndPhase(string(adkgid), telemetry.PhaseACSS, dealer.String())
 
 			// Check proposals and emit
 			for key, v := range sessionStore.TProposals {
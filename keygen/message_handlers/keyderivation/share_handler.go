@@ -6,6 +6,7 @@ import (
 	"github.com/arcana-network/dkgnode/common"
 	kcommon "github.com/arcana-network/dkgnode/keygen/common"
 	"github.com/arcana-network/dkgnode/keygen/common/aba"
+	"github.com/arcana-network/dkgnode/telemetry"
 	"github.com/coinbase/kryptology/pkg/core/curves"
 
 	log "github.com/sirupsen/logrus"
@@ -141,6 +142,7 @@ func (m ShareMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 			return
 		}
 
+		telemetry.EndPhase(string(adkgid), telemetry.PhaseKeyDerivation, "")
 		zI := curve.Scalar.Zero()
 
 		for _, j := range T {
@@ -164,6 +166,7 @@ func (m ShareMessage) Process(sender common.KeygenNodeDetails, self common.DkgPa
 			sessionStore.Share = zI.BigInt()
 			sessionStore.Commitments = common.ADKGMetadata{Commitments: sessionStore.C, T: T}
 			sessionStore.Over = true
+			telemetry.StartPhase(string(adkgid), telemetry.PhaseBFTDecision, "")
 		}
 
 		msg, err := NewPubKeygenMessage(m.RoundID, m.Curve, hZ)
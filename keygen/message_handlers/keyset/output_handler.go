@@ -9,6 +9,7 @@ import (
 	"github.com/arcana-network/dkgnode/common"
 	kcommon "github.com/arcana-network/dkgnode/keygen/common"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/aba"
+	"github.com/arcana-network/dkgnode/telemetry"
 )
 
 var OutputMessageType string = "keyset_output"
@@ -83,6 +84,7 @@ func (m OutputMessage) Process(sender common.KeygenNodeDetails, self common.DkgP
 	}
 
 	sessionStore.ABAStarted = append(sessionStore.ABAStarted, int(leader.Int64()))
+	telemetry.EndPhase(string(adkgid), telemetry.PhaseKeyset, leader.String())
 	msg, err := aba.NewInitMessage(m.RoundID, vote, 0, m.Curve)
 	if err != nil {
 		log.WithError(err).Error("Could not create init message")
@@ -12,6 +12,7 @@ import (
 
 	"github.com/arcana-network/dkgnode/common"
 	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/telemetry"
 )
 
 func (tp *KeygenTransport) Receive(senderDetails common.KeygenNodeDetails, keygenMessage common.DKGMessage) error {
@@ -103,6 +104,12 @@ func (tp *KeygenTransport) Send(nodeDetails common.KeygenNodeDetails, keygenMess
 		return tp.Receive(nodeDetails, keygenMessage)
 	}
 
+	if keygenMessage.Trace == nil {
+		if adkgid, err := common.ADKGIDFromRoundID(keygenMessage.RoundID); err == nil {
+			keygenMessage.Trace = telemetry.InjectTrace(string(adkgid))
+		}
+	}
+
 	// get recipient details
 	nodeReference := tp.broker.ChainMethods().GetNodeDetailsByAddress(common.PointToEthAddress(common.Point(nodeDetails.PubKey)))
 	byt, err := bijson.Marshal(keygenMessage)
@@ -140,8 +147,7 @@ func (tp *KeygenTransport) Send(nodeDetails common.KeygenNodeDetails, keygenMess
 	return nil
 }
 func (tp *KeygenTransport) Sign(s []byte) ([]byte, error) {
-	k := tp.broker.ChainMethods().GetSelfPrivateKey()
-	return ECDSASignBytes(s, &k), nil
+	return tp.broker.ChainMethods().SelfSignData(s)
 }
 
 func stringify(i interface{}) string {
@@ -2,6 +2,7 @@ package keystore
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/arcana-network/dkgnode/eventbus"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -11,8 +12,9 @@ import (
 )
 
 type KeystoreService struct {
-	db  *leveldb.DB
-	bus eventbus.Bus
+	db      *leveldb.DB
+	bus     eventbus.Bus
+	running atomic.Bool
 }
 
 func New(bus eventbus.Bus) *KeystoreService {
@@ -25,19 +27,23 @@ func (k *KeystoreService) ID() string {
 }
 
 func (k *KeystoreService) Start() error {
-	db, err := leveldb.OpenFile(fmt.Sprintf("%s/sym_shares", config.GlobalConfig.BasePath), nil)
+	db, err := leveldb.OpenFile(fmt.Sprintf("%s/sym_shares", config.Current().BasePath), nil)
 	if err != nil {
 		return err
 	}
 	k.db = db
+	k.running.Store(true)
 	return nil
 }
 func (k *KeystoreService) Stop() error {
-	k.db.Close()
-	return nil
+	k.running.Store(false)
+	if k.db == nil {
+		return nil
+	}
+	return k.db.Close()
 }
 func (k *KeystoreService) IsRunning() bool {
-	return true
+	return k.running.Load()
 }
 func (k *KeystoreService) Call(method string, args ...interface{}) (result interface{}, err error) {
 	switch method {
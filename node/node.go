@@ -1,6 +1,7 @@
 package node
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"runtime/debug"
@@ -9,6 +10,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/arcana-network/dkgnode/admin"
+	"github.com/arcana-network/dkgnode/audit"
 	"github.com/arcana-network/dkgnode/cache"
 	"github.com/arcana-network/dkgnode/chain"
 	"github.com/arcana-network/dkgnode/common"
@@ -19,15 +22,31 @@ import (
 	"github.com/arcana-network/dkgnode/keystore"
 	"github.com/arcana-network/dkgnode/p2p"
 	"github.com/arcana-network/dkgnode/server"
+	"github.com/arcana-network/dkgnode/telemetry"
 	"github.com/arcana-network/dkgnode/tendermint"
 	"github.com/arcana-network/dkgnode/verifier"
 )
 
 func Start(conf *config.Config) {
 
-	config.GlobalConfig = conf
+	config.Set(conf)
 
-	log.SetLevel(log.InfoLevel)
+	setLogLevel(conf.LogLevel)
+	config.OnReload(func(old, new *config.Config) {
+		if old.LogLevel != new.LogLevel {
+			setLogLevel(new.LogLevel)
+		}
+	})
+	stopTracing, err := telemetry.InitTracing(conf.TracingEndpoint, conf.IPAddress)
+	if err != nil {
+		log.WithError(err).Error("could not start tracing")
+	} else {
+		defer func() {
+			if err := stopTracing(context.Background()); err != nil {
+				log.WithError(err).Error("could not flush traces")
+			}
+		}()
+	}
 	bus := eventbus.New()
 
 	serviceRegistry := common.NewServiceRegistry(bus)
@@ -44,6 +63,8 @@ func Start(conf *config.Config) {
 		server.New(bus),
 		verifier.New(bus),
 		keystore.New(bus),
+		admin.New(bus),
+		audit.New(bus),
 	}
 
 	for _, s := range services {
@@ -53,7 +74,7 @@ func Start(conf *config.Config) {
 		}
 	}
 
-	err := serviceRegistry.StartAll()
+	err = serviceRegistry.StartAll()
 	if err != nil {
 		log.Fatalf("Error while starting all services: err=%s", err)
 	}
@@ -69,12 +90,33 @@ func Start(conf *config.Config) {
 	stopOnInterrupt(serviceRegistry)
 }
 
+func setLogLevel(name string) {
+	level, err := log.ParseLevel(name)
+	if err != nil {
+		log.WithError(err).Warn("invalid log level, using info")
+		level = log.InfoLevel
+	}
+	log.SetLevel(level)
+}
+
+// stopOnInterrupt reloads the config on SIGHUP and stops the services on
+// the termination signals.
 func stopOnInterrupt(serviceRegistry *common.ServiceRegistry) {
 	osSignal := make(chan os.Signal, 1)
-	signal.Notify(osSignal, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(osSignal, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 	osSig := <-osSignal
+	for osSig == syscall.SIGHUP {
+		if err := config.Reload(); err != nil {
+			log.WithError(err).Error("could not reload config")
+		}
+		osSig = <-osSignal
+	}
 	log.Println("Termination started, signal: " + osSig.String())
-	err := serviceRegistry.StopAll()
+	timeout := time.Duration(config.Current().ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = config.DefaultShutdownTimeout * time.Second
+	}
+	err := serviceRegistry.Shutdown(timeout)
 	if err != nil {
 		log.Fatalf("Error while stopping all services: err=%s", err)
 	}
@@ -44,7 +44,7 @@ type P2PService struct {
 	hostAddress multiaddr.Multiaddr
 	publicKey   common.Point
 	status      MessageType
-	signData    func(data []byte) (rawSig []byte)
+	signData    func(data []byte) (rawSig []byte, err error)
 	broker      *common.MessageBroker
 	context     context.Context
 	cancel      context.CancelFunc
@@ -63,14 +63,25 @@ func (*P2PService) ID() string {
 	return common.P2P_SERVICE_NAME
 }
 
+func (*P2PService) Dependencies() []string {
+	return []string{common.CHAIN_SERVICE_NAME}
+}
+
 func (service *P2PService) Start() error {
 	context, cancel := context.WithCancel(context.Background())
 	service.context = context
 	service.cancel = cancel
 
-	privKey := getPrivKey(service.broker)
+	privKey, err := newSignerKey(service.broker)
+	if err != nil {
+		log.WithError(err).Error("could not create p2p identity")
+		return err
+	}
 
-	service.publicKey = service.broker.ChainMethods().GetSelfPublicKey()
+	service.publicKey, err = service.broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return err
+	}
 
 	node, err := createLibp2pNode(privKey, service.context)
 	if err != nil {
@@ -89,15 +100,6 @@ func (service *P2PService) Start() error {
 	return nil
 }
 
-func getPrivKey(broker *common.MessageBroker) libp2pcrypto.PrivKey {
-	privKeyRaw := broker.ChainMethods().GetSelfPrivateKey()
-	privKey, err := libp2pcrypto.UnmarshalSecp256k1PrivateKey(padPrivKeyBytes(privKeyRaw.Bytes()))
-	if err != nil {
-		log.WithError(err).Fatal("could not Unmarshal privateKey")
-	}
-	return privKey
-}
-
 func createLibp2pNode(privKey libp2pcrypto.PrivKey, ctx context.Context) (node host.Host, err error) {
 	limiter := rcmgr.NewFixedLimiter(rcmgr.DefaultLimits.AutoScale())
 	rcm, err := rcmgr.NewResourceManager(limiter)
@@ -130,16 +132,27 @@ func (service *P2PService) IsRunning() bool {
 	return service.status == RUNNING
 }
 
+// Stop closes the libp2p host, so that a restart can listen on its port
+// again.
 func (service *P2PService) Stop() error {
-	service.cancel()
+	if service.cancel != nil {
+		service.cancel()
+	}
 	service.status = STOPPED
-	return nil
+	if service.p2pNode == nil {
+		return nil
+	}
+	return service.p2pNode.Close()
 }
 func (service *P2PService) Pause() error {
 	service.status = PAUSED
 	return nil
 }
 
+func (service *P2PService) IsPaused() bool {
+	return service.status == PAUSED
+}
+
 func (service *P2PService) GetState() int32 {
 	return int32(service.status)
 }
@@ -209,13 +222,15 @@ func (service *P2PService) signP2PMessage(message common.P2PMessage) ([]byte, er
 	if err != nil {
 		return nil, err
 	}
-	return service.signData(data), nil
+	return service.signData(data)
 }
 
 func (service *P2PService) Call(method string, args ...interface{}) (interface{}, error) {
 	switch method {
 	case "id":
 		return service.p2pNode.ID(), nil
+	case "peers":
+		return service.peers(), nil
 	case "get_host_address":
 
 		if service.hostAddress == nil {
@@ -280,6 +295,26 @@ func (service *P2PService) Call(method string, args ...interface{}) (interface{}
 	return false, nil
 }
 
+// peers lists the address book with the connection state of each peer.
+func (service *P2PService) peers() []common.PeerStatus {
+	peers := []common.PeerStatus{}
+	for _, id := range service.p2pNode.Peerstore().Peers() {
+		if id == service.p2pNode.ID() {
+			continue
+		}
+		status := common.PeerStatus{
+			ID:        id.String(),
+			Addrs:     []string{},
+			Connected: service.p2pNode.Network().Connectedness(id) == network.Connected,
+		}
+		for _, addr := range service.p2pNode.Peerstore().Addrs(id) {
+			status.Addrs = append(status.Addrs, addr.String())
+		}
+		peers = append(peers, status)
+	}
+	return peers
+}
+
 func (service *P2PService) sendP2PMessage(ctx context.Context, id peer.ID, p protocol.ID, msg common.P2PMessage) error {
 	data, err := bijson.Marshal(msg)
 	if err != nil {
@@ -354,12 +389,3 @@ func (service *P2PService) NewP2PMessage(messageId string, gossip bool, payload
 	})
 	return &p2pBasicMsg
 }
-
-func padPrivKeyBytes(kBytes []byte) []byte {
-	if len(kBytes) < 32 {
-		tmp := make([]byte, 32)
-		copy(tmp[32-len(kBytes):], kBytes)
-		return tmp
-	}
-	return kBytes
-}
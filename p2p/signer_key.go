@@ -0,0 +1,69 @@
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/secp256k1"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	pb "github.com/libp2p/go-libp2p/core/crypto/pb"
+)
+
+// signerKey is the libp2p identity backed by the node signer, so that the
+// p2p host does not need the raw node key. It signs like
+// libp2pcrypto.Secp256k1PrivateKey: a DER signature of the sha256 digest.
+type signerKey struct {
+	public libp2pcrypto.PubKey
+	sign   func(hash []byte) ([]byte, error)
+}
+
+func newSignerKey(broker *common.MessageBroker) (*signerKey, error) {
+	point, err := broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pub := ecdsa.PublicKey{Curve: secp256k1.Curve, X: &point.X, Y: &point.Y}
+	public, err := libp2pcrypto.UnmarshalSecp256k1PublicKey(ethCrypto.CompressPubkey(&pub))
+	if err != nil {
+		return nil, err
+	}
+	return &signerKey{public: public, sign: broker.ChainMethods().SignHash}, nil
+}
+
+func (k *signerKey) Sign(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	sig, err := k.sign(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, errors.New("unexpected signature length")
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).SetBytes(sig[:32]),
+		new(big.Int).SetBytes(sig[32:64]),
+	})
+}
+
+func (k *signerKey) GetPublic() libp2pcrypto.PubKey {
+	return k.public
+}
+
+func (k *signerKey) Type() pb.KeyType {
+	return pb.KeyType_Secp256k1
+}
+
+// Raw is not supported, the key never leaves the signer.
+func (k *signerKey) Raw() ([]byte, error) {
+	return nil, errors.New("node key is held by the signer")
+}
+
+func (k *signerKey) Equals(o libp2pcrypto.Key) bool {
+	other, ok := o.(*signerKey)
+	return ok && k.public.Equals(other.public)
+}
@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/arcana-network/dkgnode/secret/keystore"
+	"github.com/arcana-network/dkgnode/secret/vault"
+)
+
+// New returns the secret manager selected by the config kind, set up and
+// ready to use.
+func New(config *secret.SecretConfig) (manager secret.SecretManager, err error) {
+	switch config.Kind {
+	case secret.VaultKind, "":
+		manager, err = vault.NewVaultManager(config)
+	case secret.KeystoreKind:
+		manager, err = keystore.NewKeystoreManager(config)
+	default:
+		return nil, fmt.Errorf("unknown secret manager kind %q", config.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.Setup(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// FromConfigFile reads the secret config at path and returns its manager.
+func FromConfigFile(path string) (secret.SecretManager, error) {
+	config, err := secret.ReadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
@@ -9,11 +9,44 @@ import (
 	"github.com/ryanuber/columnize"
 )
 
+const (
+	VaultKind    = "hashicorp-vault"
+	KeystoreKind = "local-keystore"
+)
+
+// SecretConfig locates the secret manager the node keys are read from. The
+// Vault token is renewed and the node logs in again when it expires. A
+// rotated remote signer token is used right away, but the node and Tendermint
+// keys are only read at start: a rotated key is reported and takes effect on
+// restart, as both are bound to the node registration and the validator set.
 type SecretConfig struct {
 	Kind      string `json:"kind"`
 	Token     string `json:"token"`
 	Namespace string `json:"namespace"`
 	ServerURL string `json:"server_url"`
+
+	// Path is the directory of the local keystore.
+	Path string `json:"path,omitempty"`
+	// PasswordFile holds the keystore password. Without it the password is
+	// read from DKG_KEYSTORE_PASSWORD.
+	PasswordFile string `json:"password_file,omitempty"`
+	// KDF is the keystore key derivation function, scrypt or argon2id.
+	KDF string `json:"kdf,omitempty"`
+
+	// AuthMethod is how the node logs in to Vault: token (the default),
+	// approle or kubernetes.
+	AuthMethod string `json:"auth_method,omitempty"`
+	// AuthMount is the path the auth method is mounted at, when it is not
+	// the method name.
+	AuthMount string `json:"auth_mount,omitempty"`
+	// RoleID and SecretIDFile are the AppRole credentials. The secret id is
+	// read from a file so that it can be rotated without editing the config.
+	RoleID       string `json:"role_id,omitempty"`
+	SecretIDFile string `json:"secret_id_file,omitempty"`
+	// KubernetesRole is the Vault role bound to the service account whose
+	// token is read from KubernetesTokenPath.
+	KubernetesRole      string `json:"kubernetes_role,omitempty"`
+	KubernetesTokenPath string `json:"kubernetes_token_path,omitempty"`
 }
 
 func ReadConfig(path string) (*SecretConfig, error) {
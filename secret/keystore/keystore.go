@@ -0,0 +1,249 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	ScryptKDF = "scrypt"
+	// Argon2KDF stores argon2id parameters in the keystore v3 layout. Such
+	// files are not readable by other keystore v3 implementations.
+	Argon2KDF = "argon2id"
+
+	// PasswordEnv is read when no password file is configured.
+	PasswordEnv = "DKG_KEYSTORE_PASSWORD"
+
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	keyLength     = 32
+)
+
+// keyJSON is the keystore v3 file layout. Address is only set for the node
+// key, which makes its file importable by Ethereum wallets.
+type keyJSON struct {
+	Address string              `json:"address,omitempty"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	ID      string              `json:"id"`
+	Version int                 `json:"version"`
+}
+
+// KeystoreManager keeps every secret in its own password protected file in
+// a local directory.
+type KeystoreManager struct {
+	dir          string
+	passwordFile string
+	kdf          string
+	password     string
+	scryptN      int
+	scryptP      int
+}
+
+func NewKeystoreManager(config *secret.SecretConfig) (*KeystoreManager, error) {
+	if config.Path == "" {
+		return nil, errors.New("keystore path not specified in config")
+	}
+	kdf := config.KDF
+	if kdf == "" {
+		kdf = ScryptKDF
+	}
+	if kdf != ScryptKDF && kdf != Argon2KDF {
+		return nil, fmt.Errorf("unknown keystore kdf %q", kdf)
+	}
+	return &KeystoreManager{
+		dir:          config.Path,
+		passwordFile: config.PasswordFile,
+		kdf:          kdf,
+		scryptN:      keystore.StandardScryptN,
+		scryptP:      keystore.StandardScryptP,
+	}, nil
+}
+
+func (manager *KeystoreManager) Setup() error {
+	password, err := readPassword(manager.passwordFile)
+	if err != nil {
+		return err
+	}
+	manager.password = password
+	return os.MkdirAll(manager.dir, 0700)
+}
+
+func readPassword(passwordFile string) (string, error) {
+	if passwordFile == "" {
+		password := os.Getenv(PasswordEnv)
+		if password == "" {
+			return "", fmt.Errorf("no keystore password file configured and %s not set", PasswordEnv)
+		}
+		return password, nil
+	}
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read keystore password: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (manager *KeystoreManager) path(name string) string {
+	return filepath.Join(manager.dir, name+".json")
+}
+
+func (manager *KeystoreManager) GetSecret(name string) ([]byte, error) {
+	data, err := os.ReadFile(manager.path(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("secret not found")
+		}
+		return nil, err
+	}
+	var key keyJSON
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid keystore file %s: %w", manager.path(name), err)
+	}
+	if key.Version != 3 {
+		return nil, fmt.Errorf("unsupported keystore version %d", key.Version)
+	}
+	if key.Crypto.KDF == Argon2KDF {
+		return decryptArgon2(key.Crypto, manager.password)
+	}
+	return keystore.DecryptDataV3(key.Crypto, manager.password)
+}
+
+// SetSecret writes the secret. An existing secret is kept next to it with
+// the time it was replaced, so that a rotation can be undone.
+func (manager *KeystoreManager) SetSecret(name string, value []byte) error {
+	var (
+		cryptoJSON keystore.CryptoJSON
+		err        error
+	)
+	if manager.kdf == Argon2KDF {
+		cryptoJSON, err = encryptArgon2(value, manager.password)
+	} else {
+		cryptoJSON, err = keystore.EncryptDataV3(value, []byte(manager.password), manager.scryptN, manager.scryptP)
+	}
+	if err != nil {
+		return err
+	}
+	key := keyJSON{Crypto: cryptoJSON, ID: uuid.NewString(), Version: 3}
+	if name == secret.NodeKey {
+		privateKey, err := ethCrypto.ToECDSA(value)
+		if err != nil {
+			return err
+		}
+		key.Address = hex.EncodeToString(ethCrypto.PubkeyToAddress(privateKey.PublicKey).Bytes())
+	}
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := manager.path(name)
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("[Warning] %s secret found, keeping the previous one\n", name)
+		backup := fmt.Sprintf("%s.%d.old", path, time.Now().UnixNano())
+		if err := os.Rename(path, backup); err != nil {
+			return fmt.Errorf("could not keep previous secret: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func argon2Key(password string, salt []byte, rounds, memory uint32, threads uint8, length uint32) []byte {
+	return argon2.IDKey([]byte(password), salt, rounds, memory, threads, length)
+}
+
+func encryptArgon2(data []byte, password string) (keystore.CryptoJSON, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	derived := argon2Key(password, salt, argon2Time, argon2Memory, argon2Threads, keyLength)
+	cipherText, err := aesCTR(derived[:16], iv, data)
+	if err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	c := keystore.CryptoJSON{
+		Cipher:     "aes-128-ctr",
+		CipherText: hex.EncodeToString(cipherText),
+		KDF:        Argon2KDF,
+		KDFParams: map[string]interface{}{
+			"salt":  hex.EncodeToString(salt),
+			"t":     argon2Time,
+			"m":     argon2Memory,
+			"p":     argon2Threads,
+			"dklen": keyLength,
+		},
+		MAC: hex.EncodeToString(ethCrypto.Keccak256(derived[16:32], cipherText)),
+	}
+	c.CipherParams.IV = hex.EncodeToString(iv)
+	return c, nil
+}
+
+func decryptArgon2(c keystore.CryptoJSON, password string) ([]byte, error) {
+	if c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("cipher not supported: %v", c.Cipher)
+	}
+	salt, err := hex.DecodeString(fmt.Sprint(c.KDFParams["salt"]))
+	if err != nil {
+		return nil, err
+	}
+	param := func(name string) uint32 {
+		v, _ := c.KDFParams[name].(float64)
+		return uint32(v)
+	}
+	// argon2 panics on parameters below its minimums.
+	if param("t") < 1 || param("p") < 1 || param("p") > 255 || param("m") < 8*param("p") || param("dklen") < 32 {
+		return nil, errors.New("invalid argon2 parameters")
+	}
+	derived := argon2Key(password, salt, param("t"), param("m"), uint8(param("p")), param("dklen"))
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(ethCrypto.Keccak256(derived[16:32], cipherText), mac) {
+		return nil, keystore.ErrDecrypt
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	return aesCTR(derived[:16], iv, cipherText)
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
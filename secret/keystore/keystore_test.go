@@ -0,0 +1,89 @@
+package keystore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arcana-network/dkgnode/secret"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestManager(t *testing.T, kdf, password string) *KeystoreManager {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte(password+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manager, err := NewKeystoreManager(&secret.SecretConfig{
+		Kind:         secret.KeystoreKind,
+		Path:         filepath.Join(dir, "keystore"),
+		PasswordFile: passwordFile,
+		KDF:          kdf,
+	})
+	if err != nil {
+		t.Fatalf("NewKeystoreManager() error = %v", err)
+	}
+	manager.scryptN, manager.scryptP = keystore.LightScryptN, keystore.LightScryptP
+	if err := manager.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	return manager
+}
+
+func TestKeystoreNodeKeyIsV3Compatible(t *testing.T) {
+	manager := newTestManager(t, ScryptKDF, "secret")
+	_, address, err := secret.InitNodeKey(manager)
+	if err != nil {
+		t.Fatalf("InitNodeKey() error = %v", err)
+	}
+	data, err := os.ReadFile(manager.path(secret.NodeKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(data, "secret")
+	if err != nil {
+		t.Fatalf("keystore.DecryptKey() error = %v", err)
+	}
+	if got := key.Address.Hex(); got != address {
+		t.Errorf("keystore address = %s, want %s", got, address)
+	}
+	stored, err := manager.GetSecret(secret.NodeKey)
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if string(stored) != string(ethCrypto.FromECDSA(key.PrivateKey)) {
+		t.Error("GetSecret() does not return the stored key")
+	}
+}
+
+func TestKeystoreArgon2RoundTripAndRotation(t *testing.T) {
+	manager := newTestManager(t, Argon2KDF, "secret")
+	if _, err := secret.InitTendermintKey(manager); err != nil {
+		t.Fatalf("InitTendermintKey() error = %v", err)
+	}
+	first, err := manager.GetSecret(secret.TendermintKey)
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if _, err := secret.InitTendermintKey(manager); err != nil {
+		t.Fatalf("InitTendermintKey() error = %v", err)
+	}
+	second, err := manager.GetSecret(secret.TendermintKey)
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if string(first) == string(second) {
+		t.Error("rotation kept the same key")
+	}
+	backups, _ := filepath.Glob(manager.path(secret.TendermintKey) + ".*.old")
+	if len(backups) != 1 {
+		t.Errorf("found %d backups of the rotated key, want 1", len(backups))
+	}
+
+	manager.password = "wrong"
+	if _, err := manager.GetSecret(secret.TendermintKey); err == nil {
+		t.Error("GetSecret() succeeded with a wrong password")
+	}
+}
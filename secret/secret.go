@@ -13,9 +13,19 @@ type SecretManager interface {
 	SetSecret(name string, value []byte) error
 }
 
+// Watcher is implemented by secret managers whose secrets can be rotated
+// outside the node.
+type Watcher interface {
+	// Watch calls onChange with the new value every time name changes.
+	Watch(name string, onChange func(value []byte)) error
+}
+
 const (
 	NodeKey       = "node-key"
 	TendermintKey = "tm-key"
+	// SignerToken is the shared secret of the remote signer, when it is not
+	// read from a file.
+	SignerToken = "signer-token"
 )
 
 func InitNodeKey(manager SecretManager) (string, string, error) {
@@ -0,0 +1,209 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcana-network/dkgnode/secret"
+	vault "github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	TokenAuth      = "token"
+	AppRoleAuth    = "approle"
+	KubernetesAuth = "kubernetes"
+
+	defaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// reloginInterval is the wait between failed logins.
+	reloginInterval = 10 * time.Second
+	// secretPollInterval is how often watched secrets are checked for a
+	// new version.
+	secretPollInterval = 5 * time.Minute
+)
+
+func verifyAuth(config *secret.SecretConfig) error {
+	switch config.AuthMethod {
+	case TokenAuth, "":
+		if config.Token == "" {
+			return errors.New("token not specified in config")
+		}
+	case AppRoleAuth:
+		if config.RoleID == "" || config.SecretIDFile == "" {
+			return errors.New("role_id and secret_id_file are required for approle auth")
+		}
+	case KubernetesAuth:
+		if config.KubernetesRole == "" {
+			return errors.New("kubernetes_role is required for kubernetes auth")
+		}
+	default:
+		return fmt.Errorf("unknown vault auth method %q", config.AuthMethod)
+	}
+	return nil
+}
+
+func readCredential(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (manager *VaultManager) authMount(method string) string {
+	if manager.auth.AuthMount != "" {
+		return strings.Trim(manager.auth.AuthMount, "/")
+	}
+	return method
+}
+
+// login authenticates with the configured method and sets the client
+// token. The returned secret describes the token lifetime.
+func (manager *VaultManager) login() (*vault.Secret, error) {
+	var (
+		path string
+		data map[string]interface{}
+	)
+	switch manager.auth.AuthMethod {
+	case AppRoleAuth:
+		secretID, err := readCredential(manager.auth.SecretIDFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read approle secret id: %w", err)
+		}
+		path = fmt.Sprintf("auth/%s/login", manager.authMount(AppRoleAuth))
+		data = map[string]interface{}{"role_id": manager.auth.RoleID, "secret_id": secretID}
+	case KubernetesAuth:
+		tokenPath := manager.auth.KubernetesTokenPath
+		if tokenPath == "" {
+			tokenPath = defaultKubernetesTokenPath
+		}
+		jwt, err := readCredential(tokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read service account token: %w", err)
+		}
+		path = fmt.Sprintf("auth/%s/login", manager.authMount(KubernetesAuth))
+		data = map[string]interface{}{"role": manager.auth.KubernetesRole, "jwt": jwt}
+	default:
+		return manager.lookupToken()
+	}
+
+	// Logins must not carry an old, possibly expired, token.
+	manager.client.ClearToken()
+	authSecret, err := manager.client.Logical().Write(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("vault login failed: %w", err)
+	}
+	if authSecret == nil || authSecret.Auth == nil {
+		return nil, errors.New("vault login returned no token")
+	}
+	manager.client.SetToken(authSecret.Auth.ClientToken)
+	log.WithField("method", manager.auth.AuthMethod).Info("logged in to vault")
+	return authSecret, nil
+}
+
+// lookupToken uses the static token of the config. It can be renewed, but
+// not replaced once it expires.
+func (manager *VaultManager) lookupToken() (*vault.Secret, error) {
+	manager.client.SetToken(manager.token)
+	self, err := manager.client.Auth().Token().LookupSelf()
+	if err != nil {
+		return nil, fmt.Errorf("vault token lookup failed: %w", err)
+	}
+	renewable, _ := self.TokenIsRenewable()
+	ttl, _ := self.TokenTTL()
+	return &vault.Secret{Auth: &vault.SecretAuth{
+		ClientToken:   manager.token,
+		Renewable:     renewable,
+		LeaseDuration: int(ttl.Seconds()),
+	}}, nil
+}
+
+// renewLoop renews the token while it can be renewed, and logs in again once
+// it cannot.
+func (manager *VaultManager) renewLoop(authSecret *vault.Secret) {
+	for {
+		if authSecret == nil {
+			var err error
+			authSecret, err = manager.login()
+			if err != nil {
+				log.WithError(err).Error("VaultManager: could not log in again")
+				select {
+				case <-manager.stop:
+					return
+				case <-time.After(reloginInterval):
+				}
+				continue
+			}
+		}
+		if authSecret.Auth.LeaseDuration == 0 {
+			// The token does not expire.
+			return
+		}
+
+		watcher, err := manager.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: authSecret})
+		if err != nil {
+			log.WithError(err).Error("VaultManager: could not watch token")
+			return
+		}
+		go watcher.Start()
+		stopped := manager.watchToken(watcher)
+		watcher.Stop()
+		if stopped {
+			return
+		}
+		authSecret = nil
+	}
+}
+
+// watchToken returns once the token can no longer be renewed, or true when
+// the manager is closed.
+func (manager *VaultManager) watchToken(watcher *vault.LifetimeWatcher) bool {
+	for {
+		select {
+		case <-manager.stop:
+			return true
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.WithError(err).Warn("VaultManager: token renewal failed")
+			} else {
+				log.Info("VaultManager: token reached its max ttl")
+			}
+			return false
+		case renewal := <-watcher.RenewCh():
+			log.WithField("at", renewal.RenewedAt).Debug("VaultManager: token renewed")
+		}
+	}
+}
+
+// Watch calls onChange with the new value every time a new version of the
+// secret is written to Vault, until the manager is closed.
+func (manager *VaultManager) Watch(name string, onChange func(value []byte)) error {
+	_, version, err := manager.readSecret(name)
+	if err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(secretPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-manager.stop:
+				return
+			case <-ticker.C:
+			}
+			value, latest, err := manager.readSecret(name)
+			if err != nil {
+				log.WithError(err).WithField("secret", name).Warn("VaultManager: could not check secret")
+				continue
+			}
+			if latest != version {
+				version = latest
+				onChange(value)
+			}
+		}
+	}()
+	return nil
+}
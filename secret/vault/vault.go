@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/arcana-network/dkgnode/secret"
 	vault "github.com/hashicorp/vault/api"
@@ -14,23 +15,29 @@ type VaultManager struct {
 	serverURL string
 	token     string
 	namespace string
+	auth      *secret.SecretConfig
 	client    *vault.Client
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewVaultManager(config *secret.SecretConfig) (*VaultManager, error) {
 	if config.ServerURL == "" {
 		return nil, errors.New("server url not specified in config")
 	}
-	if config.Token == "" {
-		return nil, errors.New("token not specified in config")
+	if err := verifyAuth(config); err != nil {
+		return nil, err
 	}
 	return &VaultManager{
 		serverURL: config.ServerURL,
 		token:     config.Token,
 		namespace: config.Namespace,
+		auth:      config,
+		stop:      make(chan struct{}),
 	}, nil
 }
 
+// Setup logs in and keeps the token alive in the background until Close.
 func (manager *VaultManager) Setup() error {
 	config := vault.DefaultConfig()
 
@@ -41,44 +48,72 @@ func (manager *VaultManager) Setup() error {
 		return err
 	}
 
-	client.SetToken(manager.token)
 	log.Infof("namespace=%s", manager.namespace)
 	client.SetNamespace(manager.namespace)
 
 	manager.client = client
+	authSecret, err := manager.login()
+	if err != nil {
+		return err
+	}
+	go manager.renewLoop(authSecret)
 	return nil
 }
 
+// Client returns the Vault client, which stays logged in while the manager
+// is open.
+func (manager *VaultManager) Client() *vault.Client {
+	return manager.client
+}
+
+func (manager *VaultManager) Close() {
+	manager.closeOnce.Do(func() {
+		close(manager.stop)
+	})
+}
+
 func (manager *VaultManager) GetSecret(name string) ([]byte, error) {
+	value, _, err := manager.readSecret(name)
+	return value, err
+}
+
+// readSecret returns the secret with its KV version, which changes every
+// time the secret is written.
+func (manager *VaultManager) readSecret(name string) ([]byte, string, error) {
 	secret, err := manager.client.Logical().Read(fmt.Sprintf("secret/data/%s/%s", manager.namespace, name))
 	if err != nil {
-		return nil, errors.New("unable to get secret from vault")
+		return nil, "", errors.New("unable to get secret from vault")
 	}
 
 	if secret == nil {
-		return nil, errors.New("secret not found")
+		return nil, "", errors.New("secret not found")
 	}
 
 	data, ok := secret.Data["data"]
 	if !ok {
-		return nil, errors.New("unable to assert data type")
+		return nil, "", errors.New("unable to assert data type")
 	}
 
 	if data == nil {
-		return nil, errors.New("secret not found")
+		return nil, "", errors.New("secret not found")
 	}
 
 	value, ok := data.(map[string]interface{})[name]
 	if !ok {
-		return nil, errors.New("secret not found")
+		return nil, "", errors.New("secret not found")
 	}
 
 	val, ok := value.(string)
 	if !ok {
-		return nil, errors.New("secret not in string format")
+		return nil, "", errors.New("secret not in string format")
 	}
 
-	return hex.DecodeString(val)
+	version := ""
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		version = fmt.Sprint(metadata["version"])
+	}
+	decoded, err := hex.DecodeString(val)
+	return decoded, version, err
 }
 
 func (manager *VaultManager) SetSecret(name string, value []byte) error {
@@ -0,0 +1,125 @@
+package vault
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/secret"
+)
+
+// fakeVault issues short lived AppRole tokens and serves one KV secret to
+// the latest token only.
+type fakeVault struct {
+	sync.Mutex
+	logins  int
+	token   string
+	version int
+}
+
+func (f *fakeVault) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.Lock()
+		defer f.Unlock()
+		var body interface{}
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["role_id"] != "node" || req["secret_id"] != "s3cret" {
+				http.Error(w, `{"errors":["invalid credentials"]}`, http.StatusBadRequest)
+				return
+			}
+			f.logins++
+			f.token = fmt.Sprintf("token-%d", f.logins)
+			body = map[string]interface{}{"auth": map[string]interface{}{
+				"client_token":   f.token,
+				"renewable":      false,
+				"lease_duration": 1,
+			}}
+		case "/v1/secret/data/default/node-key":
+			if r.Header.Get("X-Vault-Token") != f.token {
+				http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+				return
+			}
+			body = map[string]interface{}{"data": map[string]interface{}{
+				"data":     map[string]interface{}{"node-key": hex.EncodeToString([]byte{byte(f.version)})},
+				"metadata": map[string]interface{}{"version": f.version},
+			}}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	})
+}
+
+func (f *fakeVault) loginCount() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.logins
+}
+
+func TestAppRoleLogsInAgainWhenTokenExpires(t *testing.T) {
+	fake := &fakeVault{version: 1}
+	server := httptest.NewServer(fake.handler(t))
+	defer server.Close()
+	secretIDFile := filepath.Join(t.TempDir(), "secret-id")
+	if err := os.WriteFile(secretIDFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewVaultManager(&secret.SecretConfig{
+		ServerURL:    server.URL,
+		Namespace:    "default",
+		AuthMethod:   AppRoleAuth,
+		RoleID:       "node",
+		SecretIDFile: secretIDFile,
+	})
+	if err != nil {
+		t.Fatalf("NewVaultManager() error = %v", err)
+	}
+	if err := manager.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer manager.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fake.loginCount() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("manager did not log in again after the token expired")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	value, version, err := manager.readSecret(secret.NodeKey)
+	if err != nil {
+		t.Fatalf("readSecret() with the new token error = %v", err)
+	}
+	if len(value) != 1 || value[0] != 1 || version != "1" {
+		t.Errorf("readSecret() = %x, %s, want version 1", value, version)
+	}
+}
+
+func TestNewVaultManagerValidatesAuth(t *testing.T) {
+	configs := []secret.SecretConfig{
+		{ServerURL: "http://vault"},
+		{ServerURL: "http://vault", AuthMethod: AppRoleAuth, RoleID: "node"},
+		{ServerURL: "http://vault", AuthMethod: KubernetesAuth},
+		{ServerURL: "http://vault", AuthMethod: "ldap"},
+	}
+	for _, config := range configs {
+		config := config
+		if _, err := NewVaultManager(&config); err == nil {
+			t.Errorf("NewVaultManager(%+v) accepted an incomplete auth config", config)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/osamingo/jsonrpc/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// batchHandler serves JSON-RPC 2.0 batch arrays and hands single requests
+// to the method repository. The requests of a batch are served concurrently,
+// as KeyAssign waits for its tx to be committed.
+type batchHandler struct {
+	mr     *jsonrpc.MethodRepository
+	limits *requestLimits
+}
+
+func sendError(w http.ResponseWriter, rpcErr *jsonrpc.Error) {
+	err := jsonrpc.SendResponse(w, []*jsonrpc.Response{{Version: jsonrpc.Version, Error: rpcErr}}, false)
+	if err != nil {
+		log.WithError(err).Error("could not send JRPC error")
+	}
+}
+
+func (h batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := r.Context().Value(requestBody).([]byte)
+	if !isBatch(body) {
+		h.mr.ServeHTTP(w, r)
+		return
+	}
+
+	requests, _, rpcErr := jsonrpc.ParseRequest(r)
+	if rpcErr != nil {
+		sendError(w, rpcErr)
+		return
+	}
+	if len(requests) == 0 {
+		sendError(w, jsonrpc.ErrInvalidRequest())
+		return
+	}
+	if maxBatch := h.limits.maxBatch.Load(); maxBatch > 0 && int64(len(requests)) > maxBatch {
+		rpcErr := jsonrpc.ErrInvalidRequest()
+		rpcErr.Data = "batch too large"
+		sendError(w, rpcErr)
+		return
+	}
+
+	responses := make([]*jsonrpc.Response, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		if req == nil {
+			responses[i] = &jsonrpc.Response{Version: jsonrpc.Version, Error: jsonrpc.ErrInvalidRequest()}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, req *jsonrpc.Request) {
+			defer wg.Done()
+			responses[i] = h.mr.InvokeMethod(r.Context(), req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	// Notifications are not answered
+	answered := make([]*jsonrpc.Response, 0, len(responses))
+	for i, resp := range responses {
+		if requests[i] != nil && requests[i].ID == nil {
+			continue
+		}
+		answered = append(answered, resp)
+	}
+	if len(answered) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := jsonrpc.SendResponse(w, answered, true); err != nil {
+		log.WithError(err).Error("could not send JRPC batch response")
+	}
+}
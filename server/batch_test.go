@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/gorilla/mux"
+	"github.com/osamingo/jsonrpc/v2"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) ServeJSONRPC(_ context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p struct {
+		Value string `json:"value"`
+	}
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	return p.Value, nil
+}
+
+func batchRouter(t *testing.T, limits *requestLimits) http.Handler {
+	mr := jsonrpc.NewMethodRepository()
+	if err := mr.RegisterMethod("Echo", echoHandler{}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	router.Handle("/rpc", batchHandler{mr: mr, limits: limits})
+	router.Use(parseBodyMiddleware(limits))
+	router.Use(augmentRequestMiddleware)
+	router.Use(rateLimitMiddleware(limits))
+	return router
+}
+
+func TestBatchRequests(t *testing.T) {
+	limits := newRequestLimits(0, 3, map[string]config.RateLimit{"Echo": {PerIP: 1, PerIPBurst: 4}})
+	server := httptest.NewServer(batchRouter(t, limits))
+	defer server.Close()
+
+	post := func(body string) (int, string) {
+		resp, err := http.Post(server.URL+"/rpc", contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out json.RawMessage
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, string(out)
+	}
+
+	code, out := post(`[
+		{"jsonrpc":"2.0","id":1,"method":"Echo","params":{"value":"a"}},
+		{"jsonrpc":"2.0","method":"Echo","params":{"value":"notification"}},
+		{"jsonrpc":"2.0","id":2,"method":"Echo","params":{"value":"b"}}
+	]`)
+	var responses []struct {
+		ID     int    `json:"id"`
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(out), &responses); err != nil {
+		t.Fatalf("batch response %d %s: %v", code, out, err)
+	}
+	if len(responses) != 2 || responses[0].ID != 1 || responses[0].Result != "a" || responses[1].ID != 2 || responses[1].Result != "b" {
+		t.Errorf("batch response = %s", out)
+	}
+
+	if code, out := post("[" + strings.Repeat(`{"jsonrpc":"2.0","method":"Echo"},`, 3) + `{"jsonrpc":"2.0","method":"Echo"}]`); !strings.Contains(out, "batch too large") {
+		t.Errorf("oversized batch = %d %s", code, out)
+	}
+	if code, _ := post(`{"jsonrpc":"2.0","id":3,"method":"Echo","params":{"value":"c"}}`); code != http.StatusOK {
+		t.Errorf("single request = %d", code)
+	}
+	// The batch took three of the four tokens and the single request one.
+	if code, _ := post(`[{"jsonrpc":"2.0","id":4,"method":"Echo","params":{"value":"d"}}]`); code != http.StatusTooManyRequests {
+		t.Errorf("batch over the rate limit = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
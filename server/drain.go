@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/server/rpc"
+)
+
+const drainingResponse = `{"jsonrpc":"2.0","id":null,"error":{"code":-32005,"message":"node is shutting down"}}`
+
+// peerMethods are still served while the node drains. Other nodes look up
+// the connection details of this node to finish the keygens it takes part
+// in.
+var peerMethods = map[string]bool{
+	rpc.ConnectionDetailsMethod: true,
+	rpc.HealthMethod:            true,
+}
+
+// drainGate turns away client requests once the node drains, and counts the
+// client requests still being served.
+type drainGate struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+// onlyPeerMethods reports whether every request of a body is a peer method.
+func onlyPeerMethods(requests []jRPCRequest) bool {
+	for _, request := range requests {
+		if !peerMethods[request.Method] {
+			return false
+		}
+	}
+	return len(requests) > 0
+}
+
+func (g *drainGate) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests, _ := r.Context().Value(jrpcRequests).([]jRPCRequest)
+		if onlyPeerMethods(requests) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// WebSocket connections are hijacked and outlive their handler, so
+		// only JSON-RPC requests are waited for.
+		if len(requests) > 0 {
+			g.inFlight.Add(1)
+			defer g.inFlight.Add(-1)
+		}
+		if g.draining.Load() {
+			log.WithField("RemoteAddr", r.RemoteAddr).Info("JRPC request refused while draining")
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(drainingResponse))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// drain stops taking client requests and waits for the ones being served.
+func (g *drainGate) drain(ctx context.Context) error {
+	g.draining.Store(true)
+	return common.WaitUntil(ctx, func() bool {
+		return g.inFlight.Load() == 0
+	})
+}
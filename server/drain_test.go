@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/server/rpc"
+)
+
+func TestDrainKeepsPeerMethods(t *testing.T) {
+	gate := &drainGate{}
+	release := make(chan struct{})
+	handler := parseBodyMiddleware(newRequestLimits(0, 0, nil))(augmentRequestMiddleware(gate.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Block") != "" {
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))))
+	serve := func(method string, block bool) int {
+		r := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":{}}`))
+		if block {
+			r.Header.Set("X-Block", "1")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	served := make(chan int)
+	go func() { served <- serve(rpc.KeyShareRequestMethod, true) }()
+	for gate.inFlight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := gate.drain(ctx); err == nil {
+		t.Error("drain did not wait for the request being served")
+	}
+
+	if code := serve(rpc.KeyShareRequestMethod, false); code != http.StatusServiceUnavailable {
+		t.Errorf("client request while draining = %d", code)
+	}
+	if code := serve(rpc.ConnectionDetailsMethod, false); code != http.StatusOK {
+		t.Errorf("peer request while draining = %d", code)
+	}
+
+	close(release)
+	if code := <-served; code != http.StatusOK {
+		t.Errorf("request served before the drain = %d", code)
+	}
+	if err := gate.drain(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/arcana-network/dkgnode/telemetry"
+	log "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// anyMethod is the rate limit entry for methods without their own.
+	anyMethod = "*"
+	// limiterIdleTimeout is how long an unused limiter is kept.
+	limiterIdleTimeout = 10 * time.Minute
+
+	rateLimitedResponse = `{"jsonrpc":"2.0","id":null,"error":{"code":-32005,"message":"rate limit exceeded"}}`
+)
+
+// defaultRateLimits apply to methods the config sets no limit for, ahead of
+// the "*" entry. Every KeyCommitmentRequest holds an entry of the token cache
+// namespace until the token is used or expires, and the namespace refuses
+// new entries once full, so one client IP may only hold a small share of it:
+// at most about 5400 with the default 90 minute TTL.
+var defaultRateLimits = map[string]config.RateLimit{
+	"KeyCommitmentRequest": {PerIP: 1, PerIPBurst: 10},
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// requestLimits holds the body size and batch caps and the rate limiters of
+// the JSON-RPC server. All can be replaced on config reload.
+type requestLimits struct {
+	maxBytes atomic.Int64
+	maxBatch atomic.Int64
+
+	mu       sync.Mutex
+	limits   map[string]config.RateLimit
+	limiters map[string]*limiterEntry
+	lastGC   time.Time
+}
+
+func newRequestLimits(maxBytes int64, maxBatch int, limits map[string]config.RateLimit) *requestLimits {
+	l := &requestLimits{}
+	l.set(maxBytes, maxBatch, limits)
+	return l
+}
+
+// set replaces the limits. Buckets start full again afterwards.
+func (l *requestLimits) set(maxBytes int64, maxBatch int, limits map[string]config.RateLimit) {
+	l.maxBytes.Store(maxBytes)
+	l.maxBatch.Store(int64(maxBatch))
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+	l.limiters = make(map[string]*limiterEntry)
+}
+
+func (l *requestLimits) limit(method string) (config.RateLimit, bool) {
+	if limit, ok := l.limits[method]; ok {
+		return limit, true
+	}
+	if limit, ok := defaultRateLimits[method]; ok {
+		return limit, true
+	}
+	limit, ok := l.limits[anyMethod]
+	return limit, ok
+}
+
+// allow takes a token from the client IP and the verifier buckets of the
+// method. Both have to have one left.
+func (l *requestLimits) allow(method, ip, verifier string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.limit(method)
+	if !ok {
+		return true
+	}
+	l.gc(now)
+
+	var limiters []*rate.Limiter
+	if limit.PerIP > 0 && ip != "" {
+		limiters = append(limiters, l.limiter("ip/"+method+"/"+ip, limit.PerIP, limit.PerIPBurst, now))
+	}
+	if limit.PerVerifier > 0 && verifier != "" {
+		limiters = append(limiters, l.limiter("verifier/"+method+"/"+verifier, limit.PerVerifier, limit.PerVerifierBurst, now))
+	}
+	var reservations []*rate.Reservation
+	for _, limiter := range limiters {
+		r := limiter.ReserveN(now, 1)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			// Hand back the tokens taken so far, the request is not served.
+			r.CancelAt(now)
+			for _, taken := range reservations {
+				taken.CancelAt(now)
+			}
+			return false
+		}
+		reservations = append(reservations, r)
+	}
+	return true
+}
+
+func (l *requestLimits) limiter(key string, perSecond float64, burst int, now time.Time) *rate.Limiter {
+	entry, ok := l.limiters[key]
+	if !ok {
+		if burst < 1 {
+			burst = 1
+		}
+		entry = &limiterEntry{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)}
+		l.limiters[key] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter
+}
+
+// gc drops the limiters of clients that have gone quiet.
+func (l *requestLimits) gc(now time.Time) {
+	if now.Sub(l.lastGC) < limiterIdleTimeout {
+		return
+	}
+	l.lastGC = now
+	for key, entry := range l.limiters {
+		if now.Sub(entry.lastSeen) > limiterIdleTimeout {
+			delete(l.limiters, key)
+		}
+	}
+}
+
+// verifierParams are the request params that name a verifier.
+type verifierParams struct {
+	Provider           string `json:"provider"`
+	VerifierIdentifier string `json:"verifieridentifier"`
+	Item               []struct {
+		Provider string `json:"provider"`
+	} `json:"item"`
+}
+
+// requestVerifier returns the verifier a JSON-RPC request is made for, or ""
+// for requests that are not tied to one.
+func requestVerifier(params []byte) string {
+	var p verifierParams
+	if err := bijson.Unmarshal(params, &p); err != nil {
+		return ""
+	}
+	switch {
+	case p.Provider != "":
+		return p.Provider
+	case p.VerifierIdentifier != "":
+		return p.VerifierIdentifier
+	case len(p.Item) > 0:
+		return p.Item[0].Provider
+	}
+	return ""
+}
+
+// allowRequests takes tokens for every request of a body, so that a batch
+// costs as much as its requests sent one by one. Bodies that are not
+// JSON-RPC, like WebSocket upgrades, fall under the default limit.
+func (l *requestLimits) allowRequests(requests []jRPCRequest, ip string, now time.Time) (string, bool) {
+	if len(requests) == 0 {
+		return "", l.allow("", ip, "", now)
+	}
+	if maxBatch := l.maxBatch.Load(); maxBatch > 0 && int64(len(requests)) > maxBatch {
+		// Rejected by batchHandler without serving any of them
+		return "", true
+	}
+	for _, req := range requests {
+		if !l.allow(req.Method, ip, requestVerifier(req.Params), now) {
+			return req.Method, false
+		}
+	}
+	return "", true
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func rateLimitMiddleware(limits *requestLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests, _ := r.Context().Value(jrpcRequests).([]jRPCRequest)
+			if method, ok := limits.allowRequests(requests, clientIP(r), time.Now()); !ok {
+				log.WithFields(log.Fields{
+					"RemoteAddr": r.RemoteAddr,
+					"method":     method,
+				}).Warn("JRPC request rate limited")
+				telemetry.CountRateLimited(method)
+				w.Header().Set("Content-Type", contentType)
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(rateLimitedResponse))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/config"
+	"github.com/gorilla/mux"
+)
+
+func TestRequestLimitsPerIPAndVerifier(t *testing.T) {
+	limits := newRequestLimits(0, 0, map[string]config.RateLimit{
+		"KeyAssign": {PerIP: 1, PerIPBurst: 2, PerVerifier: 1, PerVerifierBurst: 3},
+		anyMethod:   {PerIP: 1},
+	})
+	now := time.Now()
+
+	if !limits.allow("KeyAssign", "1.1.1.1", "google", now) || !limits.allow("KeyAssign", "1.1.1.1", "google", now) {
+		t.Fatal("requests within the ip burst were limited")
+	}
+	if limits.allow("KeyAssign", "1.1.1.1", "google", now) {
+		t.Error("third request from one ip was allowed")
+	}
+	if !limits.allow("KeyAssign", "2.2.2.2", "google", now) {
+		t.Error("request from another ip was limited")
+	}
+	// The verifier bucket is now empty, and the rejected request must not
+	// have taken a token from the fresh ip bucket.
+	if limits.allow("KeyAssign", "3.3.3.3", "google", now) {
+		t.Error("request over the verifier limit was allowed")
+	}
+	if !limits.allow("KeyAssign", "3.3.3.3", "discord", now) || !limits.allow("KeyAssign", "3.3.3.3", "discord", now) {
+		t.Error("ip bucket lost a token to a rejected request")
+	}
+	if !limits.allow("KeyAssign", "1.1.1.1", "google", now.Add(time.Second)) {
+		t.Error("ip bucket was not refilled")
+	}
+
+	if !limits.allow("HealthCheck", "1.1.1.1", "", now) || limits.allow("HealthCheck", "1.1.1.1", "", now) {
+		t.Error("default limit not applied")
+	}
+	limits.set(0, 0, nil)
+	if !limits.allow("HealthCheck", "1.1.1.1", "", now) {
+		t.Error("removed limit still applied")
+	}
+}
+
+func TestKeyCommitmentRequestLimitedByDefault(t *testing.T) {
+	limits := newRequestLimits(0, 0, map[string]config.RateLimit{anyMethod: {PerIP: 1000, PerIPBurst: 1000}})
+	now := time.Now()
+
+	burst := defaultRateLimits["KeyCommitmentRequest"].PerIPBurst
+	for i := 0; i < burst; i++ {
+		if !limits.allow("KeyCommitmentRequest", "1.1.1.1", "google", now) {
+			t.Fatalf("request %d within the burst was limited", i)
+		}
+	}
+	if limits.allow("KeyCommitmentRequest", "1.1.1.1", "google", now) {
+		t.Error("commitment flood from one ip was allowed")
+	}
+	if !limits.allow("KeyCommitmentRequest", "2.2.2.2", "google", now) {
+		t.Error("request from another ip was limited")
+	}
+
+	limits.set(0, 0, map[string]config.RateLimit{"KeyCommitmentRequest": {}})
+	for i := 0; i <= burst; i++ {
+		if !limits.allow("KeyCommitmentRequest", "1.1.1.1", "google", now) {
+			t.Fatal("configured limit did not replace the default")
+		}
+	}
+}
+
+func TestRequestVerifier(t *testing.T) {
+	bodies := map[string]string{
+		`{"provider":"google"}`:            "google",
+		`{"verifieridentifier":"discord"}`: "discord",
+		`{"item":[{"provider":"twitch"}]}`: "twitch",
+		`{}`:                               "",
+		`not json`:                         "",
+	}
+	for body, want := range bodies {
+		if got := requestVerifier([]byte(body)); got != want {
+			t.Errorf("requestVerifier(%s) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+func testRouter(limits *requestLimits, requireClientCert bool) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/rpc", func(w http.ResponseWriter, r *http.Request) {})
+	router.Use(parseBodyMiddleware(limits))
+	router.Use(augmentRequestMiddleware)
+	if requireClientCert {
+		router.Use(requireClientCertMiddleware)
+	}
+	router.Use(rateLimitMiddleware(limits))
+	return router
+}
+
+func TestMiddlewaresEnforceLimits(t *testing.T) {
+	limits := newRequestLimits(64, 0, map[string]config.RateLimit{"HealthCheck": {PerIP: 1}})
+	server := httptest.NewServer(testRouter(limits, false))
+	defer server.Close()
+
+	post := func(body string) int {
+		resp, err := http.Post(server.URL+"/rpc", contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post(`{"method":"HealthCheck","params":{}}`); code != http.StatusOK {
+		t.Errorf("first request = %d", code)
+	}
+	if code := post(`{"method":"HealthCheck","params":{}}`); code != http.StatusTooManyRequests {
+		t.Errorf("second request = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := post(`{"method":"KeyAssign","params":{"user_id":"` + strings.Repeat("a", 64) + `"}}`); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large request = %d, want %d", code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// writeCert writes a certificate signed by parent, self signed when parent
+// is nil, and returns it with its key.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func TestConnectionDetailsRequiresClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "server", ca, caKey)
+	writeCert(t, dir, "node", ca, caKey)
+
+	certs, err := newCertReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatalf("newCertReloader() error = %v", err)
+	}
+	server := httptest.NewUnstartedServer(testRouter(newRequestLimits(0, 0, nil), true))
+	server.TLS = certs.serverConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	postBody := func(client *tls.Config, body string) int {
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: client}}
+		resp, err := c.Post(server.URL+"/rpc", contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	post := func(client *tls.Config, method string) int {
+		return postBody(client, `{"method":"`+method+`","params":{}}`)
+	}
+	anonymous := &tls.Config{RootCAs: roots}
+	if code := post(anonymous, "HealthCheck"); code != http.StatusOK {
+		t.Errorf("HealthCheck without client certificate = %d", code)
+	}
+	if code := post(anonymous, "ConnectionDetails"); code != http.StatusForbidden {
+		t.Errorf("ConnectionDetails without client certificate = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := postBody(anonymous, `[{"method":"HealthCheck"},{"method":"ConnectionDetails"}]`); code != http.StatusForbidden {
+		t.Errorf("batched ConnectionDetails without client certificate = %d, want %d", code, http.StatusForbidden)
+	}
+	nodeCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "node.pem"), filepath.Join(dir, "node.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := post(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{nodeCert}}, "ConnectionDetails"); code != http.StatusOK {
+		t.Errorf("ConnectionDetails with client certificate = %d", code)
+	}
+}
@@ -3,57 +3,107 @@ package server
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/server/rpc"
 )
 
 type contextKey string
 
 const requestBody contextKey = "body"
 const jrpcMethod contextKey = "method"
+const jrpcRequests contextKey = "requests"
+
+// batchMethod is logged as the method of batch requests.
+const batchMethod = "batch"
 
 type jRPCRequest struct {
-	Method string `json:"method"`
+	Method string            `json:"method"`
+	Params bijson.RawMessage `json:"params"`
+}
+
+// isBatch reports whether a body is a JSON-RPC batch array.
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// parseJRPCRequests returns the requests of a single or a batch body.
+func parseJRPCRequests(body []byte) ([]jRPCRequest, error) {
+	if isBatch(body) {
+		var batch []jRPCRequest
+		err := bijson.Unmarshal(body, &batch)
+		return batch, err
+	}
+	var j jRPCRequest
+	if err := bijson.Unmarshal(body, &j); err != nil {
+		return nil, err
+	}
+	return []jRPCRequest{j}, nil
 }
 
 func setContextValue(r *http.Request, key contextKey, val interface{}) *http.Request {
 	return r.WithContext(context.WithValue(r.Context(), key, val))
 }
-func parseBodyMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// NOTE: This is necessary, as we are expecting to reread the body later
-		// on in the middleware / request chain
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			log.WithError(err).Error("could not read request body")
-			return
-		}
-		r = setContextValue(r, requestBody, body)
-		r.Body = io.NopCloser(bytes.NewReader(body))
-		next.ServeHTTP(w, r)
-	})
+func parseBodyMiddleware(limits *requestLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes := limits.maxBytes.Load(); maxBytes > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			// NOTE: This is necessary, as we are expecting to reread the body later
+			// on in the middleware / request chain
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					log.WithField("RemoteAddr", r.RemoteAddr).Warn("request body too large")
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				log.WithError(err).Error("could not read request body")
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+			r = setContextValue(r, requestBody, body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 func augmentRequestMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// set RPC method
-		var j jRPCRequest
 		body, ok := r.Context().Value(requestBody).([]byte)
 		if !ok {
 			log.Error("request body not set on context")
 			next.ServeHTTP(w, r)
 			return
 		}
-		err := bijson.Unmarshal(body, &j)
+		if len(body) == 0 {
+			// WebSocket upgrades come without a body
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(rpc.WithOrigin(r.Context(), r.Header.Get("Origin")))
+		requests, err := parseJRPCRequests(body)
 		if err != nil {
 			log.WithField("body", string(body)).WithError(err).Error("could not Unmarshal body getJRPCMethod")
 			next.ServeHTTP(w, r)
 			return
 		}
-		r = setContextValue(r, jrpcMethod, j.Method)
+		r = setContextValue(r, jrpcRequests, requests)
+		if isBatch(body) {
+			r = setContextValue(r, jrpcMethod, batchMethod)
+		} else {
+			r = setContextValue(r, jrpcMethod, requests[0].Method)
+		}
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum/common"
+	fastjson "github.com/goccy/go-json"
+	"github.com/osamingo/jsonrpc/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/arcana-network/dkgnode/tendermint/messageq"
+)
+
+type originKey struct{}
+
+// WithOrigin returns a context carrying the Origin header of a request, which
+// the policies of apps are evaluated on.
+func WithOrigin(ctx context.Context, origin string) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+func originFrom(ctx context.Context) string {
+	origin, _ := ctx.Value(originKey{}).(string)
+	return origin
+}
+
+type (
+	AppPolicySetHandler struct {
+		bus eventbus.Bus
+	}
+	// AppPolicySetParams are sent to every node. Each node checks the owner
+	// of the app on chain, and the policy is set once threshold nodes did.
+	AppPolicySetParams struct {
+		// Policy is the JSON policy document, exactly as it was signed.
+		Policy string `json:"policy"`
+		// Signature is the hex personal_sign signature of the policy by the
+		// owner of the app.
+		Signature string `json:"signature"`
+	}
+	AppPolicySetResult struct {
+		TxHash string `json:"tx_hash"`
+	}
+	AppPolicyTx struct {
+		Policy    []byte
+		Signature []byte
+		Owner     eth.Address
+	}
+)
+
+func (h AppPolicySetHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p AppPolicySetParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(c, time.Duration(requestTimer)*time.Second)
+	defer cancel()
+	broker := common.NewServiceBroker(h.bus, "app_policy_set_handler").WithContext(ctx)
+
+	var policy common.AppPolicy
+	if err := bijson.Unmarshal([]byte(p.Policy), &policy); err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Invalid policy: " + err.Error()}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Invalid policy: " + err.Error()}
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(p.Signature, "0x"))
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Invalid signature"}
+	}
+	signer, err := common.AppPolicySigner([]byte(p.Policy), signature)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Invalid signature: " + err.Error()}
+	}
+	// The owner is looked up here rather than in the BFT app, which has to
+	// check the tx the same way on every node.
+	owner, err := broker.ChainMethods().AppOwner(policy.AppID)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to get app owner: " + err.Error()}
+	}
+	if signer != owner {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Policy is not signed by the app owner"}
+	}
+
+	hash, err := broker.TendermintMethods().Broadcast(AppPolicyTx{
+		Policy:    []byte(p.Policy),
+		Signature: signature,
+		Owner:     owner,
+	})
+	if errors.Is(err, messageq.ErrQueueFull) {
+		return nil, &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending transactions, retry later"}
+	}
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
+	}
+	if rpcErr := waitForTransaction(hash, ctx, broker); rpcErr != nil {
+		return nil, rpcErr
+	}
+	log.WithFields(log.Fields{
+		"appId":   policy.AppID,
+		"version": policy.Version,
+	}).Info("app policy set")
+	return AppPolicySetResult{TxHash: hash.String()}, nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/arcana-network/dkgnode/secp256k1"
 	"github.com/arcana-network/dkgnode/telemetry"
 	"github.com/arcana-network/dkgnode/tendermint"
+	"github.com/arcana-network/dkgnode/tendermint/messageq"
 
 	tronCrypto "github.com/TRON-US/go-eccrypto"
 	"github.com/arcana-network/dkgnode/eventbus"
@@ -70,6 +71,7 @@ type (
 		bus eventbus.Bus
 	}
 	HealthHandler struct {
+		bus eventbus.Bus
 	}
 	ConnectionDetailsHandler struct {
 		eventBus eventbus.Bus
@@ -120,6 +122,9 @@ type (
 		UserID   string `json:"user_id"`
 		AppID    string `json:"app_id"`
 		Curve    string `json:"curve"`
+		// Async returns the tx hash right after the broadcast. The key is
+		// then pushed to a subscription on the WebSocket endpoint.
+		Async bool `json:"async"`
 	}
 	HealthParams struct {
 	}
@@ -130,10 +135,12 @@ type (
 		Address  string  `json:"address"`
 	}
 	KeyAssignResult struct {
-		Keys []KeyAssignItem `json:"keys"`
+		Keys   []KeyAssignItem `json:"keys"`
+		TxHash string          `json:"tx_hash,omitempty"`
 	}
 	HealthResult struct {
-		Status string `json:"status"`
+		Status   string                          `json:"status"`
+		Services map[string]common.ServiceStatus `json:"services,omitempty"`
 	}
 	ValidatedNodeSignature struct {
 		NodeSignature
@@ -167,6 +174,11 @@ type AssignmentTx struct {
 	Curve    common.CurveName
 }
 
+type TokenSeenTx struct {
+	TokenCommitment string
+	TempPubKey      common.Point
+}
+
 func (c *CommitmentRequestResultData) ToString() string {
 	return strings.Join([]string{
 		c.MessagePrefix,
@@ -241,9 +253,9 @@ func (h KeyAssignHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMe
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
 	}
-	broker := common.NewServiceBroker(h.bus, "key_assign_handler")
 	ctx, cancel := context.WithTimeout(c, time.Duration(requestTimer)*time.Second)
 	defer cancel()
+	broker := common.NewServiceBroker(h.bus, "key_assign_handler").WithContext(ctx)
 
 	if p.Curve == "" {
 		p.Curve = string(common.SECP256K1)
@@ -276,9 +288,15 @@ func (h KeyAssignHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMe
 		Curve:    common.CurveName(p.Curve),
 	}
 	hash, err := broker.TendermintMethods().Broadcast(msg)
+	if errors.Is(err, messageq.ErrQueueFull) {
+		return nil, &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending transactions, retry later"}
+	}
 	if err != nil {
 		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
 	}
+	if p.Async {
+		return KeyAssignResult{Keys: make([]KeyAssignItem, 0), TxHash: hash.String()}, nil
+	}
 	rpcErr := waitForTransaction(hash, ctx, broker)
 	if rpcErr != nil {
 		return nil, rpcErr
@@ -289,12 +307,34 @@ func (h KeyAssignHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMe
 	return KeyAssignResult{Keys: make([]KeyAssignItem, 0)}, nil
 }
 
-func (h HealthHandler) ServeJSONRPC(_ context.Context, _ *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
-	return HealthResult{Status: "Ok"}, nil
+func (h HealthHandler) ServeJSONRPC(c context.Context, _ *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	broker := common.NewServiceBroker(h.bus, "health_handler").WithContext(c)
+	states, err := broker.RegistryMethods().ServiceStates()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to get service states"}
+	}
+	status := "Ok"
+	if common.Degraded(states) {
+		status = "Degraded"
+	}
+	return HealthResult{Status: status, Services: states}, nil
 }
 
 var requestTimer = 45
 
+// ServerBusyErrorCode is returned when the node cannot take more work right
+// now. Clients may retry the same request later.
+const ServerBusyErrorCode = -32005
+
+// PolicyDeniedErrorCode is returned when the policy of an app denies a share
+// request. The data of the error is the reason.
+const PolicyDeniedErrorCode = -32006
+
+// RecoveryPendingErrorCode is returned for share requests through backup
+// verifiers while the recovery of the key can still be cancelled. The data of
+// the error is when the share will be released.
+const RecoveryPendingErrorCode = -32007
+
 func getTxStatus(broker *common.MessageBroker, hash []byte) *jsonrpc.Error {
 	valid, err := broker.TendermintMethods().TxStatus(hash)
 	if err != nil {
@@ -344,6 +384,37 @@ func waitForTransaction(hash common.Hash, requestContext context.Context,
 	return nil
 }
 
+// claimToken binds a token to the commitment flow of a share request. A token
+// unlocks shares only in the first flow any node saw it in, so a replayed
+// token is refused on every node, even after a restart.
+func claimToken(c context.Context, broker *common.MessageBroker, tokenCommitment string, tempPubKey common.Point) *jsonrpc.Error {
+	seen, err := broker.ABCIMethods().SeenToken(tokenCommitment)
+	if err != nil {
+		return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not check token use"}
+	}
+	if !seen.Seen() {
+		ctx, cancel := context.WithTimeout(c, time.Duration(requestTimer)*time.Second)
+		defer cancel()
+		hash, err := broker.TendermintMethods().Broadcast(TokenSeenTx{TokenCommitment: tokenCommitment, TempPubKey: tempPubKey})
+		if errors.Is(err, messageq.ErrQueueFull) {
+			return &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending transactions, retry later"}
+		}
+		if err != nil {
+			return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
+		}
+		// The tx fails if another flow claimed the token first, which the
+		// lookup below finds out.
+		_ = waitForTransaction(hash, ctx, broker)
+		if seen, err = broker.ABCIMethods().SeenToken(tokenCommitment); err != nil || !seen.Seen() {
+			return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not record token use"}
+		}
+	}
+	if !seen.SameFlow(tempPubKey) {
+		return &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Token was already used"}
+	}
+	return nil
+}
+
 func checkTransactionResult(e []byte, query *tmquery.Query) error {
 	var txResult = tmtypes.EventDataTx{}
 	err := txResult.Unmarshal(e)
@@ -366,7 +437,7 @@ func checkTransactionResult(e []byte, query *tmquery.Query) error {
 }
 
 func (h PublicKeyLookupHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
-	broker := common.NewServiceBroker(h.eventBus, "public_lookup_handler")
+	broker := common.NewServiceBroker(h.eventBus, "public_lookup_handler").WithContext(c)
 	var p VerifierLookupParams
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -399,24 +470,11 @@ func (h PublicKeyLookupHandler) ServeJSONRPC(c context.Context, params *fastjson
 	// prepare and send response
 	result := VerifierLookupResult{}
 	for _, index := range keyIndexes {
-		publicKeyAss, err := broker.ABCIMethods().RetrieveKeyMapping(index, common.CurveName(p.Curve))
+		item, err := lookupKey(broker, index, common.CurveName(p.Curve))
 		if err != nil {
 			return nil, &jsonrpc.Error{Code: -32603, Message: fmt.Sprintf("Could not find address to key index error: %v", err)}
 		}
-		pk := publicKeyAss.PublicKey
-		var addr string
-		if p.Curve == string(common.SECP256K1) {
-			//form address eth
-			addr = crypto.PointToEthAddress(pk).String()
-		} else {
-			addr = ""
-		}
-		result.Keys = append(result.Keys, VerifierLookupItem{
-			KeyIndex: index.Text(16),
-			PubKeyX:  pk.X.Text(16),
-			PubKeyY:  pk.Y.Text(16),
-			Address:  addr,
-		})
+		result.Keys = append(result.Keys, item)
 	}
 
 	statLogger.Info("key_lookup", logger.Field{"appId": p.AppID, "verifier": p.Provider})
@@ -424,9 +482,30 @@ func (h PublicKeyLookupHandler) ServeJSONRPC(c context.Context, params *fastjson
 	return result, nil
 }
 
+// lookupKey returns the public key and, for secp256k1, the address of an
+// assigned key index.
+func lookupKey(broker *common.MessageBroker, index big.Int, curve common.CurveName) (VerifierLookupItem, error) {
+	publicKeyAss, err := broker.ABCIMethods().RetrieveKeyMapping(index, curve)
+	if err != nil {
+		return VerifierLookupItem{}, err
+	}
+	pk := publicKeyAss.PublicKey
+	var addr string
+	if curve == common.SECP256K1 {
+		//form address eth
+		addr = crypto.PointToEthAddress(pk).String()
+	}
+	return VerifierLookupItem{
+		KeyIndex: index.Text(16),
+		PubKeyX:  pk.X.Text(16),
+		PubKeyY:  pk.Y.Text(16),
+		Address:  addr,
+	}, nil
+}
+
 func (h KeyCommitmentRequestHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
 
-	broker := common.NewServiceBroker(h.bus, "commitment_request_handler")
+	broker := common.NewServiceBroker(h.bus, "commitment_request_handler").WithContext(c)
 	log.WithField("params", params).Debug("CommitmentRequestHandler")
 	var p CommitmentRequestParams
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
@@ -441,7 +520,10 @@ func (h KeyCommitmentRequestHandler) ServeJSONRPC(c context.Context, params *fas
 		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Incorrect message prefix"}
 	}
 
-	found := broker.CacheMethods().TokenCommitExists(verifierIdentifier, tokenCommitment)
+	found, err := broker.CacheMethods().TokenCommitExists(verifierIdentifier, tokenCommitment)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
 	if found {
 		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Duplicate token found"}
 	}
@@ -451,7 +533,14 @@ func (h KeyCommitmentRequestHandler) ServeJSONRPC(c context.Context, params *fas
 		Y: *secp256k1.HexToBigInt(p.TempPubY),
 	}
 
-	broker.CacheMethods().RecordTokenCommit(verifierIdentifier, tokenCommitment, tempPubKey)
+	// Tokens used in another flow would be refused at the share request.
+	if seen, err := broker.ABCIMethods().SeenToken(tokenCommitment); err == nil && seen.Seen() && !seen.SameFlow(tempPubKey) {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Token was already used"}
+	}
+
+	if err := broker.CacheMethods().RecordTokenCommit(verifierIdentifier, tokenCommitment, tempPubKey); err != nil {
+		return nil, &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending token commitments, retry later"}
+	}
 
 	// sign data
 	commitmentRequestResultData := CommitmentRequestResultData{
@@ -463,9 +552,16 @@ func (h KeyCommitmentRequestHandler) ServeJSONRPC(c context.Context, params *fas
 		strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
-	k := broker.ChainMethods().GetSelfPrivateKey()
-	pk := broker.ChainMethods().GetSelfPublicKey()
-	sig := crypto.SignData([]byte(commitmentRequestResultData.ToString()), crypto.BigIntToECDSAPrivateKey(k))
+	data := []byte(commitmentRequestResultData.ToString())
+	pk, err := broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	rawSig, err := broker.ChainMethods().SelfSignData(data)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	sig := crypto.SignatureFromRaw(data, rawSig)
 	res := CommitmentRequestResult{
 		Signature: crypto.SigToHex(sig),
 		Data:      commitmentRequestResultData.ToString(),
@@ -476,177 +572,270 @@ func (h KeyCommitmentRequestHandler) ServeJSONRPC(c context.Context, params *fas
 	return res, nil
 }
 
-func (h KeyShareRequestHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+// verifiedShareItem is a share request item whose token checked out, with a
+// threshold of node signatures on its commitment.
+type verifiedShareItem struct {
+	ShareRequestItem
+	Verifier        string
+	VerifierID      string
+	TokenCommitment string
+	KeyIndexes      []big.Int
+	// TempPubKey is the key of the commitment flow, which shares are
+	// encrypted to.
+	TempPubKey common.Point
+}
 
-	broker := common.NewServiceBroker(h.bus, "share_request_handler")
-	epoch := broker.ChainMethods().GetCurrentEpoch()
-	epochInfo, err := broker.ChainMethods().GetEpochInfo(epoch, false)
+// verifyShareRequestItem verifies the token of a share request item and the
+// node signatures on its commitment, and claims the token for the flow.
+func verifyShareRequestItem(c context.Context, broker *common.MessageBroker, rawItem fastjson.RawMessage, nodeList []common.NodeReference, threshold int) (*verifiedShareItem, *jsonrpc.Error) {
+	var parsedVerifierParams ShareRequestItem
+	err := fastjson.Unmarshal(rawItem, &parsedVerifierParams)
 	if err != nil {
-		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while current epoch"}
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while parsing sharerequestitem"}
 	}
-
-	var p ShareRequestParams
-	if err := jsonrpc.Unmarshal(params, &p); err != nil {
-		return nil, err
+	log.WithField("parsedVerifierParams", (parsedVerifierParams)).Debug("ShareRequestHandler:Unmarshal()")
+	jsonMap := make(map[string]interface{})
+	err = fastjson.Unmarshal(rawItem, &jsonMap)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while parsing jsonmap"}
+	}
+	delete(jsonMap, "nodesignatures")
+	redactedRawItem, err := fastjson.Marshal(jsonMap)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while marshalling" + err.Error()}
+	}
+	partitioned, err := tendermint.GetAppKeyPartition(broker, parsedVerifierParams.AppID)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while getting a partition" + err.Error()}
 	}
 
-	threshold := int(epochInfo.K.Int64())
-	allKeyIndexes := make(map[string]big.Int)    // String keyindex => keyindex
-	allValidVerifierIDs := make(map[string]bool) // verifier + pcmn.Delimiter1 + verifierIDs => bool
-	var pubKey common.Point
-
-	nodeList := broker.ChainMethods().AwaitCompleteNodeList(epoch)
+	var verified bool
+	var userID string
 
-	curve := common.SECP256K1
-	// For Each VerifierItem we check its validity
-	for _, rawItem := range p.Item {
-		var parsedVerifierParams ShareRequestItem
-		err = fastjson.Unmarshal(rawItem, &parsedVerifierParams)
-		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while parsing sharerequestitem"}
-		}
-		curve = common.CurveName(parsedVerifierParams.Curve)
-		log.WithField("parsedVerifierParams", (parsedVerifierParams)).Debug("ShareRequestHandler:Unmarshal()")
-		jsonMap := make(map[string]interface{})
-		err = fastjson.Unmarshal(rawItem, &jsonMap)
+	if !partitioned {
+		serialized, err := fastjson.Marshal(common.GenericVerifierData{
+			Provider: "global_key_proxy",
+			UserID:   parsedVerifierParams.UserID,
+			AppID:    parsedVerifierParams.AppID,
+			Token:    parsedVerifierParams.IDToken,
+		})
 		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while parsing jsonmap"}
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying via the proxy:" + err.Error()}
 		}
-		delete(jsonMap, "nodesignatures")
-		redactedRawItem, err := fastjson.Marshal(jsonMap)
+		verified, userID, err = broker.VerifierMethods().Verify((*bijson.RawMessage)(&serialized))
 		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while marshalling" + err.Error()}
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying params: " + err.Error()}
 		}
-		partitioned, err := tendermint.GetAppKeyPartition(broker, parsedVerifierParams.AppID)
+	} else {
+		verified, userID, err = broker.VerifierMethods().Verify((*bijson.RawMessage)(&redactedRawItem))
 		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while getting a partition" + err.Error()}
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying params: " + err.Error()}
 		}
+	}
 
-		var verified bool
-		var userID string
-
-		if !partitioned {
-			serialized, err := fastjson.Marshal(common.GenericVerifierData{
-				Provider: "global_key_proxy",
-				UserID:   parsedVerifierParams.UserID,
-				AppID:    parsedVerifierParams.AppID,
-				Token:    parsedVerifierParams.IDToken,
+	if !verified {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Could not verify params"}
+	}
+	// Validate signatures
+	var validSignatures []ValidatedNodeSignature
+	for i := 0; i < len(parsedVerifierParams.NodeSignatures); i++ {
+		nodeRef, err := parsedVerifierParams.NodeSignatures[i].NodeValidation(nodeList)
+		if err == nil {
+			validSignatures = append(validSignatures, ValidatedNodeSignature{
+				parsedVerifierParams.NodeSignatures[i],
+				*nodeRef.Index,
 			})
-			if err != nil {
-				return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying via the proxy:" + err.Error()}
-			}
-			verified, userID, err = broker.VerifierMethods().Verify((*bijson.RawMessage)(&serialized))
-			if err != nil {
-				return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying params: " + err.Error()}
-			}
 		} else {
-			verified, userID, err = broker.VerifierMethods().Verify((*bijson.RawMessage)(&redactedRawItem))
-			if err != nil {
-				return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while verifying params: " + err.Error()}
-			}
+			log.WithError(err).Error("could not validate signatures")
 		}
-
-		if !verified {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Could not verify params"}
-		}
-		// Validate signatures
-		var validSignatures []ValidatedNodeSignature
-		for i := 0; i < len(parsedVerifierParams.NodeSignatures); i++ {
-			nodeRef, err := parsedVerifierParams.NodeSignatures[i].NodeValidation(nodeList)
-			if err == nil {
-				validSignatures = append(validSignatures, ValidatedNodeSignature{
-					parsedVerifierParams.NodeSignatures[i],
-					*nodeRef.Index,
-				})
-			} else {
-				log.WithError(err).Error("could not validate signatures")
-			}
+	}
+	// Check if we have threshold number of signatures
+	if len(validSignatures) < threshold {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Not enough valid signatures. Only " + strconv.Itoa(len(validSignatures)) + "valid signatures found."}
+	}
+	// Find common data string, and filter valid signatures on the wrong data
+	// this is to prevent nodes from submitting valid signatures on wrong data
+	commonDataMap := make(map[string]int)
+	for i := 0; i < len(validSignatures); i++ {
+		var commitmentRequestResultData CommitmentRequestResultData
+		ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
+		if !ok || err != nil {
+			log.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
 		}
-		// Check if we have threshold number of signatures
-		if len(validSignatures) < threshold {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Not enough valid signatures. Only " + strconv.Itoa(len(validSignatures)) + "valid signatures found."}
-		}
-		// Find common data string, and filter valid signatures on the wrong data
-		// this is to prevent nodes from submitting valid signatures on wrong data
-		commonDataMap := make(map[string]int)
-		for i := 0; i < len(validSignatures); i++ {
-			var commitmentRequestResultData CommitmentRequestResultData
-			ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
-			if !ok || err != nil {
-				log.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
-			}
-			stringData := strings.Join([]string{
-				commitmentRequestResultData.MessagePrefix,
-				commitmentRequestResultData.TokenCommitment,
-				commitmentRequestResultData.VerifierIdentifier,
-			}, common.Delimiter1)
-			commonDataMap[stringData]++
-		}
-		var commonDataString string
-		var commonDataCount int
-		for k, v := range commonDataMap {
-			if v > commonDataCount {
-				commonDataString = k
-			}
+		stringData := strings.Join([]string{
+			commitmentRequestResultData.MessagePrefix,
+			commitmentRequestResultData.TokenCommitment,
+			commitmentRequestResultData.VerifierIdentifier,
+		}, common.Delimiter1)
+		commonDataMap[stringData]++
+	}
+	var commonDataString string
+	var commonDataCount int
+	for k, v := range commonDataMap {
+		if v > commonDataCount {
+			commonDataString = k
 		}
-		var validCommonSignatures []ValidatedNodeSignature
-		for i := 0; i < len(validSignatures); i++ {
-			var commitmentRequestResultData CommitmentRequestResultData
-			ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
-			if !ok || err != nil {
-				log.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
-			}
-			stringData := strings.Join([]string{
-				commitmentRequestResultData.MessagePrefix,
-				commitmentRequestResultData.TokenCommitment,
-				commitmentRequestResultData.VerifierIdentifier,
-			}, common.Delimiter1)
-			if stringData == commonDataString {
-				validCommonSignatures = append(validCommonSignatures, validSignatures[i])
-			}
+	}
+	var validCommonSignatures []ValidatedNodeSignature
+	for i := 0; i < len(validSignatures); i++ {
+		var commitmentRequestResultData CommitmentRequestResultData
+		ok, err := commitmentRequestResultData.FromString(validSignatures[i].Data)
+		if !ok || err != nil {
+			log.WithField("ok", ok).WithError(err).Error("could not get commitmentRequestResultData from string")
 		}
-		if len(validCommonSignatures) < threshold {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Not enough valid signatures on the same data, " + strconv.Itoa(len(validCommonSignatures)) + " valid signatures."}
+		stringData := strings.Join([]string{
+			commitmentRequestResultData.MessagePrefix,
+			commitmentRequestResultData.TokenCommitment,
+			commitmentRequestResultData.VerifierIdentifier,
+		}, common.Delimiter1)
+		if stringData == commonDataString {
+			validCommonSignatures = append(validCommonSignatures, validSignatures[i])
 		}
+	}
+	if len(validCommonSignatures) < threshold {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Not enough valid signatures on the same data, " + strconv.Itoa(len(validCommonSignatures)) + " valid signatures."}
+	}
 
-		commonData := strings.Split(commonDataString, common.Delimiter1)
+	commonData := strings.Split(commonDataString, common.Delimiter1)
 
-		if len(commonData) != 3 {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Could not parse common data"}
-		}
+	if len(commonData) != 3 {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Could not parse common data"}
+	}
 
-		commonTokenCommitment := commonData[1]
-		commonVerifierIdentifier := commonData[2]
+	commonTokenCommitment := commonData[1]
+	commonVerifierIdentifier := commonData[2]
 
-		// Lookup verifier and
-		// verify that hash of token = tokenCommitment
-		cleanedToken, err := broker.VerifierMethods().CleanToken(commonVerifierIdentifier, parsedVerifierParams.IDToken)
-		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error when cleaning token " + err.Error()}
+	// Lookup verifier and
+	// verify that hash of token = tokenCommitment
+	cleanedToken, err := broker.VerifierMethods().CleanToken(commonVerifierIdentifier, parsedVerifierParams.IDToken)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error when cleaning token " + err.Error()}
+	}
+	if hex.EncodeToString(secp256k1.Keccak256([]byte(cleanedToken))) != commonTokenCommitment {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Token commitment and token are not compatible"}
+	}
+
+	keyIndexes, err := broker.ABCIMethods().GetIndexesFromVerifierID(commonVerifierIdentifier,
+		userID, parsedVerifierParams.AppID, common.CurveName(parsedVerifierParams.Curve))
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("share request could not retrieve keyIndexes: %v", err)}
+	}
+
+	pubKey, err := broker.CacheMethods().GetTokenCommitKey(commonVerifierIdentifier, commonTokenCommitment)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	if pubKey.X.Sign() == 0 && pubKey.Y.Sign() == 0 {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Token commitment not found"}
+	}
+	if rpcErr := claimToken(c, broker, commonTokenCommitment, pubKey); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return &verifiedShareItem{
+		ShareRequestItem: parsedVerifierParams,
+		Verifier:         commonVerifierIdentifier,
+		VerifierID:       userID,
+		TokenCommitment:  commonTokenCommitment,
+		KeyIndexes:       keyIndexes,
+		TempPubKey:       pubKey,
+	}, nil
+}
+
+// flowKey returns the key the shares of a request are encrypted to. Each item
+// claimed its token for the flow of its temporary key, so every item has to
+// come from the same flow.
+func flowKey(items []*verifiedShareItem) (common.Point, error) {
+	var key common.Point
+	for i, item := range items {
+		if i > 0 && (key.X.Cmp(&item.TempPubKey.X) != 0 || key.Y.Cmp(&item.TempPubKey.Y) != 0) {
+			return common.Point{}, errors.New("items come from different commitment flows")
+		}
+		key = item.TempPubKey
+	}
+	return key, nil
+}
+
+// releaseVerifiers returns the verifiers the key at index is requested
+// through. Every item has to resolve to the key, so that items of another
+// user cannot count towards the app policy or the recovery of the key.
+func releaseVerifiers(items []*verifiedShareItem, index big.Int) ([]string, error) {
+	var verifiers []string
+	for _, item := range items {
+		resolves := false
+		for _, keyIndex := range item.KeyIndexes {
+			resolves = resolves || keyIndex.Cmp(&index) == 0
 		}
-		if hex.EncodeToString(secp256k1.Keccak256([]byte(cleanedToken))) != commonTokenCommitment {
-			return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Token commitment and token are not compatible"}
+		if !resolves {
+			return nil, errors.New("items resolve to different keys")
 		}
+		verifiers = append(verifiers, item.Verifier)
+	}
+	return verifiers, nil
+}
 
-		keyIndexes, err := broker.ABCIMethods().GetIndexesFromVerifierID(commonVerifierIdentifier,
-			userID, parsedVerifierParams.AppID, common.CurveName(parsedVerifierParams.Curve))
-		if err != nil {
-			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("share request could not retrieve keyIndexes: %v", err)}
+func (h KeyShareRequestHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+
+	broker := common.NewServiceBroker(h.bus, "share_request_handler").WithContext(c)
+	epoch, err := broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	epochInfo, err := broker.ChainMethods().GetEpochInfo(epoch, false)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while current epoch"}
+	}
+
+	var p ShareRequestParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	threshold := int(epochInfo.K.Int64())
+	allKeyIndexes := make(map[string]big.Int)        // String keyindex => keyindex
+	allValidVerifierIDs := make(map[string]bool)     // verifier + pcmn.Delimiter1 + verifierIDs => bool
+	releases := make(map[string]common.ShareRelease) // String keyindex => audit record
+	var items []*verifiedShareItem
+	var pubKey common.Point
+
+	nodeList, err := broker.ChainMethods().AwaitCompleteNodeList(epoch)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+
+	curve := common.SECP256K1
+	// For Each VerifierItem we check its validity
+	for _, rawItem := range p.Item {
+		item, rpcErr := verifyShareRequestItem(c, broker, rawItem, nodeList, threshold)
+		if rpcErr != nil {
+			return nil, rpcErr
 		}
+		curve = common.CurveName(item.Curve)
 
 		// Add to overall list and valid verifierIDs
-		for _, index := range keyIndexes {
+		for _, index := range item.KeyIndexes {
 			allKeyIndexes[index.Text(16)] = index
+			releases[index.Text(16)] = common.ShareRelease{
+				KeyIndex:        index.Text(16),
+				AppID:           item.AppID,
+				Verifier:        item.Verifier,
+				VerifierIDHash:  hex.EncodeToString(secp256k1.Keccak256([]byte(item.VerifierID))),
+				TokenCommitment: item.TokenCommitment,
+			}
 		}
 
 		statLogger.Info("key_share_fetch", logger.Field{
-			"appId": parsedVerifierParams.AppID,
-			"Id":    userID,
+			"appId": item.AppID,
+			"Id":    item.VerifierID,
 		})
 
-		pubKey = broker.CacheMethods().GetTokenCommitKey(commonVerifierIdentifier, commonTokenCommitment)
+		allValidVerifierIDs[strings.Join([]string{item.UserID, item.VerifierID}, common.Delimiter1)] = true
+		items = append(items, item)
+	}
 
-		allValidVerifierIDs[strings.Join([]string{parsedVerifierParams.UserID, userID}, common.Delimiter1)] = true
+	pubKey, err = flowKey(items)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: err.Error()}
 	}
 
 	response := ShareRequestResult{}
@@ -659,19 +848,55 @@ func (h KeyShareRequestHandler) ServeJSONRPC(c context.Context, params *fastjson
 	})
 	if len(allKeyIndexesSorted) > 0 {
 		index := allKeyIndexesSorted[0]
+		release := releases[index.Text(16)]
+		refuse := func(reason string) {
+			telemetry.IncrementShareReqFail()
+			release.Outcome, release.Reason = common.ShareRefused, reason
+			if err := broker.AuditMethods().RecordShareRelease(release); err != nil {
+				log.WithError(err).Error("could not record refused share request")
+			}
+		}
+		verifiers, err := releaseVerifiers(items, index)
+		if err != nil {
+			refuse(err.Error())
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: err.Error()}
+		}
 		pubKeyAccessStructure, err := broker.ABCIMethods().RetrieveKeyMapping(index, curve)
 		log.WithFields(log.Fields{
 			"publicX": pubKeyAccessStructure.PublicKey.X,
 			"publicY": pubKeyAccessStructure.PublicKey.Y,
 		}).Debug("public_key")
 		if err != nil {
-			telemetry.IncrementShareReqFail()
+			refuse("could not retrieve access structure")
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not retrieve access structure: %v", err)}
 		}
 
+		policy, err := broker.ABCIMethods().RetrieveAppPolicy(release.AppID)
+		if err != nil {
+			refuse("could not retrieve app policy")
+			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not retrieve app policy: %v", err)}
+		}
+		recovering := policy.Recovery != nil && policy.Recovery.OnlyBackups(verifiers)
+		if err := policy.Evaluate(common.PolicyRequest{
+			Origin:     originFrom(c),
+			Verifiers:  verifiers,
+			AssignedAt: pubKeyAccessStructure.AssignedAt,
+			Recovery:   recovering,
+			Now:        h.TimeNow(),
+		}); err != nil {
+			refuse("denied by app policy: " + err.Error())
+			return nil, &jsonrpc.Error{Code: PolicyDeniedErrorCode, Message: "Denied by app policy", Data: err.Error()}
+		}
+		if recovering {
+			if rpcErr := awaitRecovery(c, broker, release, index, curve); rpcErr != nil {
+				refuse("recovery is not releasable")
+				return nil, rpcErr
+			}
+		}
+
 		si, _, err := broker.DBMethods().RetrieveCompletedShare(index, curve)
 		if err != nil {
-			telemetry.IncrementShareReqFail()
+			refuse("could not retrieve completed share")
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not retrieve completed share"}
 		}
 
@@ -688,18 +913,26 @@ func (h KeyShareRequestHandler) ServeJSONRPC(c context.Context, params *fastjson
 		pubKeyHex := "04" + fmt.Sprintf("%064s", pubKey.X.Text(16)) + fmt.Sprintf("%064s", pubKey.Y.Text(16))
 		encrypted, metadata, err := tronCrypto.Encrypt(pubKeyHex, keyAssignment.Share)
 		if err != nil {
-			telemetry.IncrementShareReqFail()
+			refuse("could not encrypt share")
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not encrypt shares with err: %v", err)}
 		}
 
 		if metadata == nil {
-			telemetry.IncrementShareReqFail()
+			refuse("could not encrypt share")
 			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not encrypt shares, metadata nil"}
 		}
 
 		keyAssignment.Share = []byte(encrypted)
 		keyAssignment.Metadata = *metadata
 
+		// The share is only released once the release is in the audit log.
+		release.Outcome = common.ShareReleased
+		if err := broker.AuditMethods().RecordShareRelease(release); err != nil {
+			telemetry.IncrementShareReqFail()
+			log.WithError(err).Error("could not record share release")
+			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not record share release"}
+		}
+
 		response.Keys = append(response.Keys, ShareRequestResultItem{
 			Index: pubKeyAccessStructure.Index.String(),
 			PublicKey: PublicKeyHex{
@@ -718,7 +951,7 @@ func (h KeyShareRequestHandler) ServeJSONRPC(c context.Context, params *fastjson
 
 func (h ConnectionDetailsHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
 	log.WithField("ConnectionDetailsParams", string(*params)).Debug("connection details handler handling request")
-	broker := common.NewServiceBroker(h.eventBus, "connection_details_handler")
+	broker := common.NewServiceBroker(h.eventBus, "connection_details_handler").WithContext(c)
 	var p ConnectionDetailsParams
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -735,8 +968,14 @@ func (h ConnectionDetailsHandler) ServeJSONRPC(c context.Context, params *fastjs
 	if !valid {
 		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "invalid connection details message"}
 	}
-	tmp2pConnection := broker.ChainMethods().GetTMP2PConnection()
-	p2pConnection := broker.ChainMethods().GetP2PConnection()
+	tmp2pConnection, err := broker.ChainMethods().GetTMP2PConnection()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	p2pConnection, err := broker.ChainMethods().GetP2PConnection()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
 	log.WithFields(log.Fields{
 		tmp2pConnection: tmp2pConnection,
 		p2pConnection:   p2pConnection,
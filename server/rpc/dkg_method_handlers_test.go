@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+func TestReleaseVerifiers(t *testing.T) {
+	key, otherKey := *big.NewInt(1), *big.NewInt(2)
+	item := func(verifier string, keyIndexes ...big.Int) *verifiedShareItem {
+		return &verifiedShareItem{Verifier: verifier, KeyIndexes: keyIndexes}
+	}
+	policy := common.AppPolicy{AppID: "app", RequiredVerifiers: []string{"google", "email"}}
+
+	verifiers, err := releaseVerifiers([]*verifiedShareItem{item("google", key), item("email", key)}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Evaluate(common.PolicyRequest{Verifiers: verifiers}); err != nil {
+		t.Errorf("request through every required verifier was denied: %v", err)
+	}
+
+	// The email item of another user must not satisfy the policy of the key.
+	mixed := []*verifiedShareItem{item("google", key), item("email", otherKey)}
+	if _, err := releaseVerifiers(mixed, key); err == nil {
+		t.Error("items resolving to different keys were accepted")
+	}
+	if _, err := releaseVerifiers([]*verifiedShareItem{item("google", key), item("email")}, key); err == nil {
+		t.Error("item of a user without a key was accepted")
+	}
+}
+
+func TestRecoveringOnlyCountsItemsOfTheKey(t *testing.T) {
+	key, otherKey := *big.NewInt(1), *big.NewInt(2)
+	recovery := &common.RecoveryPolicy{BackupVerifiers: []string{"email"}, Delay: 60}
+
+	verifiers, err := releaseVerifiers([]*verifiedShareItem{{Verifier: "email", KeyIndexes: []big.Int{key}}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recovery.OnlyBackups(verifiers) {
+		t.Error("request through backup verifiers only is not a recovery")
+	}
+
+	// The primary verifier of another user must not skip the recovery delay.
+	mixed := []*verifiedShareItem{
+		{Verifier: "email", KeyIndexes: []big.Int{key}},
+		{Verifier: "google", KeyIndexes: []big.Int{otherKey}},
+	}
+	if _, err := releaseVerifiers(mixed, key); err == nil {
+		t.Error("primary item of another key was counted for the release")
+	}
+}
+
+func TestFlowKey(t *testing.T) {
+	flow := common.Point{X: *big.NewInt(1), Y: *big.NewInt(2)}
+	other := common.Point{X: *big.NewInt(1), Y: *big.NewInt(3)}
+
+	key, err := flowKey([]*verifiedShareItem{{TempPubKey: flow}, {TempPubKey: flow}})
+	if err != nil || key.Y.Cmp(&flow.Y) != 0 {
+		t.Errorf("flowKey() = %v, %v", key, err)
+	}
+	// An item of another flow must not pick the key the shares go to.
+	if _, err := flowKey([]*verifiedShareItem{{TempPubKey: flow}, {TempPubKey: other}}); err == nil {
+		t.Error("items of different flows were accepted")
+	}
+}
@@ -15,6 +15,9 @@ const (
 	KeyShareRequestMethod      = "KeyShareRequest"
 	PublicKeyLookupMethod      = "PublicKeyLookup"
 	HealthMethod               = "HealthCheck"
+	PasskeyRegisterMethod      = "PasskeyRegister"
+	AppPolicySetMethod         = "AppPolicySet"
+	RecoveryCancelMethod       = "RecoveryCancel"
 )
 
 type (
@@ -47,7 +50,7 @@ type (
 func SetUpJRPCHandler(eventBus eventbus.Bus) (*jsonrpc.MethodRepository, error) {
 	mr := jsonrpc.NewMethodRepository()
 
-	if err := mr.RegisterMethod(HealthMethod, HealthHandler{}, HealthParams{}, HealthResult{}); err != nil {
+	if err := mr.RegisterMethod(HealthMethod, HealthHandler{eventBus}, HealthParams{}, HealthResult{}); err != nil {
 		return nil, err
 	}
 
@@ -71,5 +74,17 @@ func SetUpJRPCHandler(eventBus eventbus.Bus) (*jsonrpc.MethodRepository, error)
 		return nil, err
 	}
 
+	if err := mr.RegisterMethod(PasskeyRegisterMethod, PasskeyRegisterHandler{eventBus}, PasskeyRegisterParams{}, PasskeyRegisterResult{}); err != nil {
+		return nil, err
+	}
+
+	if err := mr.RegisterMethod(AppPolicySetMethod, AppPolicySetHandler{eventBus}, AppPolicySetParams{}, AppPolicySetResult{}); err != nil {
+		return nil, err
+	}
+
+	if err := mr.RegisterMethod(RecoveryCancelMethod, RecoveryCancelHandler{eventBus}, ShareRequestParams{}, RecoveryCancelResult{}); err != nil {
+		return nil, err
+	}
+
 	return mr, nil
 }
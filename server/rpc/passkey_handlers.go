@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	fastjson "github.com/goccy/go-json"
+	"github.com/osamingo/jsonrpc/v2"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/arcana-network/dkgnode/tendermint/messageq"
+	"github.com/arcana-network/dkgnode/webauthn"
+)
+
+type (
+	PasskeyRegisterHandler struct {
+		bus eventbus.Bus
+	}
+	PasskeyRegisterParams struct {
+		AppID        string `json:"app_id"`
+		UserID       string `json:"user_id"`
+		CredentialID string `json:"credential_id"`
+		// PublicKey is the base64url SubjectPublicKeyInfo of the credential,
+		// as returned by getPublicKey().
+		PublicKey string `json:"public_key"`
+		// Algorithm is the COSE algorithm, as returned by
+		// getPublicKeyAlgorithm().
+		Algorithm int `json:"algorithm"`
+		// Approval is an assertion by a passkey the user already registered,
+		// over the challenge base64url(sha256(credential_id || public_key)).
+		Approval *webauthn.Assertion `json:"approval,omitempty"`
+		// Proof is a share request item of the same user through a primary
+		// verifier of the app, which the first passkey of a user is
+		// registered with. Its token is used up like in a share request.
+		Proof fastjson.RawMessage `json:"proof,omitempty"`
+	}
+	PasskeyRegisterResult struct {
+		TxHash string `json:"tx_hash"`
+	}
+	PasskeyRegistrationTx struct {
+		AppID        string
+		UserID       string
+		CredentialID string
+		PublicKey    []byte
+		Algorithm    int
+		Approval     *webauthn.Assertion
+		RPID         string
+		Verifier     string
+	}
+)
+
+func (h PasskeyRegisterHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p PasskeyRegisterParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(c, time.Duration(requestTimer)*time.Second)
+	defer cancel()
+	broker := common.NewServiceBroker(h.bus, "passkey_register_handler").WithContext(ctx)
+
+	if p.AppID == "" || p.UserID == "" || p.CredentialID == "" {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "AppID, UserID and CredentialID are required"}
+	}
+	// The relying party ID goes in the tx, as the BFT app cannot look it up
+	// on chain.
+	verifierParams, err := broker.ChainMethods().GetClientIDViaVerifier(p.AppID, common.PasskeyVerifier)
+	if err != nil || verifierParams.ClientID == "" || verifierParams.Domain == "" {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "App does not accept passkeys"}
+	}
+	var verifier string
+	if len(p.Proof) > 0 {
+		item, rpcErr := verifyRegistrationProof(c, broker, p)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		verifier = item.Verifier
+	} else if p.Approval == nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Either an approval or a proof is required"}
+	}
+	publicKey, err := webauthn.Decode(p.PublicKey)
+	if err == nil {
+		_, err = webauthn.ParsePublicKey(publicKey, p.Algorithm)
+	}
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Invalid public key: " + err.Error()}
+	}
+
+	hash, err := broker.TendermintMethods().Broadcast(PasskeyRegistrationTx{
+		AppID:        p.AppID,
+		UserID:       p.UserID,
+		CredentialID: p.CredentialID,
+		PublicKey:    publicKey,
+		Algorithm:    p.Algorithm,
+		Approval:     p.Approval,
+		RPID:         verifierParams.Domain,
+		Verifier:     verifier,
+	})
+	if errors.Is(err, messageq.ErrQueueFull) {
+		return nil, &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending transactions, retry later"}
+	}
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
+	}
+	if rpcErr := waitForTransaction(hash, ctx, broker); rpcErr != nil {
+		return nil, rpcErr
+	}
+	log.WithField("appId", p.AppID).Info("passkey registered")
+	return PasskeyRegisterResult{TxHash: hash.String()}, nil
+}
+
+// verifyRegistrationProof verifies the share request item a passkey is
+// registered with, and checks that it signs in the same user of the app
+// through a primary verifier.
+func verifyRegistrationProof(c context.Context, broker *common.MessageBroker, p PasskeyRegisterParams) (*verifiedShareItem, *jsonrpc.Error) {
+	epoch, err := broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	epochInfo, err := broker.ChainMethods().GetEpochInfo(epoch, false)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while current epoch"}
+	}
+	nodeList, err := broker.ChainMethods().AwaitCompleteNodeList(epoch)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	item, rpcErr := verifyShareRequestItem(c, broker, p.Proof, nodeList, int(epochInfo.K.Int64()))
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if item.AppID != p.AppID || item.VerifierID != p.UserID || item.Verifier == common.PasskeyVerifier {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Proof is not of the same user through a primary verifier"}
+	}
+	return item, nil
+}
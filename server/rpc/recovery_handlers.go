@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	fastjson "github.com/goccy/go-json"
+	"github.com/osamingo/jsonrpc/v2"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/arcana-network/dkgnode/tendermint/messageq"
+)
+
+type (
+	RecoveryCancelHandler struct {
+		bus eventbus.Bus
+	}
+	RecoveryCancelResult struct {
+		// Keys are the indexes of the keys whose recovery was cancelled.
+		Keys []string `json:"keys"`
+	}
+	RecoveryTx struct {
+		Cancel   bool
+		AppID    string
+		KeyIndex big.Int
+		Curve    common.CurveName
+		Verifier string
+	}
+)
+
+// broadcastRecovery broadcasts a recovery tx and waits for it to be
+// committed.
+func broadcastRecovery(c context.Context, broker *common.MessageBroker, tx RecoveryTx) *jsonrpc.Error {
+	ctx, cancel := context.WithTimeout(c, time.Duration(requestTimer)*time.Second)
+	defer cancel()
+	hash, err := broker.TendermintMethods().Broadcast(tx)
+	if errors.Is(err, messageq.ErrQueueFull) {
+		return &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many pending transactions, retry later"}
+	}
+	if err != nil {
+		return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Unable to broadcast: " + err.Error()}
+	}
+	return waitForTransaction(hash, ctx, broker)
+}
+
+// retrieveRecovery returns the latest recovery of a key with the time of the
+// last committed block. Recoveries are timed by the blocks they were committed
+// in, so comparing them with the clock of this node would let a node whose
+// clock runs ahead release shares early.
+func retrieveRecovery(broker *common.MessageBroker, keyIndex big.Int, curve common.CurveName) (common.Recovery, time.Time, *jsonrpc.Error) {
+	recovery, err := broker.ABCIMethods().RetrieveRecovery(keyIndex, curve)
+	if err != nil {
+		return recovery, time.Time{}, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not retrieve recovery"}
+	}
+	blockTime, err := broker.ABCIMethods().LastBlockTime()
+	if err != nil {
+		return recovery, time.Time{}, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not retrieve block time"}
+	}
+	return recovery, blockTime, nil
+}
+
+// awaitRecovery lets a share request through backup verifiers only release
+// the share once the recovery of the key is releasable. It starts a recovery
+// if the key has none pending or releasable, and otherwise returns when the
+// share will be released.
+func awaitRecovery(c context.Context, broker *common.MessageBroker, release common.ShareRelease, keyIndex big.Int, curve common.CurveName) *jsonrpc.Error {
+	recovery, now, rpcErr := retrieveRecovery(broker, keyIndex, curve)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	switch recovery.State(now) {
+	case common.RecoveryReleasable:
+		return nil
+	case common.RecoveryNone, common.RecoveryCancelled, common.RecoveryExpired:
+		// Every node starts the recovery, the first tx committed does.
+		_ = broadcastRecovery(c, broker, RecoveryTx{
+			AppID:    release.AppID,
+			KeyIndex: keyIndex,
+			Curve:    curve,
+			Verifier: release.Verifier,
+		})
+		recovery, now, rpcErr = retrieveRecovery(broker, keyIndex, curve)
+		if rpcErr != nil || !recovery.Started(now) {
+			return &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "could not start recovery"}
+		}
+		if recovery.State(now) == common.RecoveryReleasable {
+			return nil
+		}
+		log.WithFields(log.Fields{
+			"appId":    release.AppID,
+			"keyIndex": release.KeyIndex,
+			"verifier": release.Verifier,
+		}).Info("recovery started")
+	}
+	return &jsonrpc.Error{
+		Code:    RecoveryPendingErrorCode,
+		Message: "Recovery pending",
+		Data:    fmt.Sprintf("share is released from %s unless the recovery is cancelled", recovery.ReleasableAt.UTC().Format(time.RFC3339)),
+	}
+}
+
+// ServeJSONRPC cancels the recoveries of the keys of the items, which must be
+// verified through primary verifiers of the user, as share requests are.
+func (h RecoveryCancelHandler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	broker := common.NewServiceBroker(h.bus, "recovery_cancel_handler").WithContext(c)
+	epoch, err := broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+	epochInfo, err := broker.ChainMethods().GetEpochInfo(epoch, false)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Internal error", Data: "Error occurred while current epoch"}
+	}
+
+	var p ShareRequestParams
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	threshold := int(epochInfo.K.Int64())
+	nodeList, err := broker.ChainMethods().AwaitCompleteNodeList(epoch)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: err.Error()}
+	}
+
+	result := RecoveryCancelResult{Keys: []string{}}
+	for _, rawItem := range p.Item {
+		item, rpcErr := verifyShareRequestItem(c, broker, rawItem, nodeList, threshold)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		policy, err := broker.ABCIMethods().RetrieveAppPolicy(item.AppID)
+		if err != nil {
+			return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: fmt.Sprintf("could not retrieve app policy: %v", err)}
+		}
+		if policy.Recovery == nil {
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "App does not allow recovery"}
+		}
+		if policy.Recovery.IsBackup(item.Verifier) {
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "Recovery can only be cancelled through a primary verifier"}
+		}
+
+		curve := common.CurveName(item.Curve)
+		for _, index := range item.KeyIndexes {
+			recovery, now, rpcErr := retrieveRecovery(broker, index, curve)
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			if !recovery.Started(now) {
+				continue
+			}
+			rpcErr = broadcastRecovery(c, broker, RecoveryTx{
+				Cancel:   true,
+				AppID:    item.AppID,
+				KeyIndex: index,
+				Curve:    curve,
+				Verifier: item.Verifier,
+			})
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			log.WithFields(log.Fields{
+				"appId":    item.AppID,
+				"keyIndex": index.Text(16),
+			}).Info("recovery cancelled")
+			result.Keys = append(result.Keys, index.Text(16))
+		}
+	}
+	return result, nil
+}
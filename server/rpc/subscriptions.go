@@ -0,0 +1,411 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/gorilla/websocket"
+	"github.com/osamingo/jsonrpc/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SubscribeMethod         = "subscribe"
+	UnsubscribeMethod       = "unsubscribe"
+	KeyAssignedNotification = "key_assigned"
+
+	// maxConnSubscriptions caps the subscriptions of one connection.
+	maxConnSubscriptions = 32
+	// recentTxTTL is how long assignment results are kept for clients that
+	// subscribe to a tx hash after the tx was committed.
+	recentTxTTL = 5 * time.Minute
+
+	wsMaxMessageBytes = 4096
+	wsWriteTimeout    = 10 * time.Second
+	wsPongTimeout     = 60 * time.Second
+	wsPingInterval    = 25 * time.Second
+)
+
+type (
+	// SubscribeParams name either the assignment of a verifier id, or the
+	// tx hash returned by an async KeyAssign.
+	SubscribeParams struct {
+		Provider string `json:"provider"`
+		UserID   string `json:"user_id"`
+		AppID    string `json:"app_id"`
+		Curve    string `json:"curve"`
+		TxHash   string `json:"tx_hash"`
+	}
+	UnsubscribeParams struct {
+		Subscription string `json:"subscription"`
+	}
+	// KeyAssignedParams are sent once per subscription, with the assigned
+	// key or the reason the assignment failed.
+	KeyAssignedParams struct {
+		Subscription string                `json:"subscription"`
+		Result       *VerifierLookupResult `json:"result,omitempty"`
+		Error        *jsonrpc.Error        `json:"error,omitempty"`
+	}
+
+	wsRequest struct {
+		Version string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+	wsResponse struct {
+		Version string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result,omitempty"`
+		Error   *jsonrpc.Error  `json:"error,omitempty"`
+	}
+	wsNotification struct {
+		Version string      `json:"jsonrpc"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params"`
+	}
+)
+
+type subscription struct {
+	id     string
+	key    string
+	params SubscribeParams
+	conn   *subscriberConn
+}
+
+type subscriberConn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+
+	mu   sync.Mutex
+	subs map[string]*subscription
+}
+
+type recentTx struct {
+	event common.KeyAssignedEvent
+	at    time.Time
+}
+
+// SubscriptionHandler serves the WebSocket endpoint on which clients wait
+// for key assignments instead of polling PublicKeyLookup.
+type SubscriptionHandler struct {
+	bus      eventbus.Bus
+	broker   *common.MessageBroker
+	upgrader websocket.Upgrader
+	// onEvent is kept to unsubscribe the same func from the bus.
+	onEvent func(interface{})
+
+	mu      sync.Mutex
+	waiting map[string]map[*subscription]struct{}
+	recent  map[string]recentTx
+	conns   map[*subscriberConn]struct{}
+}
+
+func NewSubscriptionHandler(bus eventbus.Bus) (*SubscriptionHandler, error) {
+	h := &SubscriptionHandler{
+		bus:    bus,
+		broker: common.NewServiceBroker(bus, "subscription_handler"),
+		upgrader: websocket.Upgrader{
+			// Keys are looked up from web apps on any origin, like the
+			// JSON-RPC endpoint allows with CORS.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		waiting: make(map[string]map[*subscription]struct{}),
+		recent:  make(map[string]recentTx),
+		conns:   make(map[*subscriberConn]struct{}),
+	}
+	h.onEvent = func(data interface{}) {
+		var event common.KeyAssignedEvent
+		if err := common.CastOrUnmarshal(data, &event); err != nil {
+			log.WithError(err).Error("SubscriptionHandler: could not read assignment event")
+			return
+		}
+		h.deliver(event)
+	}
+	if err := bus.SubscribeAsync(common.KeyAssignedTopic, h.onEvent, false); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Close stops the handler and closes the open connections.
+func (h *SubscriptionHandler) Close() {
+	_ = h.bus.Unsubscribe(common.KeyAssignedTopic, h.onEvent)
+	h.mu.Lock()
+	conns := h.conns
+	h.conns = make(map[*subscriberConn]struct{})
+	h.mu.Unlock()
+	for conn := range conns {
+		conn.ws.Close()
+	}
+}
+
+func txKey(hash string) string {
+	return "tx:" + strings.ToUpper(strings.TrimPrefix(strings.ToLower(hash), "0x"))
+}
+
+func assignmentKey(p SubscribeParams) string {
+	return "key:" + common.AssignmentKey(p.Provider, p.UserID, p.AppID, common.CurveName(p.Curve))
+}
+
+// take removes the subscriptions waiting on a key.
+func (h *SubscriptionHandler) take(key string) []*subscription {
+	var subs []*subscription
+	for sub := range h.waiting[key] {
+		subs = append(subs, sub)
+	}
+	delete(h.waiting, key)
+	return subs
+}
+
+// remove stops a subscription from waiting. It returns false when the
+// subscription was already notified or removed.
+func (h *SubscriptionHandler) remove(sub *subscription) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.waiting[sub.key][sub]; !ok {
+		return false
+	}
+	delete(h.waiting[sub.key], sub)
+	if len(h.waiting[sub.key]) == 0 {
+		delete(h.waiting, sub.key)
+	}
+	return true
+}
+
+func (h *SubscriptionHandler) deliver(event common.KeyAssignedEvent) {
+	now := time.Now()
+	h.mu.Lock()
+	for key, tx := range h.recent {
+		if now.Sub(tx.at) > recentTxTTL {
+			delete(h.recent, key)
+		}
+	}
+	h.recent[txKey(event.TxHash)] = recentTx{event: event, at: now}
+	subs := h.take(txKey(event.TxHash))
+	if event.AssignmentKey != "" {
+		subs = append(subs, h.take("key:"+event.AssignmentKey)...)
+	}
+	h.mu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+
+	result, rpcErr := h.eventResult(event)
+	for _, sub := range subs {
+		sub.conn.notify(sub, result, rpcErr)
+	}
+}
+
+func (h *SubscriptionHandler) eventResult(event common.KeyAssignedEvent) (*VerifierLookupResult, *jsonrpc.Error) {
+	if !event.OK {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Tx failed"}
+	}
+	index, ok := new(big.Int).SetString(event.KeyIndex, 16)
+	if !ok {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Invalid key index"}
+	}
+	item, err := lookupKey(h.broker, *index, event.Curve)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: -32603, Message: "Internal error", Data: "Could not find assigned key"}
+	}
+	return &VerifierLookupResult{Keys: []VerifierLookupItem{item}}, nil
+}
+
+// existing notifies a new subscription whose key was assigned before it was
+// made.
+func (h *SubscriptionHandler) existing(sub *subscription) {
+	if sub.params.TxHash != "" {
+		h.mu.Lock()
+		tx, ok := h.recent[sub.key]
+		h.mu.Unlock()
+		if ok && h.remove(sub) {
+			result, rpcErr := h.eventResult(tx.event)
+			sub.conn.notify(sub, result, rpcErr)
+		}
+		return
+	}
+
+	p := sub.params
+	curve := common.CurveName(p.Curve)
+	keyIndexes, err := h.broker.ABCIMethods().GetIndexesFromVerifierID(p.Provider, p.UserID, p.AppID, curve)
+	if err != nil || len(keyIndexes) == 0 {
+		return
+	}
+	result := VerifierLookupResult{}
+	for _, index := range keyIndexes {
+		item, err := lookupKey(h.broker, index, curve)
+		if err != nil {
+			log.WithError(err).Error("SubscriptionHandler: could not look up assigned key")
+			return
+		}
+		result.Keys = append(result.Keys, item)
+	}
+	if h.remove(sub) {
+		sub.conn.notify(sub, &result, nil)
+	}
+}
+
+func newSubscriptionID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func (h *SubscriptionHandler) subscribe(conn *subscriberConn, raw json.RawMessage) (*subscription, *jsonrpc.Error) {
+	var p SubscribeParams
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "Invalid params", Data: err.Error()}
+	}
+	sub := &subscription{id: newSubscriptionID(), params: p, conn: conn}
+	if p.TxHash != "" {
+		sub.key = txKey(p.TxHash)
+	} else {
+		if p.Provider == "" || p.UserID == "" || p.AppID == "" {
+			return nil, &jsonrpc.Error{Code: -32602, Message: "Input error", Data: "provider, user_id and app_id or tx_hash are required"}
+		}
+		if sub.params.Curve == "" {
+			sub.params.Curve = string(common.SECP256K1)
+		}
+		sub.key = assignmentKey(sub.params)
+	}
+
+	conn.mu.Lock()
+	if len(conn.subs) >= maxConnSubscriptions {
+		conn.mu.Unlock()
+		return nil, &jsonrpc.Error{Code: ServerBusyErrorCode, Message: "Server busy", Data: "Too many subscriptions on this connection"}
+	}
+	conn.subs[sub.id] = sub
+	conn.mu.Unlock()
+
+	h.mu.Lock()
+	if h.waiting[sub.key] == nil {
+		h.waiting[sub.key] = make(map[*subscription]struct{})
+	}
+	h.waiting[sub.key][sub] = struct{}{}
+	h.mu.Unlock()
+	return sub, nil
+}
+
+func (h *SubscriptionHandler) unsubscribe(conn *subscriberConn, raw json.RawMessage) (bool, *jsonrpc.Error) {
+	var p UnsubscribeParams
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return false, &jsonrpc.Error{Code: -32602, Message: "Invalid params", Data: err.Error()}
+	}
+	conn.mu.Lock()
+	sub, ok := conn.subs[p.Subscription]
+	delete(conn.subs, p.Subscription)
+	conn.mu.Unlock()
+	return ok && h.remove(sub), nil
+}
+
+func (h *SubscriptionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Debug("SubscriptionHandler: upgrade failed")
+		return
+	}
+	conn := &subscriberConn{ws: ws, subs: make(map[string]*subscription)}
+	h.mu.Lock()
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go conn.ping(done)
+	h.serve(conn)
+	close(done)
+
+	h.mu.Lock()
+	delete(h.conns, conn)
+	h.mu.Unlock()
+	conn.mu.Lock()
+	for _, sub := range conn.subs {
+		h.remove(sub)
+	}
+	conn.mu.Unlock()
+	ws.Close()
+}
+
+func (h *SubscriptionHandler) serve(conn *subscriberConn) {
+	conn.ws.SetReadLimit(wsMaxMessageBytes)
+	_ = conn.ws.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	for {
+		var req wsRequest
+		if err := conn.ws.ReadJSON(&req); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				conn.write(wsResponse{Version: "2.0", ID: json.RawMessage("null"), Error: &jsonrpc.Error{Code: -32700, Message: "Parse error"}})
+				continue
+			}
+			return
+		}
+		resp := wsResponse{Version: "2.0", ID: req.ID}
+		var sub *subscription
+		switch req.Method {
+		case SubscribeMethod:
+			sub, resp.Error = h.subscribe(conn, req.Params)
+			if sub != nil {
+				resp.Result = sub.id
+			}
+		case UnsubscribeMethod:
+			resp.Result, resp.Error = h.unsubscribe(conn, req.Params)
+		default:
+			resp.Error = &jsonrpc.Error{Code: -32601, Message: "Method not found", Data: req.Method}
+		}
+		conn.write(resp)
+		if sub != nil {
+			// After the response, so that the client knows the subscription
+			// id before it is notified.
+			go h.existing(sub)
+		}
+	}
+}
+
+func (conn *subscriberConn) write(v interface{}) {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+	_ = conn.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := conn.ws.WriteJSON(v); err != nil {
+		log.WithError(err).Debug("SubscriptionHandler: write failed")
+		conn.ws.Close()
+	}
+}
+
+func (conn *subscriberConn) notify(sub *subscription, result *VerifierLookupResult, rpcErr *jsonrpc.Error) {
+	conn.mu.Lock()
+	delete(conn.subs, sub.id)
+	conn.mu.Unlock()
+	conn.write(wsNotification{
+		Version: "2.0",
+		Method:  KeyAssignedNotification,
+		Params:  KeyAssignedParams{Subscription: sub.id, Result: result, Error: rpcErr},
+	})
+}
+
+func (conn *subscriberConn) ping(done chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			conn.writeMu.Lock()
+			err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+			conn.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/eventbus"
+	"github.com/gorilla/websocket"
+)
+
+func TestSubscribeToTxHash(t *testing.T) {
+	bus := eventbus.New()
+	h, err := NewSubscriptionHandler(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(h)
+	defer server.Close()
+	defer h.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var resp struct {
+		ID     int    `json:"id"`
+		Result string `json:"result"`
+	}
+	call := func(id int, method, params string) {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":`+string(rune('0'+id))+`,"method":"`+method+`","params":`+params+`}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := ws.ReadJSON(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.ID != id || resp.Result == "" {
+			t.Fatalf("%s response = %+v", method, resp)
+		}
+	}
+
+	call(1, SubscribeMethod, `{"tx_hash":"0xabcd"}`)
+	subscription := resp.Result
+	bus.Publish(common.KeyAssignedTopic, common.KeyAssignedEvent{TxHash: "ABCD", AssignmentKey: "key", OK: false})
+
+	var notification struct {
+		Method string            `json:"method"`
+		Params KeyAssignedParams `json:"params"`
+	}
+	if err := ws.ReadJSON(&notification); err != nil {
+		t.Fatal(err)
+	}
+	if notification.Method != KeyAssignedNotification || notification.Params.Subscription != subscription || notification.Params.Error == nil {
+		t.Errorf("notification = %+v", notification)
+	}
+
+	// The result of a committed tx is sent to late subscribers right away.
+	call(2, SubscribeMethod, `{"tx_hash":"abcd"}`)
+	if err := ws.ReadJSON(&notification); err != nil {
+		t.Fatal(err)
+	}
+	if notification.Params.Subscription != resp.Result || notification.Params.Error == nil {
+		t.Errorf("late notification = %+v", notification)
+	}
+}
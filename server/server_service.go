@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/arcana-network/dkgnode/common"
@@ -76,17 +77,28 @@ func (c *ConnectionDetailsMessage) Validate(pubKeyX, pubKeyY big.Int, sig []byte
 }
 
 type ServerService struct {
-	bus    eventbus.Bus
-	client *http.Client
-	server *http.Server
-	broker *common.MessageBroker
+	bus     eventbus.Bus
+	client  *http.Client
+	server  *http.Server
+	broker  *common.MessageBroker
+	running atomic.Bool
+	limits  *requestLimits
+	// certs is set when the server is served over TLS.
+	certs         *certReloader
+	subscriptions *rpc.SubscriptionHandler
+	gate          *drainGate
 }
 
 func New(bus eventbus.Bus) *ServerService {
-	return &ServerService{
+	s := &ServerService{
 		bus:    bus,
 		broker: common.NewServiceBroker(bus, common.SERVER_SERVICE_NAME),
+		limits: newRequestLimits(config.DefaultMaxRequestBytes, config.DefaultMaxBatchSize, nil),
 	}
+	config.OnReload(func(_, new *config.Config) {
+		s.limits.set(new.MaxRequestBytes, new.MaxBatchSize, new.RateLimits)
+	})
+	return s
 }
 
 func (s *ServerService) ID() string {
@@ -94,39 +106,82 @@ func (s *ServerService) ID() string {
 }
 
 func (s *ServerService) Start() error {
-	addr := fmt.Sprintf(":%s", config.GlobalConfig.HttpServerPort)
+	conf := config.Current()
+	addr := fmt.Sprintf(":%s", conf.HttpServerPort)
+	s.limits.set(conf.MaxRequestBytes, conf.MaxBatchSize, conf.RateLimits)
+	s.certs = nil
+	if conf.TLSCertFile != "" {
+		certs, err := newCertReloader(conf.TLSCertFile, conf.TLSKeyFile, conf.TLSClientCAFile)
+		if err != nil {
+			return err
+		}
+		s.certs = certs
+	}
 	s.client = &http.Client{
 		Timeout: 30 * time.Second,
 	}
-	s.server = createServer(s.bus, addr)
-	go startServer(s.server)
+	if s.certs != nil {
+		s.client.Transport = &http.Transport{TLSClientConfig: s.certs.clientConfig()}
+	}
+	subscriptions, err := rpc.NewSubscriptionHandler(s.bus)
+	if err != nil {
+		return err
+	}
+	s.subscriptions = subscriptions
+	s.gate = &drainGate{}
+	s.server = createServer(s.bus, addr, s.limits, s.gate, s.certs, s.subscriptions)
+	s.running.Store(true)
+	go s.startServer(s.server)
 
 	return nil
 }
 
-func startServer(server *http.Server) {
-	err := server.ListenAndServe()
-	if err != nil {
-		log.WithError(err).Fatal()
+// startServer serves until the server is shut down. Any other failure is
+// reported through IsRunning so the registry can restart the service.
+func (s *ServerService) startServer(server *http.Server) {
+	var err error
+	if server.TLSConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.WithError(err).Error("ServerService.ListenAndServe()")
 	}
+	s.running.Store(false)
 }
 
-func createServer(bus eventbus.Bus, addr string) *http.Server {
-	router := setUpRouter(bus)
+func createServer(bus eventbus.Bus, addr string, limits *requestLimits, gate *drainGate, certs *certReloader, subscriptions http.Handler) *http.Server {
+	router := setUpRouter(bus, limits, gate, subscriptions, certs != nil && certs.caFile != "")
 	server := &http.Server{
 		Addr:    addr,
 		Handler: router,
 	}
+	if certs != nil {
+		server.TLSConfig = certs.serverConfig()
+	}
 	return server
 }
 
+// Drain turns away new client requests and waits for the ones being served
+// to complete. The server keeps serving peer methods, which the keygens this
+// node takes part in depend on, until it is stopped.
+func (s *ServerService) Drain(ctx context.Context) error {
+	if s.gate == nil {
+		return nil
+	}
+	return s.gate.drain(ctx)
+}
+
 func (s *ServerService) Stop() error {
 	s.client.CloseIdleConnections()
+	// Shutdown does not wait for hijacked WebSocket connections
+	s.subscriptions.Close()
 	err := s.server.Shutdown(context.Background())
 	return err
 }
 func (service *ServerService) IsRunning() bool {
-	return true
+	return service.running.Load()
 }
 func (service *ServerService) Call(method string, args ...interface{}) (interface{}, error) {
 	switch method {
@@ -141,15 +196,20 @@ func (service *ServerService) Call(method string, args ...interface{}) (interfac
 }
 
 func (s *ServerService) RequestConnectionDetails(endpoint string) (connectionDetails common.ConnectionDetails, err error) {
-	pubKey := s.broker.ChainMethods().GetSelfPublicKey()
-	privKey := s.broker.ChainMethods().GetSelfPrivateKey()
+	pubKey, err := s.broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return connectionDetails, err
+	}
 	addr := s.broker.ChainMethods().GetSelfAddress()
 	connectionDetailsMessage := ConnectionDetailsMessage{
 		Message:     "ConnectionDetails",
 		Timestamp:   strconv.FormatInt(time.Now().Unix(), 10),
 		NodeAddress: addr,
 	}
-	sig := keygen.ECDSASign(connectionDetailsMessage.String(), &privKey)
+	sig, err := s.broker.ChainMethods().SelfSignData([]byte(connectionDetailsMessage.String()))
+	if err != nil {
+		return connectionDetails, err
+	}
 	connectionDetailsParams := ConnectionDetailsParams{
 		PubKeyX:                  pubKey.X.Text(16),
 		PubKeyY:                  pubKey.Y.Text(16),
@@ -182,8 +242,14 @@ func (s *ServerService) RequestConnectionDetails(endpoint string) (connectionDet
 	var respErr error
 	var resp *http.Response
 
+	// A node serving TLS expects its peers to do the same, and presents its
+	// certificate to them for mTLS.
+	scheme := "http"
+	if s.certs != nil {
+		scheme = "https"
+	}
 	if port != "" {
-		resp, respErr = s.client.Post(fmt.Sprintf("http://%s:%s/rpc", uri, port), contentType, bytes.NewBuffer(body))
+		resp, respErr = s.client.Post(fmt.Sprintf("%s://%s:%s/rpc", scheme, uri, port), contentType, bytes.NewBuffer(body))
 		log.WithFields(log.Fields{
 			"status":  resp.StatusCode,
 			"respErr": respErr,
@@ -224,7 +290,7 @@ func (s *ServerService) RequestConnectionDetails(endpoint string) (connectionDet
 	return res, nil
 }
 
-func setUpRouter(eventBus eventbus.Bus) http.Handler {
+func setUpRouter(eventBus eventbus.Bus, limits *requestLimits, gate *drainGate, subscriptions http.Handler, requireClientCert bool) http.Handler {
 	mr, err := rpc.SetUpJRPCHandler(eventBus)
 	if err != nil {
 		log.WithError(err).Fatal()
@@ -232,12 +298,18 @@ func setUpRouter(eventBus eventbus.Bus) http.Handler {
 
 	router := mux.NewRouter().StrictSlash(true)
 
-	router.Handle("/rpc", mr)
+	router.Handle("/rpc", batchHandler{mr: mr, limits: limits})
+	router.Handle("/ws", subscriptions)
 	// AttachProfiler(router)
 
-	router.Use(parseBodyMiddleware)
+	router.Use(parseBodyMiddleware(limits))
 	router.Use(augmentRequestMiddleware)
 	router.Use(loggingMiddleware)
+	if requireClientCert {
+		router.Use(requireClientCertMiddleware)
+	}
+	router.Use(rateLimitMiddleware(limits))
+	router.Use(gate.middleware)
 
 	handler := cors.Default().Handler(router)
 	return handler
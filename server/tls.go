@@ -0,0 +1,194 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/server/rpc"
+)
+
+// certCheckInterval is how often the certificate files are checked for
+// changes during handshakes.
+const certCheckInterval = 30 * time.Second
+
+// certReloader serves the certificate and client CA from disk and rereads
+// them once the files change, so renewed certificates are used without a
+// restart.
+type certReloader struct {
+	certFile, keyFile, caFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+	checked   time.Time
+}
+
+func newCertReloader(certFile, keyFile, caFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// lastModified is the latest modification time of the watched files.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("could not read tls client ca: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("tls client ca has no certificates")
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCAs, r.modTime = &cert, clientCAs, modTime
+	return nil
+}
+
+// maybeReload rereads the files when they changed since the last load. A
+// broken renewal keeps the previous certificate in use.
+func (r *certReloader) maybeReload() {
+	r.mu.Lock()
+	if time.Since(r.checked) < certCheckInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.checked = time.Now()
+	loaded := r.modTime
+	r.mu.Unlock()
+
+	modTime, err := r.lastModified()
+	if err != nil || !modTime.After(loaded) {
+		return
+	}
+	if err := r.load(); err != nil {
+		log.WithError(err).Error("could not reload tls certificate, keeping the previous one")
+		return
+	}
+	log.Info("reloaded tls certificate")
+}
+
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.clientCAs
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// serverConfig asks for client certificates when a client CA is set. They
+// are optional for the handshake and only required for ConnectionDetails.
+func (r *certReloader) serverConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs := r.current()
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.Certificates = []tls.Certificate{*cert}
+		if clientCAs != nil {
+			c.ClientCAs = clientCAs
+			c.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		return c, nil
+	}
+	return base
+}
+
+// TLSConfig serves the node certificate like the RPC server does, rereading
+// renewed certificate files. Other listeners of the node use it to be served
+// over TLS as well.
+func TLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	certs, err := newCertReloader(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return certs.serverConfig(), nil
+}
+
+// clientConfig presents the node certificate to other nodes. Their server
+// certificates are checked against the system roots and the client CA.
+func (r *certReloader) clientConfig() *tls.Config {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if r.caFile != "" {
+		if pem, err := os.ReadFile(r.caFile); err == nil {
+			roots.AppendCertsFromPEM(pem)
+		}
+	}
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		RootCAs:              roots,
+		GetClientCertificate: r.getClientCertificate,
+	}
+}
+
+// requireClientCertMiddleware rejects ConnectionDetails requests that do not
+// come with a verified client certificate, also when sent in a batch.
+func requireClientCertMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests, _ := r.Context().Value(jrpcRequests).([]jRPCRequest)
+		connectionDetails := false
+		for _, req := range requests {
+			connectionDetails = connectionDetails || req.Method == rpc.ConnectionDetailsMethod
+		}
+		if connectionDetails && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			log.WithField("RemoteAddr", r.RemoteAddr).Warn("ConnectionDetails requested without a client certificate")
+			http.Error(w, "client certificate required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,187 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+const remoteSignerTimeout = 10 * time.Second
+
+type publicKeyResponse struct {
+	PublicKey hexutil.Bytes `json:"publicKey"`
+}
+
+type signRequest struct {
+	Hash hexutil.Bytes `json:"hash"`
+}
+
+type signResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// RemoteSigner signs through an HTTP signer process. The process serves
+// GET /public_key and POST /sign, see NewHandler. Sign requests carry a
+// shared secret as a bearer token, which can be swapped when it is rotated.
+type RemoteSigner struct {
+	url       string
+	client    *http.Client
+	publicKey *ecdsa.PublicKey
+
+	mu    sync.RWMutex
+	token string
+}
+
+// ReadToken reads the shared secret of a remote signer from a file.
+func ReadToken(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read signer token: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("signer token is empty")
+	}
+	return token, nil
+}
+
+func NewRemoteSigner(url, token string) (*RemoteSigner, error) {
+	if url == "" {
+		return nil, errors.New("remote signer url not specified")
+	}
+	if token == "" {
+		return nil, errors.New("remote signer token not specified")
+	}
+	s := &RemoteSigner{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: remoteSignerTimeout},
+	}
+	resp, err := s.client.Get(s.url + "/public_key")
+	if err != nil {
+		return nil, fmt.Errorf("could not reach remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned %s", resp.Status)
+	}
+	var body publicKeyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	s.publicKey, err = ethCrypto.UnmarshalPubkey(body.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer public key: %w", err)
+	}
+	return s, nil
+}
+
+func (s *RemoteSigner) PublicKey() *ecdsa.PublicKey {
+	return s.publicKey
+}
+
+// SetToken replaces the shared secret sign requests carry.
+func (s *RemoteSigner) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+func (s *RemoteSigner) SignHash(hash []byte) ([]byte, error) {
+	body, _ := json.Marshal(signRequest{Hash: hash})
+	req, err := http.NewRequest(http.MethodPost, s.url+"/sign", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.mu.RUnlock()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned %s", resp.Status)
+	}
+	var signed signResponse
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+		return nil, err
+	}
+	if len(signed.Signature) != 65 {
+		return nil, fmt.Errorf("remote signer returned a signature of %d bytes", len(signed.Signature))
+	}
+	// Do not trust the signer process to sign with the key it announced.
+	r := new(big.Int).SetBytes(signed.Signature[:32])
+	sv := new(big.Int).SetBytes(signed.Signature[32:64])
+	return recoverable(hash, r, sv, s.publicKey)
+}
+
+// NewHandler serves s with the protocol RemoteSigner speaks. It is used to
+// run the key in a separate process, and as a stand-in signer in tests. Only
+// sign requests carrying token are served.
+func NewHandler(s Signer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/public_key", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, publicKeyResponse{PublicKey: ethCrypto.FromECDSAPub(s.PublicKey())})
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorized(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req signRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if len(req.Hash) != 32 {
+			http.Error(w, "hash must be 32 bytes", http.StatusBadRequest)
+			return
+		}
+		sig, err := s.SignHash(req.Hash)
+		if err != nil {
+			log.WithError(err).Error("signer: could not sign")
+			http.Error(w, "could not sign", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, signResponse{Signature: sig})
+	})
+	return mux
+}
+
+// authorized reports whether a request carries token. No request is
+// authorized by an empty token.
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Error("signer: could not write response")
+	}
+}
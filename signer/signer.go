@@ -0,0 +1,145 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// The vault and remote signers sign chain txs, p2p handshakes and node
+// signatures outside the node process. They do not isolate the node key: the
+// raw key still has to be configured, and stays in the node process for the
+// DKG key agreement and the Tendermint validator key, which is written to the
+// Tendermint config directory.
+const (
+	Local        = "local"
+	VaultTransit = "vault"
+	Remote       = "remote"
+)
+
+// Signer signs with the node identity key. Implementations may hold the key
+// in memory or delegate to another process.
+type Signer interface {
+	PublicKey() *ecdsa.PublicKey
+	// SignHash signs a 32 byte digest. The signature has the [R || S || V]
+	// format of go-ethereum's crypto.Sign, with V being 0 or 1.
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// Options selects and configures a signer.
+type Options struct {
+	Kind string
+	// PrivateKey is the key of the local signer.
+	PrivateKey []byte
+	// URL is the address of the remote signer, and Token the shared secret
+	// it authenticates the node by.
+	URL   string
+	Token string
+	// SecretConfigPath is the vault configuration used by the transit signer.
+	SecretConfigPath string
+	// TransitMount and TransitKey name the transit key that signs.
+	TransitMount string
+	TransitKey   string
+}
+
+func New(opts Options) (Signer, error) {
+	switch opts.Kind {
+	case Local, "":
+		return NewLocalSigner(opts.PrivateKey)
+	case VaultTransit:
+		return NewTransitSigner(opts.SecretConfigPath, opts.TransitMount, opts.TransitKey)
+	case Remote:
+		return NewRemoteSigner(opts.URL, opts.Token)
+	}
+	return nil, fmt.Errorf("unknown signer %q", opts.Kind)
+}
+
+func Address(s Signer) ethCommon.Address {
+	return ethCrypto.PubkeyToAddress(*s.PublicKey())
+}
+
+// SignData signs the keccak256 hash of data, like crypto.SignData.
+func SignData(s Signer, data []byte) ([]byte, error) {
+	return s.SignHash(secp256k1.Keccak256(data))
+}
+
+// TransactOpts returns transaction options signing with s.
+func TransactOpts(s Signer, chainID *big.Int) *bind.TransactOpts {
+	txSigner := types.LatestSignerForChainID(chainID)
+	from := Address(s)
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address ethCommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			sig, err := s.SignHash(txSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, sig)
+		},
+	}
+}
+
+// LocalSigner holds the key in memory.
+type LocalSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func NewLocalSigner(privateKey []byte) (*LocalSigner, error) {
+	key, err := ethCrypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{key: key}, nil
+}
+
+func (s *LocalSigner) PublicKey() *ecdsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+func (s *LocalSigner) SignHash(hash []byte) ([]byte, error) {
+	return ethCrypto.Sign(hash, s.key)
+}
+
+// PrivateKey returns the key for the uses that cannot be delegated to a
+// signer, see Exportable.
+func (s *LocalSigner) PrivateKey() *ecdsa.PrivateKey {
+	return s.key
+}
+
+// Exportable is implemented by signers that can hand out the raw key. The
+// DKG key agreement and the Tendermint validator key still need it.
+type Exportable interface {
+	PrivateKey() *ecdsa.PrivateKey
+}
+
+var secp256k1HalfN = new(big.Int).Rsh(ethCrypto.S256().Params().N, 1)
+
+// recoverable turns an (r, s) signature made by pub into the [R || S || V]
+// format, normalising s to the lower half of the curve order.
+func recoverable(hash []byte, r, s *big.Int, pub *ecdsa.PublicKey) ([]byte, error) {
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(ethCrypto.S256().Params().N, s)
+	}
+	sig := make([]byte, 65)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	want := ethCrypto.FromECDSAPub(pub)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := ethCrypto.Ecrecover(hash, sig)
+		if err == nil && string(recovered) == string(want) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("signature does not match the signer public key")
+}
@@ -0,0 +1,140 @@
+package signer
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/secret"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestLocalSigner(t *testing.T) *LocalSigner {
+	key, err := ethCrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewLocalSigner(ethCrypto.FromECDSA(key))
+	if err != nil {
+		t.Fatalf("NewLocalSigner() error = %v", err)
+	}
+	return s
+}
+
+func recoveredAddress(t *testing.T, data, sig []byte) string {
+	pub, err := ethCrypto.SigToPub(secp256k1.Keccak256(data), sig)
+	if err != nil {
+		t.Fatalf("SigToPub() error = %v", err)
+	}
+	return ethCrypto.PubkeyToAddress(*pub).Hex()
+}
+
+func TestRemoteSignerSignsWithServedKey(t *testing.T) {
+	local := newTestLocalSigner(t)
+	server := httptest.NewServer(NewHandler(local, "secret"))
+	defer server.Close()
+
+	remote, err := NewRemoteSigner(server.URL, "secret")
+	if err != nil {
+		t.Fatalf("NewRemoteSigner() error = %v", err)
+	}
+	if Address(remote) != Address(local) {
+		t.Fatalf("Address() = %v, want %v", Address(remote), Address(local))
+	}
+	data := []byte("connection details")
+	sig, err := SignData(remote, data)
+	if err != nil {
+		t.Fatalf("SignData() error = %v", err)
+	}
+	if got := recoveredAddress(t, data, sig); got != Address(local).Hex() {
+		t.Errorf("signature recovers to %s, want %s", got, Address(local).Hex())
+	}
+
+	unauthorized, err := NewRemoteSigner(server.URL, "guess")
+	if err != nil {
+		t.Fatalf("NewRemoteSigner() error = %v", err)
+	}
+	if _, err := SignData(unauthorized, data); err == nil {
+		t.Error("remote signer signed for a client with the wrong token")
+	}
+}
+
+// fakeTransit serves the transit key and sign endpoints with a local key.
+// Signatures are returned with a high s to check they are normalised.
+func fakeTransit(t *testing.T, local *LocalSigner) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]interface{}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/auth/token/lookup-self":
+			data = map[string]interface{}{"renewable": false, "ttl": 0}
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/transit/keys/node":
+			data = map[string]interface{}{
+				"latest_version": 1,
+				"keys": map[string]interface{}{
+					"1": map[string]interface{}{
+						"public_key": hex.EncodeToString(ethCrypto.FromECDSAPub(local.PublicKey())),
+					},
+				},
+			}
+		case r.Method == http.MethodPut || r.Method == http.MethodPost:
+			if r.URL.Path != "/v1/transit/sign/node" {
+				http.NotFound(w, r)
+				return
+			}
+			var req struct {
+				Input string `json:"input"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("could not decode sign request: %v", err)
+				return
+			}
+			hash, _ := base64.StdEncoding.DecodeString(req.Input)
+			sig, err := local.SignHash(hash)
+			if err != nil {
+				t.Errorf("SignHash() error = %v", err)
+				return
+			}
+			highS := new(big.Int).Sub(ethCrypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+			der, _ := asn1.Marshal(ecdsaSignature{R: new(big.Int).SetBytes(sig[:32]), S: highS})
+			data = map[string]interface{}{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(der)}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestTransitSignerNormalisesSignatures(t *testing.T) {
+	local := newTestLocalSigner(t)
+	server := fakeTransit(t, local)
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "secret.json")
+	if err := (&secret.SecretConfig{Kind: "vault", Token: "token", ServerURL: server.URL}).WriteConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+	transit, err := NewTransitSigner(configPath, "", "node")
+	if err != nil {
+		t.Fatalf("NewTransitSigner() error = %v", err)
+	}
+	data := []byte("commitment")
+	sig, err := SignData(transit, data)
+	if err != nil {
+		t.Fatalf("SignData() error = %v", err)
+	}
+	if new(big.Int).SetBytes(sig[32:64]).Cmp(secp256k1HalfN) > 0 {
+		t.Error("SignData() returned a high s signature")
+	}
+	if got := recoveredAddress(t, data, sig); got != Address(local).Hex() {
+		t.Errorf("signature recovers to %s, want %s", got, Address(local).Hex())
+	}
+}
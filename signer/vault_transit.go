@@ -0,0 +1,132 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/arcana-network/dkgnode/secret"
+	secretVault "github.com/arcana-network/dkgnode/secret/vault"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const defaultTransitMount = "transit"
+
+// TransitSigner signs with a key held by a Vault transit engine. Stock Vault
+// transit has no secp256k1 key type, so the mount has to be served by a
+// transit-compatible plugin that supports it.
+type TransitSigner struct {
+	client    *vault.Client
+	mount     string
+	key       string
+	publicKey *ecdsa.PublicKey
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func NewTransitSigner(secretConfigPath, mount, key string) (*TransitSigner, error) {
+	if key == "" {
+		return nil, errors.New("transit key not specified")
+	}
+	if mount == "" {
+		mount = defaultTransitMount
+	}
+	c, err := secret.ReadConfig(secretConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	// The manager keeps the client logged in and its token renewed.
+	manager, err := secretVault.NewVaultManager(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.Setup(); err != nil {
+		return nil, err
+	}
+
+	s := &TransitSigner{client: manager.Client(), mount: strings.Trim(mount, "/"), key: key}
+	if err := s.loadPublicKey(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *TransitSigner) loadPublicKey() error {
+	resp, err := s.client.Logical().Read(fmt.Sprintf("%s/keys/%s", s.mount, s.key))
+	if err != nil {
+		return fmt.Errorf("could not read transit key: %w", err)
+	}
+	if resp == nil {
+		return errors.New("transit key not found")
+	}
+	keys, _ := resp.Data["keys"].(map[string]interface{})
+	latest := fmt.Sprint(resp.Data["latest_version"])
+	version, _ := keys[latest].(map[string]interface{})
+	encoded, _ := version["public_key"].(string)
+	if encoded == "" {
+		return errors.New("transit key has no public key")
+	}
+	s.publicKey, err = parsePublicKey(encoded)
+	return err
+}
+
+// parsePublicKey accepts an uncompressed hex key or a PEM encoded
+// SubjectPublicKeyInfo. The standard library does not know secp256k1, so
+// the PEM is unpacked by hand.
+func parsePublicKey(encoded string) (*ecdsa.PublicKey, error) {
+	if block, _ := pem.Decode([]byte(encoded)); block != nil {
+		var info struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}
+		if _, err := asn1.Unmarshal(block.Bytes, &info); err != nil {
+			return nil, fmt.Errorf("invalid transit public key: %w", err)
+		}
+		return ethCrypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid transit public key: %w", err)
+	}
+	return ethCrypto.UnmarshalPubkey(raw)
+}
+
+func (s *TransitSigner) PublicKey() *ecdsa.PublicKey {
+	return s.publicKey
+}
+
+func (s *TransitSigner) SignHash(hash []byte) ([]byte, error) {
+	resp, err := s.client.Logical().Write(fmt.Sprintf("%s/sign/%s", s.mount, s.key), map[string]interface{}{
+		"input":                base64.StdEncoding.EncodeToString(hash),
+		"prehashed":            true,
+		"marshaling_algorithm": "asn1",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("transit sign failed: %w", err)
+	}
+	if resp == nil {
+		return nil, errors.New("transit sign returned no data")
+	}
+	encoded, _ := resp.Data["signature"].(string)
+	// Signatures are prefixed with the vault and key version, vault:v1:...
+	parts := strings.Split(encoded, ":")
+	der, err := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid transit signature: %w", err)
+	}
+	var sig ecdsaSignature
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("invalid transit signature: %w", err)
+	}
+	return recoverable(hash, sig.R, sig.S, s.publicKey)
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cacheMetrics struct {
+	lookups   *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	refusals  *prometheus.CounterVec
+	entries   *prometheus.GaugeVec
+}
+
+// cache is created eagerly, like serviceMethods, because the cache service
+// starts before the client.
+var cache = NewCacheMetrics()
+
+func NewCacheMetrics() *cacheMetrics {
+	m := &cacheMetrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_lookups",
+			Help: "Cache lookups by namespace and result, hit or miss",
+		}, []string{"namespace", "result"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_evictions",
+			Help: "Cache entries removed by namespace and reason, expired or full",
+		}, []string{"namespace", "reason"}),
+		refusals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_refusals",
+			Help: "New entries refused by a full cache namespace that does not evict",
+		}, []string{"namespace"}),
+		entries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cache_entries",
+			Help: "Entries held in a cache namespace",
+		}, []string{"namespace"}),
+	}
+	_ = prometheus.Register(m.lookups)
+	_ = prometheus.Register(m.evictions)
+	_ = prometheus.Register(m.refusals)
+	_ = prometheus.Register(m.entries)
+	return m
+}
+
+func CountCacheLookup(namespace string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cache.lookups.WithLabelValues(namespace, result).Inc()
+}
+
+func CountCacheEviction(namespace, reason string) {
+	cache.evictions.WithLabelValues(namespace, reason).Inc()
+}
+
+func CountCacheRefusal(namespace string) {
+	cache.refusals.WithLabelValues(namespace).Inc()
+}
+
+func SetCacheEntries(namespace string, entries int) {
+	cache.entries.WithLabelValues(namespace).Set(float64(entries))
+}
@@ -0,0 +1,237 @@
+package telemetry
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Phases of an ADKG session, as timed by StartPhase and EndPhase.
+const (
+	// PhaseACSS runs from the first ACSS message of a dealer to its output.
+	PhaseACSS = "acss"
+	// PhaseKeyset runs from the first keyset message of a dealer to the
+	// verification of its keyset.
+	PhaseKeyset = "keyset"
+	// PhaseABARound is one round of the ABA on the keyset of a dealer.
+	PhaseABARound = "aba_round"
+	// PhaseKeyDerivation runs from the end of all ABAs to the derived share.
+	PhaseKeyDerivation = "keyderivation"
+	// PhaseBFTDecision runs from the derived share to the BFT decision on
+	// the public key.
+	PhaseBFTDecision = "bft_decision"
+)
+
+const (
+	// staleSessionAge is how long a session may stay open without ending
+	// before its metrics state is dropped.
+	staleSessionAge = time.Hour
+	staleCheckEvery = time.Minute
+)
+
+type keygenMetrics struct {
+	phaseDuration *prometheus.HistogramVec
+	messages      *prometheus.CounterVec
+	abaRounds     prometheus.Histogram
+	keyBuffer     *prometheus.GaugeVec
+}
+
+// keygen is created eagerly, like serviceMethods, because the keygen
+// handlers run before the client starts in tests.
+var keygen = NewKeygenMetrics()
+
+func NewKeygenMetrics() *keygenMetrics {
+	m := &keygenMetrics{
+		phaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adkg_phase_duration_seconds",
+			Help:    "Duration of the phases of ADKG sessions",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+		}, []string{"phase"}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adkg_messages_received",
+			Help: "Keygen messages received by kind, method and sending peer",
+		}, []string{"kind", "method", "peer"}),
+		abaRounds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "adkg_aba_rounds",
+			Help:    "Number of ABA rounds before a decision",
+			Buckets: []float64{1, 2, 3, 4, 5, 6, 8, 10, 15, 20},
+		}),
+		keyBuffer: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "key_buffer_available",
+			Help: "Generated keys not yet assigned, by curve",
+		}, []string{"curve"}),
+	}
+	_ = prometheus.Register(m.phaseDuration)
+	_ = prometheus.Register(m.messages)
+	_ = prometheus.Register(m.abaRounds)
+	_ = prometheus.Register(m.keyBuffer)
+	return m
+}
+
+// CountKeygenMessage counts a keygen message received from a peer.
+func CountKeygenMessage(kind, method string, peer int) {
+	keygen.messages.WithLabelValues(kind, method, strconv.Itoa(peer)).Inc()
+}
+
+// ObserveABARounds records the number of rounds an ABA took to decide.
+func ObserveABARounds(rounds int) {
+	keygen.abaRounds.Observe(float64(rounds))
+}
+
+// SetKeyBufferDepth records the number of unassigned keys of a curve.
+func SetKeyBufferDepth(curve string, available int) {
+	keygen.keyBuffer.WithLabelValues(curve).Set(float64(available))
+}
+
+type phaseKey struct {
+	phase    string
+	instance string
+}
+
+type openPhase struct {
+	start time.Time
+	span  trace.Span
+}
+
+type sessionTimings struct {
+	start  time.Time
+	ctx    context.Context
+	span   trace.Span
+	phases map[phaseKey]openPhase
+	ended  map[phaseKey]bool
+}
+
+// phaseTracker keeps the phases in progress of each session, so that
+// phases are timed from the handlers that start and end them.
+type phaseTracker struct {
+	mu        sync.Mutex
+	sessions  map[string]*sessionTimings
+	lastCheck time.Time
+	observe   func(phase string, d time.Duration)
+}
+
+var phases = newPhaseTracker(func(phase string, d time.Duration) {
+	keygen.phaseDuration.WithLabelValues(phase).Observe(d.Seconds())
+})
+
+func newPhaseTracker(observe func(phase string, d time.Duration)) *phaseTracker {
+	return &phaseTracker{sessions: make(map[string]*sessionTimings), observe: observe}
+}
+
+// session returns the timings of a session, starting it if needed.
+// The caller holds the lock.
+func (p *phaseTracker) session(id string, now time.Time) *sessionTimings {
+	if s, ok := p.sessions[id]; ok {
+		return s
+	}
+	if now.Sub(p.lastCheck) > staleCheckEvery {
+		p.lastCheck = now
+		for staleID, s := range p.sessions {
+			if now.Sub(s.start) > staleSessionAge {
+				s.end(now, "stale")
+				delete(p.sessions, staleID)
+			}
+		}
+	}
+	ctx, span := startSessionSpan(id, now)
+	s := &sessionTimings{
+		start:  now,
+		ctx:    ctx,
+		span:   span,
+		phases: make(map[phaseKey]openPhase),
+		ended:  make(map[phaseKey]bool),
+	}
+	p.sessions[id] = s
+	return s
+}
+
+func (p *phaseTracker) start(session, phase, instance string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.session(session, now)
+	key := phaseKey{phase, instance}
+	if _, ok := s.phases[key]; ok || s.ended[key] {
+		return
+	}
+	_, span := tracer().Start(s.ctx, "adkg."+phase,
+		trace.WithTimestamp(now),
+		trace.WithAttributes(attribute.String("adkg.instance", instance)))
+	s.phases[key] = openPhase{start: now, span: span}
+}
+
+func (p *phaseTracker) end(session, phase, instance string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[session]
+	if !ok {
+		return
+	}
+	key := phaseKey{phase, instance}
+	open, ok := s.phases[key]
+	if !ok {
+		return
+	}
+	delete(s.phases, key)
+	s.ended[key] = true
+	open.span.End(trace.WithTimestamp(now))
+	p.observe(phase, now.Sub(open.start))
+}
+
+func (p *phaseTracker) endSession(session string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[session]
+	if !ok {
+		return
+	}
+	delete(p.sessions, session)
+	s.end(now, "")
+}
+
+// end closes the spans of a session. Phases still open are not observed,
+// as the session ended without them.
+func (s *sessionTimings) end(now time.Time, reason string) {
+	for _, open := range s.phases {
+		open.span.SetStatus(codes.Error, "session ended before the phase")
+		open.span.End(trace.WithTimestamp(now))
+	}
+	if reason != "" {
+		s.span.SetStatus(codes.Error, reason)
+	}
+	s.span.End(trace.WithTimestamp(now))
+}
+
+// sessionContext returns the span context of a session in progress.
+func (p *phaseTracker) sessionContext(session string) (context.Context, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[session]
+	if !ok {
+		return nil, false
+	}
+	return s.ctx, true
+}
+
+// StartPhase starts timing a phase of a session. The instance tells apart
+// phases that run once per dealer or per round, and may be empty. Starting
+// a phase that is in progress or already ended does nothing.
+func StartPhase(session, phase, instance string) {
+	phases.start(session, phase, instance, time.Now())
+}
+
+// EndPhase ends a phase started by StartPhase and records its duration.
+func EndPhase(session, phase, instance string) {
+	phases.end(session, phase, instance, time.Now())
+}
+
+// EndSession drops the state of a session once it completed or was
+// cancelled. Phases still in progress are not recorded.
+func EndSession(session string) {
+	phases.endSession(session, time.Now())
+}
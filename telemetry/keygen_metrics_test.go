@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPhaseTracker(t *testing.T) {
+	observed := map[string][]time.Duration{}
+	p := newPhaseTracker(func(phase string, d time.Duration) {
+		observed[phase] = append(observed[phase], d)
+	})
+	start := time.Now()
+
+	p.start("ADKG_1", PhaseACSS, "1", start)
+	p.start("ADKG_1", PhaseACSS, "1", start.Add(time.Second))
+	p.start("ADKG_1", PhaseACSS, "2", start.Add(time.Second))
+	p.end("ADKG_1", PhaseACSS, "1", start.Add(3*time.Second))
+	p.end("ADKG_1", PhaseACSS, "1", start.Add(4*time.Second))
+	// A phase that ended is not timed again.
+	p.start("ADKG_1", PhaseACSS, "1", start.Add(5*time.Second))
+	p.end("ADKG_1", PhaseACSS, "1", start.Add(6*time.Second))
+	// Phases of other sessions or never started are ignored.
+	p.end("ADKG_2", PhaseACSS, "1", start.Add(6*time.Second))
+	p.end("ADKG_1", PhaseKeyset, "1", start.Add(6*time.Second))
+
+	if got := observed[PhaseACSS]; len(got) != 1 || got[0] != 3*time.Second {
+		t.Fatalf("acss durations = %v, want [3s]", got)
+	}
+
+	p.endSession("ADKG_1", start.Add(7*time.Second))
+	p.end("ADKG_1", PhaseACSS, "2", start.Add(8*time.Second))
+	if got := observed[PhaseACSS]; len(got) != 1 {
+		t.Errorf("phase of an ended session was observed: %v", got)
+	}
+	if len(p.sessions) != 0 {
+		t.Errorf("%d sessions left", len(p.sessions))
+	}
+}
+
+func TestTraceFollowsSession(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	const session = "ADKG_trace"
+	StartPhase(session, PhaseACSS, "1")
+	carrier := InjectTrace(session)
+	if carrier["traceparent"] == "" {
+		t.Fatal("no trace context injected")
+	}
+	// The receiving node sees the message under the same trace, as a child
+	// of the session span of the sender.
+	StartReceiveSpan(session, "acss_share", 1, carrier).End()
+	EndPhase(session, PhaseACSS, "1")
+	EndSession(session)
+
+	root := sessionRoot(session)
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if span.SpanContext().TraceID() != root.TraceID() {
+			t.Errorf("span %s is in trace %s, want %s", span.Name(), span.SpanContext().TraceID(), root.TraceID())
+		}
+	}
+	sessionSpan, receive := spans["adkg.session"], spans["adkg.receive acss_share"]
+	if sessionSpan == nil || receive == nil || spans["adkg.acss"] == nil {
+		t.Fatalf("missing spans: %v", spans)
+	}
+	if sessionSpan.Parent().SpanID() != root.SpanID() {
+		t.Error("session span is not a child of the session root")
+	}
+	if receive.Parent().SpanID() != sessionSpan.SpanContext().SpanID() {
+		t.Error("receive span is not a child of the sender session span")
+	}
+	if !sessionRoot(session).Equal(root) || sessionRoot("ADKG_other").TraceID() == root.TraceID() {
+		t.Error("session root is not derived from the session id")
+	}
+}
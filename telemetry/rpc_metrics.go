@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type rpcMetrics struct {
+	rateLimited *prometheus.CounterVec
+}
+
+// rpc is created eagerly, like cache, so requests are counted whether or
+// not the client has started.
+var rpc = NewRPCMetrics()
+
+func NewRPCMetrics() *rpcMetrics {
+	m := &rpcMetrics{
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jrpc_rate_limited",
+			Help: "JSON-RPC requests refused by the rate limits, by method",
+		}, []string{"method"}),
+	}
+	_ = prometheus.Register(m.rateLimited)
+	return m
+}
+
+func CountRateLimited(method string) {
+	rpc.rateLimited.WithLabelValues(method).Inc()
+}
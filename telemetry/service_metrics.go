@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type serviceMethodMetrics struct {
+	latency  *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+// serviceMethods is created eagerly, unlike the counters set up by
+// StartClient, because services call each other before the client starts.
+var serviceMethods = NewServiceMethodMetrics()
+
+func NewServiceMethodMetrics() *serviceMethodMetrics {
+	m := &serviceMethodMetrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "service_method_duration_seconds",
+			Help:    "Latency of method calls between services",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"service", "method"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "service_method_failures",
+			Help: "Method calls between services that returned an error",
+		}, []string{"service", "method"}),
+	}
+	_ = prometheus.Register(m.latency)
+	_ = prometheus.Register(m.failures)
+	return m
+}
+
+func ObserveServiceMethod(service, method string, duration time.Duration, err error) {
+	serviceMethods.latency.WithLabelValues(service, method).Observe(duration.Seconds())
+	if err != nil {
+		serviceMethods.failures.WithLabelValues(service, method).Inc()
+	}
+}
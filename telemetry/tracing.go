@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/arcana-network/dkgnode/keygen"
+
+var propagator = propagation.TraceContext{}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// InitTracing exports spans to an OTLP/HTTP collector at endpoint, given as
+// host:port. Without an endpoint spans are not recorded. The returned
+// function flushes and stops the exporter.
+func InitTracing(endpoint, nodeName string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName("dkgnode"),
+			semconv.ServiceInstanceID(nodeName))),
+	)
+	otel.SetTracerProvider(provider)
+	log.WithField("endpoint", endpoint).Info("exporting keygen traces")
+	return provider.Shutdown, nil
+}
+
+// sessionRoot is the span context every node derives from the ADKGID, so
+// that the spans of all nodes in a session belong to the same trace. No
+// node records this span; the session span of each node is its child.
+func sessionRoot(session string) trace.SpanContext {
+	h := sha256.Sum256([]byte("adkg-trace:" + session))
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	copy(traceID[:], h[:16])
+	copy(spanID[:], h[16:24])
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+}
+
+func startSessionSpan(session string, now time.Time) (context.Context, trace.Span) {
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sessionRoot(session))
+	return tracer().Start(ctx, "adkg.session",
+		trace.WithTimestamp(now),
+		trace.WithAttributes(attribute.String("adkg.id", session)))
+}
+
+// InjectTrace returns the trace context of a session in progress, to be
+// carried in the keygen messages this node sends for it.
+func InjectTrace(session string) map[string]string {
+	ctx, ok := phases.sessionContext(session)
+	if !ok {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier
+}
+
+// StartReceiveSpan starts the span of a keygen message received for a
+// session, as a child of the span of the sender found in carrier. The
+// caller ends the span once the message is processed.
+func StartReceiveSpan(session, method string, peer int, carrier map[string]string) trace.Span {
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sessionRoot(session))
+	if len(carrier) > 0 {
+		ctx = propagator.Extract(ctx, propagation.MapCarrier(carrier))
+	}
+	_, span := tracer().Start(ctx, "adkg.receive "+method,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("adkg.id", session),
+			attribute.Int("adkg.peer", peer)))
+	return span
+}
@@ -6,12 +6,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/arcana-network/dkgnode/common"
 	"github.com/arcana-network/dkgnode/config"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/acss"
 	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/telemetry"
 
 	log "github.com/sirupsen/logrus"
 	code "github.com/tendermint/tendermint/abci/example/code"
@@ -37,6 +39,13 @@ type ABCI struct {
 	state       *State
 	prevState   *State
 	info        *AppInfo
+	buffer      *BufferPolicy
+	// draining stops EndBlock from starting new keygens while the node
+	// shuts down.
+	draining atomic.Bool
+	// committedTime is the time of the last committed block, in Unix
+	// nanoseconds, for the services that compare against the chain clock.
+	committedTime atomic.Int64
 }
 
 type KeygenPubKey struct {
@@ -79,6 +88,9 @@ func (t *TransferSummary) ID() TransferSummaryID {
 	return TransferSummaryID(strconv.Itoa(int(t.LastUnassignedIndex)))
 }
 
+// MappingCounter is the per-block observation the key buffer policy adapts to:
+// RequiredCount is the number of keys assigned during the block and KeyCount
+// the number of generated keys still unassigned.
 type MappingCounter struct {
 	RequiredCount int
 	KeyCount      int
@@ -111,8 +123,9 @@ func (state *State) KeyAvailable(curve common.CurveName) bool {
 }
 
 type AppInfo struct {
-	Height  int64  `json:"height"`
-	AppHash []byte `json:"app_hash"`
+	Height    int64     `json:"height"`
+	AppHash   []byte    `json:"app_hash"`
+	BlockTime time.Time `json:"block_time"`
 }
 
 type DBIteratorsSyncMap struct {
@@ -120,11 +133,25 @@ type DBIteratorsSyncMap struct {
 }
 
 func (a *ABCI) NewABCI(broker *common.MessageBroker) *ABCI {
-	db, err := tmdb.NewGoLevelDB("tmstate", config.GlobalConfig.BasePath+"/tmstate")
+	db, err := tmdb.NewGoLevelDB("tmstate", config.Current().BasePath+"/tmstate")
 	if err != nil {
 		log.WithError(err).Fatal("could not start GoLevelDB for tendermint state")
 	}
-	abci := ABCI{db: db, dbIterators: &DBIteratorsSyncMap{}, broker: broker}
+	abci := ABCI{
+		db:          db,
+		dbIterators: &DBIteratorsSyncMap{},
+		broker:      broker,
+		buffer:      NewBufferPolicy(config.Current().KeyBufferPolicy),
+	}
+	config.OnReload(func(_, new *config.Config) {
+		abci.buffer.SetOverrides(new.KeyBufferPolicy)
+	})
+	if err := abci.loadGovernedBufferPolicies(); err != nil {
+		log.WithError(err).Error("could not load the key buffer policies voted by the nodes")
+	}
+	if err := abci.loadBufferStates(); err != nil {
+		log.WithError(err).Error("could not load the key buffer state")
+	}
 	_, stateExists := abci.LoadState()
 
 	if !stateExists {
@@ -156,19 +183,35 @@ func (abci *ABCI) DeliverTx(req abcitypes.RequestDeliverTx) abcitypes.ResponseDe
 	correct, tags, err := abci.ValidateAndUpdateAndTagBFTTx(parsedTx.BFTTx, parsedTx.MsgType, senderDetails)
 	if err != nil {
 		log.WithError(err).Error("could not validate BFTTx")
-		return abcitypes.ResponseDeliverTx{Code: code.CodeTypeUnauthorized}
+		return abcitypes.ResponseDeliverTx{Code: code.CodeTypeUnauthorized, Events: failedAssignmentEvents(parsedTx)}
 	}
 
 	if !correct {
 		log.Error("tx not correct, could not be validated: err=%w", err)
-		return abcitypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError}
+		return abcitypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Events: failedAssignmentEvents(parsedTx)}
 	}
 
 	if tags == nil {
 		tags = new([]abcitypes.EventAttribute)
 	}
 
-	return abcitypes.ResponseDeliverTx{Code: code.CodeTypeOK, Events: []abcitypes.Event{{Type: "transfer", Attributes: *tags}}}
+	return abcitypes.ResponseDeliverTx{Code: code.CodeTypeOK, Events: []abcitypes.Event{{Type: common.AssignmentEventType, Attributes: *tags}}}
+}
+
+// failedAssignmentEvents tags a failed assignment tx, so that clients waiting
+// for the assignment learn that it failed.
+func failedAssignmentEvents(parsedTx DefaultBFTTxWrapper) []abcitypes.Event {
+	if parsedTx.MsgType != byte(1) {
+		return nil
+	}
+	var tx AssignmentTx
+	if err := bijson.Unmarshal(parsedTx.BFTTx, &tx); err != nil {
+		return nil
+	}
+	return []abcitypes.Event{{Type: common.AssignmentEventType, Attributes: []abcitypes.EventAttribute{
+		{Key: []byte(common.AssignmentTag), Value: []byte("0")},
+		{Key: []byte(common.AssignmentKeyTag), Value: []byte(common.AssignmentKey(tx.Provider, tx.UserID, tx.AppID, tx.Curve)), Index: true},
+	}}}
 }
 func (abci *ABCI) CheckTx(req abcitypes.RequestCheckTx) abcitypes.ResponseCheckTx {
 	tx := req.GetTx()
@@ -220,82 +263,85 @@ func (abci *ABCI) EndBlock(req abcitypes.RequestEndBlock) abcitypes.ResponseEndB
 		"LastUnassignedIndex": int(abci.state.LastUnassignedIndex),
 	}).Info("EndBlock")
 
-	buffer := abci.broker.ChainMethods().KeyBuffer()
-	var maxKeyInit int
-	if buffer > 500 {
-		maxKeyInit = buffer / 500
-	} else {
-		maxKeyInit = 100
+	if req.Height%seenTokenPruneInterval == 0 {
+		abci.pruneSeenTokens()
 	}
+	if abci.draining.Load() {
+		return abcitypes.ResponseEndBlock{}
+	}
+	buffer := abci.broker.ChainMethods().KeyBuffer()
+	abci.refillKeyBuffer(common.SECP256K1, buffer, req.Height, abci.state.LastCreatedIndex, abci.state.LastUnassignedIndex)
+	abci.refillKeyBuffer(common.ED25519, buffer, req.Height, abci.state.C25519State.LastCreatedIndex, abci.state.C25519State.LastUnassignedIndex)
+	return abcitypes.ResponseEndBlock{}
+}
 
-	if int(abci.state.LastCreatedIndex)-int(abci.state.LastUnassignedIndex) < buffer {
-		log.WithFields(log.Fields{
-			"EndBlockHeight":      req.Height,
-			"LastCreatedIndex":    int(abci.state.LastCreatedIndex),
-			"LastUnassignedIndex": int(abci.state.LastUnassignedIndex),
-		}).Info("EndBlock")
-		end := MinOf(int(abci.state.LastCreatedIndex)+maxKeyInit, int(abci.state.LastUnassignedIndex)+buffer)
-		log.WithFields(log.Fields{
-			"Start":  int(abci.state.LastCreatedIndex),
-			"End":    end,
-			"Buffer": buffer,
-		}).Info("EndBlock: Starting Keygens")
-		for i := int(abci.state.LastCreatedIndex); i < end; i++ {
-			id := common.GenerateADKGID(*big.NewInt(int64(i)))
-			round := common.RoundDetails{
-				ADKGID: id,
-				Dealer: abci.broker.ChainMethods().GetSelfIndex(),
-				Kind:   "acss",
-			}
-			msg, err := acss.NewShareMessage(
-				round.ID(),
-				common.SECP256K1,
-			)
-			if err != nil {
-				log.WithError(err).Error("EndBlock:Acss.NewShareMessage")
-				continue
-			}
-			err = abci.broker.KeygenMethods().ReceiveMessage(*msg)
-			if err != nil {
-				log.WithError(err).Error("Could not receive keygenmessage share")
-			}
+// refillKeyBuffer starts the keygens the buffer policy plans for a curve in
+// the block at height.
+func (abci *ABCI) refillKeyBuffer(curve common.CurveName, buffer int, height int64, created, unassigned uint) {
+	telemetry.SetKeyBufferDepth(string(curve), int(created)-int(unassigned))
+	counter := abci.buffer.Observe(curve, created, unassigned)
+	start, end := abci.buffer.Plan(curve, buffer, height, created, unassigned)
+	if err := abci.saveBufferState(curve); err != nil {
+		log.WithError(err).Error("could not save the key buffer state")
+	}
+	log.WithFields(log.Fields{
+		"Curve":          curve,
+		"Start":          start,
+		"End":            end,
+		"Buffer":         buffer,
+		"AvailableKeys":  counter.KeyCount,
+		"AssignedKeys":   counter.RequiredCount,
+		"AssignmentRate": abci.buffer.Rate(curve),
+	}).Info("EndBlock: Starting Keygens")
+
+	for i := start; i < end; i++ {
+		id := common.NewADKGID(*big.NewInt(int64(i)), curve)
+		round := common.RoundDetails{
+			ADKGID: id,
+			Dealer: abci.broker.ChainMethods().GetSelfIndex(),
+			Kind:   "acss",
+		}
+		msg, err := acss.NewShareMessage(
+			round.ID(),
+			curve,
+		)
+		if err != nil {
+			log.WithError(err).Error("EndBlock:Acss.NewShareMessage")
+			continue
+		}
+		err = abci.broker.KeygenMethods().ReceiveMessage(*msg)
+		if err != nil {
+			log.WithError(err).Error("Could not receive keygenmessage share")
 		}
 	}
+}
 
-	edBuffer := buffer / 10
-	if int(abci.state.C25519State.LastCreatedIndex)-int(abci.state.C25519State.LastUnassignedIndex) < edBuffer {
-		maxEDKeyInit := maxKeyInit / 10
-		endED := MinOf(int(abci.state.C25519State.LastCreatedIndex)+maxEDKeyInit, int(abci.state.C25519State.LastUnassignedIndex)+edBuffer)
-		log.WithFields(log.Fields{
-			"LastCreatedIndex":    int(abci.state.C25519State.LastCreatedIndex),
-			"Buffer":              buffer,
-			"EDBuffer":            edBuffer,
-			"EndED":               endED,
-			"LastUnassignedIndex": int(abci.state.C25519State.LastUnassignedIndex),
-		}).Info("EndBlock:ED25519")
-		for j := int(abci.state.C25519State.LastCreatedIndex); j < endED; j++ {
-			log.Debugf("Starting ED25519 with index: %d", j)
-			id := common.NewADKGID(*big.NewInt(int64(j)), common.ED25519)
-			round := common.RoundDetails{
-				ADKGID: id,
-				Dealer: abci.broker.ChainMethods().GetSelfIndex(),
-				Kind:   "acss",
-			}
-			msg, err := acss.NewShareMessage(
-				round.ID(),
-				common.ED25519,
-			)
-			if err != nil {
-				log.WithError(err).Error("EndBlock:Acss.NewShareMessage")
-				continue
-			}
-			err = abci.broker.KeygenMethods().ReceiveMessage(*msg)
-			if err != nil {
-				log.WithError(err).Error("Could not receive keygenmessage share")
-			}
+// keyBufferStatus reports the fill of the key buffer of each curve.
+func (abci *ABCI) keyBufferStatus() map[common.CurveName]common.KeyBufferStatus {
+	buffer := abci.broker.ChainMethods().KeyBuffer()
+	status := func(curve common.CurveName, created, unassigned uint) common.KeyBufferStatus {
+		return common.KeyBufferStatus{
+			LastCreatedIndex:    created,
+			LastUnassignedIndex: unassigned,
+			Available:           int(created) - int(unassigned),
+			Target:              abci.buffer.Policy(curve, buffer).Target,
+			AssignmentRate:      abci.buffer.Rate(curve),
 		}
 	}
-	return abcitypes.ResponseEndBlock{}
+	return map[common.CurveName]common.KeyBufferStatus{
+		common.SECP256K1: status(common.SECP256K1, abci.state.LastCreatedIndex, abci.state.LastUnassignedIndex),
+		common.ED25519:   status(common.ED25519, abci.state.C25519State.LastCreatedIndex, abci.state.C25519State.LastUnassignedIndex),
+	}
+}
+
+// lastBlockTime returns the time of the last committed block, the zero time
+// before the first one.
+func (app *ABCI) lastBlockTime() time.Time {
+	nanos := app.committedTime.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
 }
 
 func (app *ABCI) Info(req abcitypes.RequestInfo) (resInfo abcitypes.ResponseInfo) {
@@ -322,7 +368,11 @@ func (abci *ABCI) Commit() abcitypes.ResponseCommit {
 	// update prepare state for next block,
 	abci.info.AppHash = currAppHash
 	abci.info.Height += 1
+	abci.info.BlockTime = abci.state.BlockTime
 	abci.SaveState()
+	if !abci.state.BlockTime.IsZero() {
+		abci.committedTime.Store(abci.state.BlockTime.UnixNano())
+	}
 	abci.prevState = nil
 	err = bijson.Unmarshal(byt, &abci.prevState)
 	if err != nil {
@@ -426,6 +476,9 @@ func (app *ABCI) LoadState() (State, bool) {
 	app.state = &state
 	app.prevState = &prevState
 	app.info = &info
+	if !info.BlockTime.IsZero() {
+		app.committedTime.Store(info.BlockTime.UnixNano())
+	}
 	return state, stateExists
 }
 
@@ -454,7 +507,10 @@ func authenticateBftTx(tx []byte, broker *common.MessageBroker) (parsedTx Defaul
 		return parsedTx, senderDetails, err
 	}
 
-	curEpoch := broker.ChainMethods().GetCurrentEpoch()
+	curEpoch, err := broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return parsedTx, senderDetails, err
+	}
 	senderDetails, err = broker.ChainMethods().VerifyDataWithEpoch(parsedTx.PubKey, parsedTx.Signature, parsedTx.GetSerializedBody(), curEpoch)
 	if err != nil {
 		log.Errorf("bfttx not valid: error %v, tx %v", err, parsedTx)
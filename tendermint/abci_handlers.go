@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"time"
 
 	"github.com/arcana-network/dkgnode/common"
 	"github.com/arcana-network/dkgnode/keygen/message_handlers/keyderivation"
@@ -91,6 +92,78 @@ func (abci *ABCI) validateTx(tx []byte, msgType byte, senderDetails common.Keyge
 			return true, nil
 		}
 		return false, errors.New("tendermint received dkg message with unimplemented method:" + msg.Method)
+
+	case byte(3):
+		var parsedTx PasskeyRegistrationTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:PasskeyRegistration")
+			return false, err
+		}
+		if err := abci.checkPasskeyRegistration(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:PasskeyRegistration")
+			return false, err
+		}
+		return true, nil
+
+	case byte(4):
+		var parsedTx TokenSeenTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:TokenSeen")
+			return false, err
+		}
+		if err := abci.checkTokenSeen(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:TokenSeen")
+			return false, err
+		}
+		return true, nil
+
+	case byte(5):
+		var parsedTx AppPolicyTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:AppPolicy")
+			return false, err
+		}
+		if _, err := abci.checkAppPolicy(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:AppPolicy")
+			return false, err
+		}
+		return true, nil
+
+	case byte(6):
+		var parsedTx RecoveryTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:Recovery")
+			return false, err
+		}
+		if _, err := abci.checkRecovery(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:Recovery")
+			return false, err
+		}
+		return true, nil
+
+	case byte(7):
+		var parsedTx BufferPolicyVoteTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:BufferPolicyVote")
+			return false, err
+		}
+		if err := checkBufferPolicyVote(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:BufferPolicyVote")
+			return false, err
+		}
+		return true, nil
+
+	case byte(8):
+		var parsedTx PasskeySignCountTx
+		if err := bijson.Unmarshal(tx, &parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:PasskeySignCount")
+			return false, err
+		}
+		if err := abci.checkPasskeySignCount(parsedTx); err != nil {
+			log.WithError(err).Error("CheckTx:PasskeySignCount")
+			return false, err
+		}
+		return true, nil
 	}
 	return false, errors.New("tx type not recognized")
 }
@@ -104,13 +177,14 @@ func (app *ABCI) assignKey(pk common.KeyAssignmentPublic, curve common.CurveName
 	app.state.NewKeyAssignments = append(app.state.NewKeyAssignments, pk)
 }
 
-func createKeyAssignment(tx AssignmentTx, i big.Int, pk common.Point) common.KeyAssignmentPublic {
+func createKeyAssignment(tx AssignmentTx, i big.Int, pk common.Point, assignedAt time.Time) common.KeyAssignmentPublic {
 	verifierMap := make(map[string][]string)
 	verifierMap[tx.Provider] = []string{tx.UserID}
 	newKeyMapping := common.KeyAssignmentPublic{
-		Index:     i,
-		PublicKey: pk,
-		Verifiers: verifierMap,
+		Index:      i,
+		PublicKey:  pk,
+		Verifiers:  verifierMap,
+		AssignedAt: assignedAt,
 	}
 	return newKeyMapping
 }
@@ -125,7 +199,10 @@ func getVerifierKey(tx AssignmentTx, partitioned bool) []byte {
 func (abci *ABCI) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, senderDetails common.KeygenNodeDetails) (bool, *[]abcitypes.EventAttribute, error) {
 	var tags []abcitypes.EventAttribute
 
-	currEpoch := abci.broker.ChainMethods().GetCurrentEpoch()
+	currEpoch, err := abci.broker.ChainMethods().GetCurrentEpoch()
+	if err != nil {
+		return false, &tags, err
+	}
 	currEpochInfo, err := abci.broker.ChainMethods().GetEpochInfo(currEpoch, false)
 	if err != nil {
 		return false, &tags, fmt.Errorf("could not get current epoch with err: %v", err)
@@ -157,7 +234,7 @@ func (abci *ABCI) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sende
 		keyIndexes := abci.getKeyAssignment(*assignIndex, tx)
 		pk := abci.state.KeygenPubKeys[dkgID].Point
 
-		keyAssignment := createKeyAssignment(tx, *assignIndex, pk)
+		keyAssignment := createKeyAssignment(tx, *assignIndex, pk, abci.state.BlockTime)
 
 		err = abci.storeKeyMapping(*assignIndex, tx.Curve, keyAssignment)
 		if err != nil {
@@ -183,7 +260,10 @@ func (abci *ABCI) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sende
 		delete(abci.state.KeygenPubKeys, dkgID)
 		// add final tags
 		tags = []abcitypes.EventAttribute{
-			{Key: []byte("assignment"), Value: []byte("1")},
+			{Key: []byte(common.AssignmentTag), Value: []byte("1")},
+			{Key: []byte(common.AssignmentKeyTag), Value: []byte(common.AssignmentKey(tx.Provider, tx.UserID, tx.AppID, tx.Curve)), Index: true},
+			{Key: []byte(common.KeyIndexTag), Value: []byte(assignIndex.Text(16))},
+			{Key: []byte(common.CurveTag), Value: []byte(tx.Curve)},
 		}
 		return true, &tags, nil
 
@@ -303,6 +383,92 @@ func (abci *ABCI) ValidateAndUpdateAndTagBFTTx(bftTx []byte, msgType byte, sende
 			return true, &tags, nil
 		}
 		return false, &tags, errors.New("tendermint: unimplemented method:" + msg.Method)
+
+	case byte(3): // passkey registration
+		var tx PasskeyRegistrationTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("PasskeyRegistrationTx failed")
+			return false, &tags, err
+		}
+		if err := abci.checkPasskeyRegistration(tx); err != nil {
+			return false, &tags, fmt.Errorf("could not register passkey: %w", err)
+		}
+		if err := abci.registerPasskey(tx); err != nil {
+			return false, &tags, fmt.Errorf("could not store passkey: %w", err)
+		}
+		return true, &tags, nil
+
+	case byte(4): // token use
+		var tx TokenSeenTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("TokenSeenTx failed")
+			return false, &tags, err
+		}
+		if err := abci.checkTokenSeen(tx); err != nil {
+			return false, &tags, err
+		}
+		if err := abci.recordTokenSeen(tx); err != nil {
+			return false, &tags, fmt.Errorf("could not record token use: %w", err)
+		}
+		return true, &tags, nil
+
+	case byte(5): // app policy
+		var tx AppPolicyTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("AppPolicyTx failed")
+			return false, &tags, err
+		}
+		policy, err := abci.checkAppPolicy(tx)
+		if err != nil {
+			return false, &tags, fmt.Errorf("could not set app policy: %w", err)
+		}
+		if err := abci.recordAppPolicyVote(tx, *policy, senderDetails.Index, threshold); err != nil {
+			return false, &tags, fmt.Errorf("could not record app policy vote: %w", err)
+		}
+		return true, &tags, nil
+
+	case byte(6): // recovery request or cancellation
+		var tx RecoveryTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("RecoveryTx failed")
+			return false, &tags, err
+		}
+		policy, err := abci.checkRecovery(tx)
+		if err != nil {
+			return false, &tags, fmt.Errorf("could not update recovery: %w", err)
+		}
+		if err := abci.applyRecovery(tx, policy); err != nil {
+			return false, &tags, fmt.Errorf("could not store recovery: %w", err)
+		}
+		return true, &tags, nil
+
+	case byte(7): // key buffer policy vote
+		var tx BufferPolicyVoteTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("BufferPolicyVoteTx failed")
+			return false, &tags, err
+		}
+		if err := checkBufferPolicyVote(tx); err != nil {
+			return false, &tags, err
+		}
+		if err := abci.recordBufferPolicyVote(tx, senderDetails.Index, threshold); err != nil {
+			return false, &tags, fmt.Errorf("could not record key buffer policy vote: %w", err)
+		}
+		return true, &tags, nil
+
+	case byte(8): // passkey signature counter
+		var tx PasskeySignCountTx
+		if err := bijson.Unmarshal(bftTx, &tx); err != nil {
+			log.WithError(err).Error("PasskeySignCountTx failed")
+			return false, &tags, err
+		}
+		if err := abci.checkPasskeySignCount(tx); err != nil {
+			return false, &tags, err
+		}
+		if err := abci.recordPasskeySignCount(tx); err != nil {
+			return false, &tags, fmt.Errorf("could not record passkey signature counter: %w", err)
+		}
+		return true, &tags, nil
 	}
 	return false, &tags, errors.New("Invalid tx type")
 }
@@ -1,6 +1,7 @@
 package tendermint
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"os"
@@ -39,6 +40,10 @@ func (s *ABCIService) ID() string {
 	return common.ABCI_SERVICE_NAME
 }
 
+func (*ABCIService) Dependencies() []string {
+	return []string{common.CHAIN_SERVICE_NAME, common.DB_SERVICE_NAME}
+}
+
 func (s *ABCIService) Start() error {
 	s.ABCI = s.ABCI.NewABCI(s.broker)
 	socketAddr := common.GetSocketAddress()
@@ -58,11 +63,29 @@ func (s *ABCIService) Start() error {
 }
 
 func (service *ABCIService) IsRunning() bool {
-	return true
+	return service.socketServer != nil && service.socketServer.IsRunning()
+}
+
+// Drain stops EndBlock from starting new keygens. Blocks keep being
+// processed so that the keygens already started can complete.
+func (service *ABCIService) Drain(ctx context.Context) error {
+	if service.ABCI != nil {
+		service.ABCI.draining.Store(true)
+	}
+	return nil
 }
 
 func (service *ABCIService) Stop() error {
-	return service.socketServer.Stop()
+	if service.socketServer == nil {
+		return nil
+	}
+	err := service.socketServer.Stop()
+	if service.ABCI != nil && service.ABCI.db != nil {
+		if closeErr := service.ABCI.db.Close(); closeErr != nil {
+			log.WithError(closeErr).Error("ABCI.db.Close()")
+		}
+	}
+	return err
 }
 
 func (a *ABCIService) Call(method string, args ...interface{}) (interface{}, error) {
@@ -72,6 +95,8 @@ func (a *ABCIService) Call(method string, args ...interface{}) (interface{}, err
 		return a.ABCI.state.LastCreatedIndex, nil
 	case "last_unassigned_index":
 		return a.ABCI.state.LastUnassignedIndex, nil
+	case "key_buffer_status":
+		return a.ABCI.keyBufferStatus(), nil
 	case "retrieve_key_mapping":
 		var keyIndex big.Int
 		var curve common.CurveName
@@ -93,6 +118,35 @@ func (a *ABCIService) Call(method string, args ...interface{}) (interface{}, err
 
 		keyIndexes, err := a.ABCI.getIndexesFromVerifierID(provider, userID, appID, curve)
 		return keyIndexes, err
+	case "retrieve_passkey_credential":
+		var appID, credentialID string
+		_ = common.CastOrUnmarshal(args[0], &appID)
+		_ = common.CastOrUnmarshal(args[1], &credentialID)
+
+		credential, err := a.ABCI.retrievePasskeyCredential(appID, credentialID)
+		if err != nil {
+			return nil, err
+		}
+		return *credential, nil
+	case "retrieve_seen_token":
+		var tokenCommitment string
+		_ = common.CastOrUnmarshal(args[0], &tokenCommitment)
+
+		return a.ABCI.retrieveSeenToken(tokenCommitment)
+	case "retrieve_app_policy":
+		var appID string
+		_ = common.CastOrUnmarshal(args[0], &appID)
+
+		return a.ABCI.retrieveAppPolicy(appID)
+	case "retrieve_recovery":
+		var keyIndex big.Int
+		var curve common.CurveName
+		_ = common.CastOrUnmarshal(args[0], &keyIndex)
+		_ = common.CastOrUnmarshal(args[1], &curve)
+
+		return a.ABCI.retrieveRecovery(keyIndex, curve)
+	case "last_block_time":
+		return a.ABCI.lastBlockTime(), nil
 	}
 
 	return nil, fmt.Errorf("ABCI service method %v not found", method)
@@ -0,0 +1,124 @@
+package tendermint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	tmdb "github.com/tendermint/tm-db"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+var (
+	appPolicyPrefixKey     = []byte("ap")
+	appPolicyVotePrefixKey = []byte("av")
+)
+
+func appPolicyKey(appID string) []byte {
+	return append(append([]byte(nil), appPolicyPrefixKey...), appID...)
+}
+
+func appPolicyVotePrefix(appID string) []byte {
+	return append(append([]byte(nil), appPolicyVotePrefixKey...), appID+common.Delimiter1...)
+}
+
+func appPolicyVoteKey(appID string, node int) []byte {
+	return append(appPolicyVotePrefix(appID), strconv.Itoa(node)...)
+}
+
+// retrieveAppPolicy returns the policy of an app, the zero value if the app
+// has none.
+func (app *ABCI) retrieveAppPolicy(appID string) (common.AppPolicy, error) {
+	var policy common.AppPolicy
+	b, err := app.db.Get(appPolicyKey(appID))
+	if err != nil || b == nil {
+		return policy, err
+	}
+	err = bijson.Unmarshal(b, &policy)
+	return policy, err
+}
+
+// checkAppPolicy parses the policy of the tx, and checks that it is signed
+// by the owner in the tx and not older than the current policy. The owner is
+// what the node that broadcast the tx looked up on chain, so a policy is only
+// adopted once threshold nodes vote for it.
+func (app *ABCI) checkAppPolicy(tx AppPolicyTx) (*common.AppPolicy, error) {
+	var policy common.AppPolicy
+	if err := bijson.Unmarshal(tx.Policy, &policy); err != nil {
+		return nil, fmt.Errorf("could not parse policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	current, err := app.retrieveAppPolicy(policy.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Version < current.Version {
+		return nil, fmt.Errorf("policy version %d is below %d", policy.Version, current.Version)
+	}
+	signer, err := common.AppPolicySigner(tx.Policy, tx.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if signer != tx.Owner {
+		return nil, errors.New("policy is not signed by the app owner")
+	}
+	return &policy, nil
+}
+
+// recordAppPolicyVote replaces the vote of a node for the policy of an app,
+// and adopts the policy once threshold nodes voted for the same tx. Votes
+// that come in after the policy was adopted change nothing.
+func (app *ABCI) recordAppPolicyVote(tx AppPolicyTx, policy common.AppPolicy, node, threshold int) error {
+	current, err := app.retrieveAppPolicy(policy.AppID)
+	if err != nil {
+		return err
+	}
+	if policy.Version <= current.Version {
+		return nil
+	}
+	vote, err := bijson.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	if err := app.db.Set(appPolicyVoteKey(policy.AppID, node), vote); err != nil {
+		return err
+	}
+
+	it, err := tmdb.IteratePrefix(app.db, appPolicyVotePrefix(policy.AppID))
+	if err != nil {
+		return err
+	}
+	votes := 0
+	for ; it.Valid(); it.Next() {
+		if bytes.Equal(it.Value(), vote) {
+			votes++
+		}
+	}
+	it.Close()
+	if votes < threshold {
+		return nil
+	}
+	if err := app.storeAppPolicy(policy); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"appId":   policy.AppID,
+		"version": policy.Version,
+		"votes":   votes,
+	}).Info("app policy adopted")
+	return nil
+}
+
+func (app *ABCI) storeAppPolicy(policy common.AppPolicy) error {
+	b, err := bijson.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return app.db.Set(appPolicyKey(policy.AppID), b)
+}
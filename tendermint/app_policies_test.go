@@ -0,0 +1,91 @@
+package tendermint
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+func TestAppPolicies(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	app := &ABCI{db: db, state: &State{}}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := []byte(`{"app_id":"app","version":1,"required_verifiers":["google"]}`)
+	signature, err := crypto.Sign(accounts.TextHash(document), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	signer, err := common.AppPolicySigner(document, signature)
+	if err != nil || signer != owner {
+		t.Errorf("AppPolicySigner = %s, %v", signer.Hex(), err)
+	}
+
+	if _, err := app.checkAppPolicy(AppPolicyTx{Policy: document, Signature: signature, Owner: owner}); err != nil {
+		t.Errorf("policy signed by the owner was refused: %v", err)
+	}
+	if _, err := app.checkAppPolicy(AppPolicyTx{Policy: document, Signature: signature}); err == nil {
+		t.Error("policy not signed by the owner was accepted")
+	}
+
+	// One node naming an owner does not set the policy.
+	vote := func(tx AppPolicyTx, node int) error {
+		policy, err := app.checkAppPolicy(tx)
+		if err != nil {
+			return err
+		}
+		return app.recordAppPolicyVote(tx, *policy, node, 2)
+	}
+	tx := AppPolicyTx{Policy: document, Signature: signature, Owner: owner}
+	for _, node := range []int{1, 1} {
+		if err := vote(tx, node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := crypto.Sign(accounts.TextHash(document), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vote(AppPolicyTx{Policy: document, Signature: forged, Owner: crypto.PubkeyToAddress(other.PublicKey)}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := app.retrieveAppPolicy("app"); policy.Version != 0 {
+		t.Errorf("policy set without threshold votes for it = %+v", policy)
+	}
+	if err := vote(tx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := app.retrieveAppPolicy("app"); policy.Version != 1 {
+		t.Errorf("policy not set by threshold votes = %+v", policy)
+	}
+	if err := vote(tx, 3); err != nil {
+		t.Errorf("vote after the policy was set: %v", err)
+	}
+
+	if err := app.storeAppPolicy(common.AppPolicy{AppID: "app", Version: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.checkAppPolicy(tx); err == nil {
+		t.Error("older policy was accepted")
+	}
+	if policy, err := app.retrieveAppPolicy("other"); err != nil || policy.Version != 0 {
+		t.Errorf("policy of app without one = %+v, %v", policy, err)
+	}
+}
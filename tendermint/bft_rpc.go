@@ -3,14 +3,20 @@ package tendermint
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strings"
 
 	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
 	"github.com/arcana-network/dkgnode/secp256k1"
 	"github.com/arcana-network/dkgnode/tendermint/messageq"
+	"github.com/arcana-network/dkgnode/webauthn"
 
 	"github.com/arcana-network/dkgnode/eventbus"
+	ethCommon "github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
 	"github.com/tendermint/tendermint/rpc/client"
 	tmtypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -44,10 +50,85 @@ type AssignmentTx struct {
 	Curve    common.CurveName
 }
 
-// mapping of name of struct to id
+// PasskeyRegistrationTx registers a passkey for a user of an app. A user
+// who already has passkeys needs one of them to approve the new one; the
+// first passkey needs the user to sign in through a primary verifier, which
+// the node that broadcast the tx checked and records in Verifier. RPID is the
+// relying party ID of the passkey verifier of the app, which that node looked
+// up on chain.
+type PasskeyRegistrationTx struct {
+	AppID        string
+	UserID       string
+	CredentialID string
+	PublicKey    []byte
+	Algorithm    int
+	Approval     *webauthn.Assertion
+	RPID         string
+	Verifier     string
+}
+
+// PasskeySignCountTx records the signature counter of a passkey assertion
+// over a token commitment, so that every node refuses older counters.
+type PasskeySignCountTx struct {
+	AppID           string
+	CredentialID    string
+	SignCount       uint32
+	TokenCommitment string
+}
+
+// TokenSeenTx records that a token was used in a commitment flow, identified
+// by the temporary key the shares are encrypted to. The first flow recorded
+// for a token is the only one it unlocks shares in.
+type TokenSeenTx struct {
+	TokenCommitment string
+	TempPubKey      common.Point
+}
+
+// AppPolicyTx sets the policy of an app. Policy is the JSON document of a
+// common.AppPolicy as the owner of the app signed it, with personal_sign.
+// Owner is the owner of the app on chain when the tx was broadcast. Every
+// node broadcasts the tx it served, and the policy is set once threshold
+// nodes sent the same tx.
+type AppPolicyTx struct {
+	Policy    []byte
+	Signature []byte
+	Owner     ethCommon.Address
+}
+
+// RecoveryTx requests the recovery of a key through a backup verifier, or
+// cancels it through a primary verifier of the user.
+type RecoveryTx struct {
+	Cancel   bool
+	AppID    string
+	KeyIndex big.Int
+	Curve    common.CurveName
+	Verifier string
+}
+
+// BufferPolicyVoteTx is the vote of a node for the key buffer policy of a
+// curve, cast by its operator. The policy is adopted once a threshold of
+// nodes voted for it.
+type BufferPolicyVoteTx struct {
+	Curve  common.CurveName
+	Policy config.KeyBufferPolicy
+}
+
+// bftMsgQueueCapacity is the number of txs per priority class that may wait
+// for submission before new ones are rejected.
+const bftMsgQueueCapacity = 1000
+
+// mapping of name of struct to id. Txs are looked up by the name of their
+// type alone, so the services that cannot import this package broadcast
+// their own copies of the tx structs, with the same name and fields.
 var txTypeMap = map[string]byte{
-	getType(AssignmentTx{}):      byte(1),
-	getType(common.DKGMessage{}): byte(2),
+	getType(AssignmentTx{}):          byte(1),
+	getType(common.DKGMessage{}):     byte(2),
+	getType(PasskeyRegistrationTx{}): byte(3),
+	getType(TokenSeenTx{}):           byte(4),
+	getType(AppPolicyTx{}):           byte(5),
+	getType(RecoveryTx{}):            byte(6),
+	getType(BufferPolicyVoteTx{}):    byte(7),
+	getType(PasskeySignCountTx{}):    byte(8),
 }
 
 func (wrapper *DefaultBFTTxWrapper) PrepareBFTTx(bftTx interface{}, broker *common.MessageBroker) ([]byte, error) {
@@ -62,7 +143,10 @@ func (wrapper *DefaultBFTTxWrapper) PrepareBFTTx(bftTx interface{}, broker *comm
 		return nil, fmt.Errorf("could not generate random number")
 	}
 	wrapper.Nonce = uint32(nonce.Int64())
-	pk := broker.ChainMethods().GetSelfPublicKey()
+	pk, err := broker.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return nil, err
+	}
 	wrapper.PubKey.X = pk.X
 	wrapper.PubKey.Y = pk.Y
 	bftRaw, err := bijson.Marshal(bftTx)
@@ -73,7 +157,10 @@ func (wrapper *DefaultBFTTxWrapper) PrepareBFTTx(bftTx interface{}, broker *comm
 
 	// sign message data
 	data := wrapper.GetSerializedBody()
-	wrapper.Signature = broker.ChainMethods().SelfSignData(data)
+	wrapper.Signature, err = broker.ChainMethods().SelfSignData(data)
+	if err != nil {
+		return nil, err
+	}
 
 	rawMsg, err := bijson.Marshal(wrapper)
 	if err != nil {
@@ -117,8 +204,15 @@ func (bftrpc BFTRPC) Broadcast(bftTx interface{}) (*common.Hash, error) {
 		return nil, err
 	}
 
-	res := bftrpc.BftMsgQueue.Add(preparedTx)
+	add := bftrpc.BftMsgQueue.Add
+	if isKeygenTx(bftTx) {
+		add = bftrpc.BftMsgQueue.AddWaiting
+	}
+	res, err := add(preparedTx, txPriority(bftTx))
 	log.WithField("Tx", bftTx).Info("BFTRPC:Broadcast()")
+	if err != nil {
+		return nil, fmt.Errorf("could not broadcast: %w", err)
+	}
 
 	response, ok := res.(*tmtypes.ResultBroadcastTx)
 	if !ok {
@@ -156,12 +250,45 @@ func (bftrpc BFTRPC) Retrieve(hash []byte, txStruct BFTTxWrapper) (err error) {
 func NewBFTRPC(tmclient client.Client, bus eventbus.Bus) *BFTRPC {
 	var rpc BFTRPC
 	rpc.Client = tmclient
-	rpc.BftMsgQueue = messageq.NewMessageQueue(rpc.messageRunFunc)
+	rpc.BftMsgQueue = messageq.NewMessageQueue(rpc.messageRunFunc, bftMsgQueueCapacity, isRetryableBroadcastError)
 	rpc.BftMsgQueue.RunMsgEngine(50)
 	rpc.broker = common.NewServiceBroker(bus, "bft-rpc")
 	return &rpc
 }
 
+// txPriority puts user facing transactions ahead of keygen traffic, matching
+// on the type name like txTypeMap.
+func txPriority(bftTx interface{}) messageq.Priority {
+	switch getType(bftTx) {
+	case getType(AssignmentTx{}), getType(PasskeyRegistrationTx{}), getType(PasskeySignCountTx{}), getType(TokenSeenTx{}), getType(RecoveryTx{}):
+		return messageq.PriorityHigh
+	}
+	return messageq.PriorityNormal
+}
+
+// isKeygenTx reports whether bftTx is keygen protocol traffic. A dropped
+// keygen tx can stall a round and nothing would resend it, so these wait
+// for room in the queue instead of being rejected like client txs.
+func isKeygenTx(bftTx interface{}) bool {
+	return getType(bftTx) == getType(common.DKGMessage{})
+}
+
+// isRetryableBroadcastError reports whether resubmitting a tx might succeed.
+// Tendermint rejects duplicate and oversized txs for good, so those are not
+// retried.
+func isRetryableBroadcastError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, permanent := range []string{"tx already exists in cache", "tx too large", "invalid tx"} {
+		if strings.Contains(msg, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
 func (bftrpc *BFTRPC) messageRunFunc(msg []byte) (interface{}, error) {
 	response, err := bftrpc.BroadcastTxSync(context.Background(), msg)
 	if err != nil {
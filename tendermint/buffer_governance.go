@@ -0,0 +1,130 @@
+package tendermint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	tmdb "github.com/tendermint/tm-db"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+var (
+	bufferVotePrefixKey   = []byte("bv")
+	bufferPolicyPrefixKey = []byte("bg")
+	bufferStatePrefixKey  = []byte("bs")
+)
+
+func bufferVotePrefix(curve common.CurveName) []byte {
+	return append(append([]byte(nil), bufferVotePrefixKey...), string(curve)+common.Delimiter1...)
+}
+
+func bufferVoteKey(curve common.CurveName, node int) []byte {
+	return append(bufferVotePrefix(curve), strconv.Itoa(node)...)
+}
+
+func bufferPolicyKey(curve common.CurveName) []byte {
+	return append(append([]byte(nil), bufferPolicyPrefixKey...), curve...)
+}
+
+func bufferStateKey(curve common.CurveName) []byte {
+	return append(append([]byte(nil), bufferStatePrefixKey...), curve...)
+}
+
+func checkBufferPolicyVote(tx BufferPolicyVoteTx) error {
+	if tx.Curve != common.SECP256K1 && tx.Curve != common.ED25519 {
+		return fmt.Errorf("unknown curve %q", tx.Curve)
+	}
+	return tx.Policy.Verify(string(tx.Curve))
+}
+
+// recordBufferPolicyVote replaces the vote of a node, and adopts the policy
+// once threshold nodes voted for it.
+func (app *ABCI) recordBufferPolicyVote(tx BufferPolicyVoteTx, node, threshold int) error {
+	vote, err := bijson.Marshal(tx.Policy)
+	if err != nil {
+		return err
+	}
+	if err := app.db.Set(bufferVoteKey(tx.Curve, node), vote); err != nil {
+		return err
+	}
+
+	it, err := tmdb.IteratePrefix(app.db, bufferVotePrefix(tx.Curve))
+	if err != nil {
+		return err
+	}
+	votes := 0
+	for ; it.Valid(); it.Next() {
+		var policy config.KeyBufferPolicy
+		if err := bijson.Unmarshal(it.Value(), &policy); err == nil && policy == tx.Policy {
+			votes++
+		}
+	}
+	it.Close()
+	if votes < threshold {
+		return nil
+	}
+	if err := app.db.Set(bufferPolicyKey(tx.Curve), vote); err != nil {
+		return err
+	}
+	app.buffer.SetGoverned(tx.Curve, tx.Policy)
+	log.WithFields(log.Fields{
+		"curve":  tx.Curve,
+		"policy": tx.Policy,
+		"votes":  votes,
+	}).Info("key buffer policy adopted")
+	return nil
+}
+
+// loadGovernedBufferPolicies sets the policies the nodes voted for on the
+// buffer policy.
+func (app *ABCI) loadGovernedBufferPolicies() error {
+	it, err := tmdb.IteratePrefix(app.db, bufferPolicyPrefixKey)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var policy config.KeyBufferPolicy
+		if err := bijson.Unmarshal(it.Value(), &policy); err != nil {
+			return err
+		}
+		curve := common.CurveName(strings.TrimPrefix(string(it.Key()), string(bufferPolicyPrefixKey)))
+		app.buffer.SetGoverned(curve, policy)
+	}
+	return nil
+}
+
+// saveBufferState stores what the buffer policy remembers about a curve. It
+// is kept out of the app hash: the local overrides make the plans of the
+// nodes differ, which is fine as every node deals its own keygens.
+func (app *ABCI) saveBufferState(curve common.CurveName) error {
+	state, err := bijson.Marshal(app.buffer.curveState(curve))
+	if err != nil {
+		return err
+	}
+	return app.db.Set(bufferStateKey(curve), state)
+}
+
+// loadBufferStates restores what the buffer policy remembered about each
+// curve before the node stopped.
+func (app *ABCI) loadBufferStates() error {
+	it, err := tmdb.IteratePrefix(app.db, bufferStatePrefixKey)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var state curveBufferState
+		if err := bijson.Unmarshal(it.Value(), &state); err != nil {
+			return err
+		}
+		curve := common.CurveName(strings.TrimPrefix(string(it.Key()), string(bufferStatePrefixKey)))
+		app.buffer.setCurveState(curve, state)
+	}
+	return nil
+}
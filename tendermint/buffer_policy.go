@@ -0,0 +1,236 @@
+package tendermint
+
+import (
+	"math"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+const (
+	// rateSmoothing is the weight given to the latest block when updating the
+	// moving average of assignments per block.
+	rateSmoothing = 0.2
+	// refillHeadroom is how many blocks worth of observed demand are
+	// started per block.
+	refillHeadroom = 2.0
+	// keygenStallBlocks is the number of blocks without a completed keygen
+	// after which the started keygens are taken as lost and dealt again.
+	// It is counted in block heights, so blocks a node missed while down
+	// count too.
+	keygenStallBlocks = 50
+)
+
+// CurveBufferPolicy is the effective buffer policy for one curve.
+type CurveBufferPolicy struct {
+	Target        int
+	MinRefill     int
+	MaxRefill     int
+	MaxConcurrent int
+}
+
+// curveBufferState is what the policy remembers about a curve between
+// blocks. It is stored with the ABCI state, so a restarted node picks up
+// where it left off.
+type curveBufferState struct {
+	Observed       bool    `json:"observed"`
+	LastUnassigned uint    `json:"last_unassigned"`
+	LastStarted    uint    `json:"last_started"`
+	LastCreated    uint    `json:"last_created"`
+	ProgressHeight int64   `json:"progress_height"`
+	Rate           float64 `json:"rate"`
+}
+
+// BufferPolicy decides, per curve and per block, which key indexes should
+// have their keygen started. The refill rate follows the observed
+// assignment rate and is bounded by the limits configured on this node and
+// the ones voted by the nodes, which take precedence.
+type BufferPolicy struct {
+	sync.Mutex
+	overrides map[common.CurveName]config.KeyBufferPolicy
+	governed  map[common.CurveName]config.KeyBufferPolicy
+	curves    map[common.CurveName]*curveBufferState
+}
+
+func NewBufferPolicy(overrides map[string]config.KeyBufferPolicy) *BufferPolicy {
+	policy := &BufferPolicy{
+		governed: make(map[common.CurveName]config.KeyBufferPolicy),
+		curves:   make(map[common.CurveName]*curveBufferState),
+	}
+	policy.SetOverrides(overrides)
+	return policy
+}
+
+// SetOverrides replaces the configured per-curve overrides.
+func (p *BufferPolicy) SetOverrides(overrides map[string]config.KeyBufferPolicy) {
+	p.Lock()
+	defer p.Unlock()
+	p.overrides = make(map[common.CurveName]config.KeyBufferPolicy)
+	for curve, override := range overrides {
+		p.overrides[common.CurveName(curve)] = override
+	}
+}
+
+// SetGoverned sets the policy of a curve the nodes voted for.
+func (p *BufferPolicy) SetGoverned(curve common.CurveName, policy config.KeyBufferPolicy) {
+	p.Lock()
+	defer p.Unlock()
+	p.governed[curve] = policy
+}
+
+// defaultCurvePolicy derives a policy from the contract buffer size. It
+// matches the limits the node used before the policy was configurable.
+func defaultCurvePolicy(curve common.CurveName, buffer int) CurveBufferPolicy {
+	maxRefill := 100
+	if buffer > 500 {
+		maxRefill = buffer / 500
+	}
+	target := buffer
+	if curve == common.ED25519 {
+		target = buffer / 10
+		maxRefill = maxRefill / 10
+	}
+	maxRefill = MaxOf(maxRefill, 1)
+	return CurveBufferPolicy{
+		Target:        target,
+		MinRefill:     MaxOf(maxRefill/5, 1),
+		MaxRefill:     maxRefill,
+		MaxConcurrent: maxRefill,
+	}
+}
+
+// Policy returns the effective policy for a curve, given the buffer size
+// set on the contract.
+func (p *BufferPolicy) Policy(curve common.CurveName, buffer int) CurveBufferPolicy {
+	p.Lock()
+	defer p.Unlock()
+	return p.policy(curve, buffer)
+}
+
+func (p *BufferPolicy) policy(curve common.CurveName, buffer int) CurveBufferPolicy {
+	policy := defaultCurvePolicy(curve, buffer)
+	if override, ok := p.overrides[curve]; ok {
+		policy = policy.override(override)
+	}
+	if governed, ok := p.governed[curve]; ok {
+		policy = policy.override(governed)
+	}
+	return policy
+}
+
+// override returns the policy with the set fields of override.
+func (policy CurveBufferPolicy) override(override config.KeyBufferPolicy) CurveBufferPolicy {
+	if override.Target > 0 {
+		policy.Target = override.Target
+	}
+	if override.MaxRefill > 0 {
+		policy.MaxRefill = override.MaxRefill
+	}
+	if override.MinRefill > 0 {
+		policy.MinRefill = override.MinRefill
+	}
+	if override.MaxConcurrent > 0 {
+		policy.MaxConcurrent = override.MaxConcurrent
+	}
+	policy.MinRefill = MinOf(policy.MinRefill, policy.MaxRefill)
+	return policy
+}
+
+// curveState returns a copy of what the policy remembers about a curve.
+func (p *BufferPolicy) curveState(curve common.CurveName) curveBufferState {
+	p.Lock()
+	defer p.Unlock()
+	return *p.state(curve)
+}
+
+// setCurveState restores what the policy remembered about a curve.
+func (p *BufferPolicy) setCurveState(curve common.CurveName, s curveBufferState) {
+	p.Lock()
+	defer p.Unlock()
+	p.curves[curve] = &s
+}
+
+func (p *BufferPolicy) state(curve common.CurveName) *curveBufferState {
+	s, ok := p.curves[curve]
+	if !ok {
+		s = &curveBufferState{}
+		p.curves[curve] = s
+	}
+	return s
+}
+
+// Observe records the assignments made since the previous block and
+// updates the moving average of the assignment rate.
+func (p *BufferPolicy) Observe(curve common.CurveName, created, unassigned uint) MappingCounter {
+	p.Lock()
+	defer p.Unlock()
+	s := p.state(curve)
+
+	counter := MappingCounter{KeyCount: MaxOf(int(created)-int(unassigned), 0)}
+	if s.Observed && unassigned > s.LastUnassigned {
+		counter.RequiredCount = int(unassigned - s.LastUnassigned)
+	}
+	if s.Observed {
+		s.Rate = (1-rateSmoothing)*s.Rate + rateSmoothing*float64(counter.RequiredCount)
+	}
+	s.Observed = true
+	s.LastUnassigned = unassigned
+	return counter
+}
+
+// Plan returns the range [start, end) of key indexes whose keygen should be
+// started in the block at height. An empty range means nothing should be
+// started.
+func (p *BufferPolicy) Plan(curve common.CurveName, buffer int, height int64, created, unassigned uint) (start, end int) {
+	p.Lock()
+	defer p.Unlock()
+	policy := p.policy(curve, buffer)
+	s := p.state(curve)
+
+	// Keygens that stall or are cancelled never complete, so the window
+	// would stay full. They are dealt again from the created index, as they
+	// were before the window was tracked.
+	if created > s.LastCreated || s.LastStarted <= created {
+		s.LastCreated = created
+		s.ProgressHeight = height
+	} else if height-s.ProgressHeight >= keygenStallBlocks {
+		log.WithFields(log.Fields{
+			"curve":          curve,
+			"created":        created,
+			"lastStarted":    s.LastStarted,
+			"progressHeight": s.ProgressHeight,
+		}).Warn("keygens stalled, dealing them again")
+		s.LastStarted = created
+		s.ProgressHeight = height
+	}
+
+	start = MaxOf(int(created), int(s.LastStarted))
+	fill := int(created) - int(unassigned)
+	if fill >= policy.Target {
+		return start, start
+	}
+
+	refill := int(math.Ceil(s.Rate * refillHeadroom))
+	if fill < policy.Target/2 {
+		refill = policy.MaxRefill
+	}
+	refill = MaxOf(MinOf(refill, policy.MaxRefill), policy.MinRefill)
+
+	end = MinOf(start+refill, int(created)+policy.MaxConcurrent)
+	end = MinOf(end, int(unassigned)+policy.Target)
+	if end <= start {
+		return start, start
+	}
+	s.LastStarted = uint(end)
+	return start, end
+}
+
+// Rate returns the moving average of assignments per block for a curve.
+func (p *BufferPolicy) Rate(curve common.CurveName) float64 {
+	p.Lock()
+	defer p.Unlock()
+	return p.state(curve).Rate
+}
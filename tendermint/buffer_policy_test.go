@@ -0,0 +1,192 @@
+package tendermint
+
+import (
+	"testing"
+
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+func TestDefaultCurvePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		curve  common.CurveName
+		buffer int
+		want   CurveBufferPolicy
+	}{
+		{"small-secp", common.SECP256K1, 200, CurveBufferPolicy{Target: 200, MinRefill: 20, MaxRefill: 100, MaxConcurrent: 100}},
+		{"large-secp", common.SECP256K1, 50000, CurveBufferPolicy{Target: 50000, MinRefill: 20, MaxRefill: 100, MaxConcurrent: 100}},
+		{"large-ed", common.ED25519, 50000, CurveBufferPolicy{Target: 5000, MinRefill: 2, MaxRefill: 10, MaxConcurrent: 10}},
+		{"tiny-ed", common.ED25519, 10, CurveBufferPolicy{Target: 1, MinRefill: 2, MaxRefill: 10, MaxConcurrent: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := defaultCurvePolicy(tt.curve, tt.buffer)
+			if got != tt.want {
+				t.Errorf("defaultCurvePolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBufferPolicyOverrides(t *testing.T) {
+	policy := NewBufferPolicy(map[string]config.KeyBufferPolicy{
+		"ed25519": {Target: 40, MaxRefill: 4},
+	})
+	got := policy.Policy(common.ED25519, 50000)
+	want := CurveBufferPolicy{Target: 40, MinRefill: 2, MaxRefill: 4, MaxConcurrent: 10}
+	if got != want {
+		t.Errorf("Policy() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBufferPolicyPlanRespectsConcurrency(t *testing.T) {
+	policy := NewBufferPolicy(map[string]config.KeyBufferPolicy{
+		"secp256k1": {Target: 100, MinRefill: 5, MaxRefill: 20, MaxConcurrent: 30},
+	})
+
+	start, end := policy.Plan(common.SECP256K1, 0, 1, 0, 0)
+	if start != 0 || end != 20 {
+		t.Fatalf("first Plan() = [%d, %d), want [0, 20)", start, end)
+	}
+
+	// Nothing completed yet, only 10 more fit in the concurrency window.
+	start, end = policy.Plan(common.SECP256K1, 0, 2, 0, 0)
+	if start != 20 || end != 30 {
+		t.Fatalf("second Plan() = [%d, %d), want [20, 30)", start, end)
+	}
+
+	start, end = policy.Plan(common.SECP256K1, 0, 3, 0, 0)
+	if start != end {
+		t.Fatalf("third Plan() = [%d, %d), want empty range", start, end)
+	}
+}
+
+func TestBufferPolicyAdaptsToAssignmentRate(t *testing.T) {
+	policy := NewBufferPolicy(map[string]config.KeyBufferPolicy{
+		"secp256k1": {Target: 150, MinRefill: 1, MaxRefill: 50, MaxConcurrent: 1000},
+	})
+
+	// Buffer above half of the target and no demand: refill at the minimum.
+	policy.Observe(common.SECP256K1, 80, 0)
+	start, end := policy.Plan(common.SECP256K1, 0, 1, 80, 0)
+	if end-start != 1 {
+		t.Fatalf("idle Plan() started %d keygens, want 1", end-start)
+	}
+
+	// Steady demand of 10 keys per block raises the refill rate.
+	created, unassigned := uint(81), uint(0)
+	for i := 0; i < 20; i++ {
+		unassigned += 10
+		created += 10
+		policy.Observe(common.SECP256K1, created, unassigned)
+	}
+	if rate := policy.Rate(common.SECP256K1); rate < 9 || rate > 10 {
+		t.Fatalf("Rate() = %f, want close to 10", rate)
+	}
+	start, end = policy.Plan(common.SECP256K1, 0, 2, created, unassigned)
+	if end-start != 20 {
+		t.Fatalf("busy Plan() started %d keygens, want 20", end-start)
+	}
+}
+
+func TestBufferPolicyRecoversFromStalledKeygens(t *testing.T) {
+	policy := NewBufferPolicy(map[string]config.KeyBufferPolicy{
+		"secp256k1": {Target: 100, MinRefill: 10, MaxRefill: 10, MaxConcurrent: 10},
+	})
+	if start, end := policy.Plan(common.SECP256K1, 0, 1, 0, 0); start != 0 || end != 10 {
+		t.Fatalf("first Plan() = [%d, %d), want [0, 10)", start, end)
+	}
+	// None of the keygens complete.
+	for height := int64(2); height <= keygenStallBlocks; height++ {
+		if start, end := policy.Plan(common.SECP256K1, 0, height, 0, 0); start != end {
+			t.Fatalf("Plan() while keygens run = [%d, %d), want empty range", start, end)
+		}
+	}
+	if start, end := policy.Plan(common.SECP256K1, 0, keygenStallBlocks+1, 0, 0); start != 0 || end != 10 {
+		t.Fatalf("Plan() after the stall = [%d, %d), want [0, 10)", start, end)
+	}
+
+	// Progress resets the stall height.
+	policy.Plan(common.SECP256K1, 0, 2*keygenStallBlocks, 0, 0)
+	if start, end := policy.Plan(common.SECP256K1, 0, 2*keygenStallBlocks+1, 5, 0); start != 10 || end != 15 {
+		t.Fatalf("Plan() after progress = [%d, %d), want [10, 15)", start, end)
+	}
+
+	// Blocks a node missed while it was down count towards a stall.
+	if start, end := policy.Plan(common.SECP256K1, 0, 3*keygenStallBlocks+1, 5, 0); start != 5 || end != 15 {
+		t.Fatalf("Plan() after a long gap = [%d, %d), want [5, 15)", start, end)
+	}
+}
+
+func TestBufferStateSurvivesRestart(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	overrides := map[string]config.KeyBufferPolicy{
+		"secp256k1": {Target: 100, MinRefill: 10, MaxRefill: 10, MaxConcurrent: 20},
+	}
+	app := &ABCI{db: db, buffer: NewBufferPolicy(overrides)}
+	if start, end := app.buffer.Plan(common.SECP256K1, 0, 1, 0, 0); start != 0 || end != 10 {
+		t.Fatalf("first Plan() = [%d, %d), want [0, 10)", start, end)
+	}
+	if err := app.saveBufferState(common.SECP256K1); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := &ABCI{db: db, buffer: NewBufferPolicy(overrides)}
+	if err := restarted.loadBufferStates(); err != nil {
+		t.Fatal(err)
+	}
+	if start, end := restarted.buffer.Plan(common.SECP256K1, 0, 2, 0, 0); start != 10 || end != 20 {
+		t.Errorf("Plan() after a restart = [%d, %d), want [10, 20)", start, end)
+	}
+}
+
+func TestBufferPolicyVotes(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	app := &ABCI{db: db, state: &State{}, buffer: NewBufferPolicy(map[string]config.KeyBufferPolicy{
+		"ed25519": {MaxRefill: 4},
+	})}
+	vote := BufferPolicyVoteTx{Curve: common.ED25519, Policy: config.KeyBufferPolicy{Target: 40, MaxRefill: 8}}
+
+	if err := checkBufferPolicyVote(BufferPolicyVoteTx{Curve: common.ED25519, Policy: config.KeyBufferPolicy{MinRefill: 5, MaxRefill: 2}}); err == nil {
+		t.Error("invalid policy was accepted")
+	}
+	for node := 1; node <= 2; node++ {
+		if err := app.recordBufferPolicyVote(vote, node, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A node changing its vote does not count twice.
+	if err := app.recordBufferPolicyVote(vote, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := app.buffer.Policy(common.ED25519, 50000).Target; got != 5000 {
+		t.Fatalf("Target before the threshold = %d, want 5000", got)
+	}
+	if err := app.recordBufferPolicyVote(vote, 3, 3); err != nil {
+		t.Fatal(err)
+	}
+	// The voted policy takes precedence over the configured override.
+	want := CurveBufferPolicy{Target: 40, MinRefill: 2, MaxRefill: 8, MaxConcurrent: 10}
+	if got := app.buffer.Policy(common.ED25519, 50000); got != want {
+		t.Fatalf("Policy() = %+v, want %+v", got, want)
+	}
+
+	restarted := &ABCI{db: db, buffer: NewBufferPolicy(nil)}
+	if err := restarted.loadGovernedBufferPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.buffer.Policy(common.ED25519, 50000).Target; got != 40 {
+		t.Errorf("Target after a restart = %d, want 40", got)
+	}
+}
@@ -56,21 +56,44 @@ func (*TendermintService) ID() string {
 	return common.TENDERMINT_SERVICE_NAME
 }
 
+func (*TendermintService) Dependencies() []string {
+	return []string{common.CHAIN_SERVICE_NAME, common.ABCI_SERVICE_NAME}
+}
+
 func (t *TendermintService) IsRunning() bool {
-	return true
+	return t.node != nil && t.node.IsRunning()
+}
+
+// Drain waits for the BFT transactions that were already submitted to be
+// broadcast.
+func (t *TendermintService) Drain(ctx context.Context) error {
+	if t.bftrpc == nil {
+		return nil
+	}
+	queue := t.bftrpc.BftMsgQueue
+	err := common.WaitUntil(ctx, func() bool {
+		return queue.Pending() == 0
+	})
+	if err != nil {
+		return fmt.Errorf("%d bft messages still pending: %w", queue.Pending(), err)
+	}
+	return nil
 }
 
 func (t *TendermintService) Stop() error {
+	if t.node == nil || !t.node.IsRunning() {
+		return nil
+	}
 	return t.node.Stop()
 }
 
 func (t *TendermintService) Start() error {
-	err := createTendermintFolderStructure(config.GlobalConfig.BasePath)
+	err := createTendermintFolderStructure(config.Current().BasePath)
 	if err != nil {
 		log.WithError(err).Fatalln("Error during creation of folder structure")
 	}
 
-	tmRootPath := config.GlobalConfig.BasePath + "/tendermint"
+	tmRootPath := config.Current().BasePath + "/tendermint"
 
 	nodeKey, err := getTendermintNodeKey(tmRootPath)
 	if err != nil {
@@ -111,9 +134,9 @@ func getTendermintNodeKey(tendermintRootPath string) (*tmp2p.NodeKey, error) {
 	dftConfig := cfg.DefaultConfig()
 	dftConfig.SetRoot(tendermintRootPath)
 	var tmNodeKey *tmp2p.NodeKey
-	if len(config.GlobalConfig.TMPrivateKey) != 0 {
+	if len(config.Current().TMPrivateKey) != 0 {
 		tmNodeKey = &tmp2p.NodeKey{
-			PrivKey: ed25519.PrivKey(config.GlobalConfig.TMPrivateKey),
+			PrivKey: ed25519.PrivKey(config.Current().TMPrivateKey),
 		}
 	} else {
 		k, err := tmp2p.LoadOrGenNodeKey(dftConfig.NodeKeyFile())
@@ -129,7 +152,14 @@ func getTendermintNodeKey(tendermintRootPath string) (*tmp2p.NodeKey, error) {
 func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p.NodeKey) {
 	chainMethods := common.NewServiceBroker(t.bus, "tendermint").ChainMethods()
 
-	nodeList := chainMethods.AwaitCompleteNodeList(chainMethods.GetCurrentEpoch())
+	epoch, err := chainMethods.GetCurrentEpoch()
+	if err != nil {
+		log.WithError(err).Fatal("could not get current epoch")
+	}
+	nodeList, err := chainMethods.AwaitCompleteNodeList(epoch)
+	if err != nil {
+		log.WithError(err).Fatal("could not get node list")
+	}
 
 	peerList, validators := getValidatorsAndPeerFromNodeList(nodeList)
 	log.WithFields(log.Fields{
@@ -143,7 +173,7 @@ func (t *TendermintService) startTendermintCore(buildPath string, nodeKey *tmp2p
 	genesisDoc := createGenesisDoc(validators)
 	saveGenesisDoc(genesisDoc, defaultConfig.GenesisFile())
 
-	err := verifyAndSaveConfig(defaultConfig)
+	err = verifyAndSaveConfig(defaultConfig)
 	if err != nil {
 		log.WithError(err).Fatal("config doesnt pass validation checks")
 	}
@@ -211,12 +241,12 @@ func getTendermintConfig(buildPath string, peers string) *cfg.Config {
 
 	defaultConfig.BaseConfig.DBBackend = "goleveldb"
 	defaultConfig.FastSyncMode = false
-	// defaultConfig.RPC.ListenAddress = fmt.Sprintf("tcp://%s:26657", config.GlobalConfig.IPAddress)
+	// defaultConfig.RPC.ListenAddress = fmt.Sprintf("tcp://%s:26657", config.Current().IPAddress)
 	defaultConfig.RPC.ListenAddress = "tcp://0.0.0.0:26657"
 	defaultConfig.RPC.MaxSubscriptionClients = 5
 	defaultConfig.RPC.MaxSubscriptionsPerClient = 200
 
-	// defaultConfig.P2P.ListenAddress = fmt.Sprintf("tcp://%s:26656", config.GlobalConfig.IPAddress)
+	// defaultConfig.P2P.ListenAddress = fmt.Sprintf("tcp://%s:26656", config.Current().IPAddress)
 	defaultConfig.P2P.ListenAddress = "tcp://0.0.0.0:26656"
 	defaultConfig.P2P.MaxNumInboundPeers = 300
 	defaultConfig.P2P.PersistentPeers = peers
@@ -267,6 +297,7 @@ func abciMonitor(t *TendermintService) {
 				t.bftrpc = NewBFTRPC(bftClient, t.bus)
 				t.bftSemaphore = NewSemaphore(30)
 				t.websocketStatus = WebSocketUp
+				go t.forwardAssignments()
 				break
 			}
 		}
@@ -277,6 +308,14 @@ func (t *TendermintService) Call(method string, args ...interface{}) (interface{
 	switch method {
 	case "get_node_key":
 		return tmjson.Marshal(*t.tmNodeKey)
+	case "queue_status":
+		if t.bftrpc == nil {
+			return common.QueueStatus{}, nil
+		}
+		return common.QueueStatus{
+			Queued:  t.bftrpc.BftMsgQueue.Len(),
+			Pending: t.bftrpc.BftMsgQueue.Pending(),
+		}, nil
 	case "tx_status":
 		var args0 []byte
 		_ = common.CastOrUnmarshal(args[0], &args0)
@@ -369,6 +408,53 @@ func (t *TendermintService) RegisterQuery(query string) (chan []byte, context.Ca
 	return responseCh, cancel, nil
 }
 
+// assignmentQuery matches every assignment tx, whether it succeeded or not.
+var assignmentQuery = fmt.Sprintf("tm.event='Tx' AND %s.%s EXISTS", common.AssignmentEventType, common.AssignmentTag)
+
+// assignmentEventCapacity is the number of assignment events buffered for
+// the bus.
+const assignmentEventCapacity = 100
+
+// forwardAssignments publishes the assignment events on the bus, so that the
+// server can push keys to the clients waiting for them.
+func (t *TendermintService) forwardAssignments() {
+	ch, err := t.bftrpc.Subscribe(context.Background(), "assignments", assignmentQuery, assignmentEventCapacity)
+	if err != nil {
+		log.WithError(err).Error("could not subscribe to assignment events")
+		return
+	}
+	for result := range ch {
+		eventDataTx, ok := result.Data.(tmtypes.EventDataTx)
+		if !ok {
+			continue
+		}
+		t.bus.Publish(common.KeyAssignedTopic, keyAssignedEvent(eventDataTx))
+	}
+}
+
+func keyAssignedEvent(e tmtypes.EventDataTx) common.KeyAssignedEvent {
+	event := common.KeyAssignedEvent{
+		TxHash: fmt.Sprintf("%X", tmtypes.Tx(e.Tx).Hash()),
+		OK:     e.Result.IsOK(),
+	}
+	for _, ev := range e.Result.Events {
+		if ev.Type != common.AssignmentEventType {
+			continue
+		}
+		for _, attr := range ev.Attributes {
+			switch string(attr.Key) {
+			case common.AssignmentKeyTag:
+				event.AssignmentKey = string(attr.Value)
+			case common.KeyIndexTag:
+				event.KeyIndex = string(attr.Value)
+			case common.CurveTag:
+				event.Curve = common.CurveName(attr.Value)
+			}
+		}
+	}
+	return event
+}
+
 func (t *TendermintService) isHashValid(hash []byte) bool {
 	res, err := t.bftrpc.Tx(context.Background(), hash, false)
 	if err != nil {
@@ -1,6 +1,8 @@
 package messageq
 
 import (
+	"errors"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -8,34 +10,139 @@ import (
 	"github.com/avast/retry-go"
 )
 
+// Priority is the class a message is queued under. Workers always drain
+// higher priority classes first.
+type Priority int
+
+const (
+	// PriorityHigh is used for user facing transactions such as key assignments.
+	PriorityHigh Priority = iota
+	// PriorityNormal is used for keygen protocol transactions.
+	PriorityNormal
+	numPriorities
+)
+
+const (
+	retryAttempts = 6
+	retryDelay    = 250 * time.Millisecond
+	retryMaxDelay = 5 * time.Second
+)
+
+// ErrQueueFull is returned by Add when the queue for the requested priority
+// has no room left. Callers may retry later.
+var ErrQueueFull = errors.New("message queue is full")
+
 type MessageQueue struct {
-	queue          chan MessageWrapper
+	queues         []chan MessageWrapper
+	wake           chan struct{}
 	processMessage func(msg []byte) (response interface{}, err error)
+	retryable      func(err error) bool
+	pending        atomic.Int64
 }
 
 type MessageWrapper struct {
-	Response chan interface{}
+	Response chan MessageResult
 	Msg      []byte
 }
 
-func NewMessageQueue(processMessageFunc func(msg []byte) (response interface{}, err error)) *MessageQueue {
-	return &MessageQueue{
-		queue:          make(chan MessageWrapper),
+type MessageResult struct {
+	Response interface{}
+	Err      error
+}
+
+// NewMessageQueue creates a queue holding up to capacity messages per
+// priority class. Failed messages are retried with exponential backoff as
+// long as retryable reports the error as transient; a nil retryable retries
+// every error.
+func NewMessageQueue(
+	processMessageFunc func(msg []byte) (response interface{}, err error),
+	capacity int,
+	retryable func(err error) bool,
+) *MessageQueue {
+	if retryable == nil {
+		retryable = func(error) bool { return true }
+	}
+	q := &MessageQueue{
+		queues:         make([]chan MessageWrapper, numPriorities),
+		wake:           make(chan struct{}, capacity*int(numPriorities)),
 		processMessage: processMessageFunc,
+		retryable:      retryable,
+	}
+	for i := range q.queues {
+		q.queues[i] = make(chan MessageWrapper, capacity)
 	}
+	return q
 }
 
-func (q *MessageQueue) Add(bftTxBytes []byte) (res interface{}) {
-	c := make(chan interface{})
-	q.queue <- MessageWrapper{c, bftTxBytes}
-	return <-c
+// Add queues a message and blocks until it has been processed. It returns
+// ErrQueueFull right away if there is no room for the message.
+func (q *MessageQueue) Add(bftTxBytes []byte, priority Priority) (res interface{}, err error) {
+	return q.add(bftTxBytes, priority, false)
+}
+
+// AddWaiting is Add for messages that must not be dropped: if there is no
+// room for the message it waits for some instead of returning ErrQueueFull.
+func (q *MessageQueue) AddWaiting(bftTxBytes []byte, priority Priority) (res interface{}, err error) {
+	return q.add(bftTxBytes, priority, true)
+}
+
+func (q *MessageQueue) add(bftTxBytes []byte, priority Priority, wait bool) (res interface{}, err error) {
+	if priority < 0 || priority >= numPriorities {
+		priority = PriorityNormal
+	}
+	c := make(chan MessageResult, 1)
+	m := MessageWrapper{c, bftTxBytes}
+	select {
+	case q.queues[priority] <- m:
+	default:
+		if !wait {
+			log.WithField("priority", priority).Warn("msgQ:queue full, rejecting message")
+			return nil, ErrQueueFull
+		}
+		log.WithField("priority", priority).Warn("msgQ:queue full, waiting for room")
+		q.queues[priority] <- m
+	}
+	q.pending.Add(1)
+	defer q.pending.Add(-1)
+	q.wake <- struct{}{}
+	result := <-c
+	return result.Response, result.Err
+}
+
+// Len returns the number of messages waiting to be processed.
+func (q *MessageQueue) Len() int {
+	total := 0
+	for _, queue := range q.queues {
+		total += len(queue)
+	}
+	return total
+}
+
+// Pending returns the number of messages queued or being processed.
+func (q *MessageQueue) Pending() int {
+	return int(q.pending.Load())
+}
+
+// next returns the highest priority message available. Every wake signal
+// matches exactly one queued message, so a message is always available.
+func (q *MessageQueue) next() MessageWrapper {
+	<-q.wake
+	for {
+		for _, queue := range q.queues {
+			select {
+			case m := <-queue:
+				return m
+			default:
+			}
+		}
+	}
 }
 
 func (q *MessageQueue) RunMsgEngine(num int) {
 	for i := 0; i < num; i++ {
 		go func() {
 			for {
-				m := <-q.queue
+				m := q.next()
 				var res interface{}
 				var err error
 				err = retry.Do(func() error {
@@ -46,18 +153,17 @@ func (q *MessageQueue) RunMsgEngine(num int) {
 					}
 					return nil
 				},
-					// retry.RetryIf(func(err error) bool {
-					// 	log.WithError(err).Error("msgQ:retryIf")
-					// 	return true
-					// }),
-					retry.Attempts(6),
-					retry.Delay(1*time.Second),
-					retry.DelayType(retry.FixedDelay),
+					retry.RetryIf(q.retryable),
+					retry.Attempts(retryAttempts),
+					retry.Delay(retryDelay),
+					retry.MaxDelay(retryMaxDelay),
+					retry.DelayType(retry.BackOffDelay),
+					retry.LastErrorOnly(true),
 				)
 				if err != nil {
 					log.WithError(err).Errorf("could not process message in RunMsgEngine: %s", err.Error())
 				}
-				m.Response <- res
+				m.Response <- MessageResult{Response: res, Err: err}
 			}
 		}()
 	}
@@ -0,0 +1,133 @@
+package messageq
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddRejectsWhenFull(t *testing.T) {
+	block := make(chan struct{})
+	q := NewMessageQueue(func(msg []byte) (interface{}, error) {
+		<-block
+		return nil, nil
+	}, 1, nil)
+	q.RunMsgEngine(1)
+
+	var wg sync.WaitGroup
+	for _, msg := range []string{"processing", "queued"} {
+		wg.Add(1)
+		go func(msg string) {
+			defer wg.Done()
+			_, _ = q.Add([]byte(msg), PriorityNormal)
+		}(msg)
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if _, err := q.Add([]byte("rejected"), PriorityNormal); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Add() error = %v, want %v", err, ErrQueueFull)
+	}
+	if got := q.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	close(block)
+	wg.Wait()
+}
+
+func TestAddWaitingWaitsForRoom(t *testing.T) {
+	block := make(chan struct{})
+	q := NewMessageQueue(func(msg []byte) (interface{}, error) {
+		<-block
+		return string(msg), nil
+	}, 1, nil)
+	q.RunMsgEngine(1)
+
+	var wg sync.WaitGroup
+	for _, msg := range []string{"processing", "queued"} {
+		wg.Add(1)
+		go func(msg string) {
+			defer wg.Done()
+			_, _ = q.Add([]byte(msg), PriorityNormal)
+		}(msg)
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		res, err := q.AddWaiting([]byte("keygen"), PriorityNormal)
+		if err == nil && res != "keygen" {
+			err = errors.New("unexpected response")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("AddWaiting() returned %v while the queue was full", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(block)
+	if err := <-done; err != nil {
+		t.Errorf("AddWaiting() error = %v", err)
+	}
+	wg.Wait()
+}
+
+func TestHighPriorityProcessedFirst(t *testing.T) {
+	block := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	q := NewMessageQueue(func(msg []byte) (interface{}, error) {
+		if string(msg) == "first" {
+			<-block
+		}
+		mu.Lock()
+		order = append(order, string(msg))
+		mu.Unlock()
+		return nil, nil
+	}, 10, nil)
+	q.RunMsgEngine(1)
+
+	var wg sync.WaitGroup
+	add := func(msg string, priority Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = q.Add([]byte(msg), priority)
+		}()
+		time.Sleep(50 * time.Millisecond)
+	}
+	add("first", PriorityNormal)
+	add("keygen", PriorityNormal)
+	add("assignment", PriorityHigh)
+	close(block)
+	wg.Wait()
+
+	want := []string{"first", "assignment", "keygen"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("processing order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestPermanentErrorsAreNotRetried(t *testing.T) {
+	permanent := errors.New("tx already exists in cache")
+	calls := 0
+	q := NewMessageQueue(func(msg []byte) (interface{}, error) {
+		calls++
+		return nil, permanent
+	}, 1, func(err error) bool {
+		return !errors.Is(err, permanent)
+	})
+	q.RunMsgEngine(1)
+
+	if _, err := q.Add([]byte("tx"), PriorityHigh); !errors.Is(err, permanent) {
+		t.Errorf("Add() error = %v, want %v", err, permanent)
+	}
+	if calls != 1 {
+		t.Errorf("processMessage called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,142 @@
+package tendermint
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/webauthn"
+)
+
+var (
+	passkeyPrefixKey     = []byte("pc")
+	passkeyUserPrefixKey = []byte("pu")
+)
+
+func passkeyKey(appID, credentialID string) []byte {
+	return append(append([]byte(nil), passkeyPrefixKey...), strings.Join([]string{appID, credentialID}, common.Delimiter1)...)
+}
+
+func passkeyUserKey(appID, userID string) []byte {
+	return append(append([]byte(nil), passkeyUserPrefixKey...), strings.Join([]string{appID, userID}, common.Delimiter1)...)
+}
+
+func (app *ABCI) retrievePasskeyCredential(appID, credentialID string) (*common.PasskeyCredential, error) {
+	b, err := app.db.Get(passkeyKey(appID, credentialID))
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("passkey is not registered")
+	}
+	var credential common.PasskeyCredential
+	if err := bijson.Unmarshal(b, &credential); err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// userPasskeys returns the IDs of the credentials registered for a user.
+func (app *ABCI) userPasskeys(appID, userID string) ([]string, error) {
+	b, err := app.db.Get(passkeyUserKey(appID, userID))
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var ids []string
+	err = bijson.Unmarshal(b, &ids)
+	return ids, err
+}
+
+// checkPasskeyRegistration checks that a credential can be registered: it is
+// new, and if the user already has passkeys, one of them approved it with an
+// assertion over the new credential. The first passkey of a user has to be
+// registered through a primary verifier.
+func (app *ABCI) checkPasskeyRegistration(tx PasskeyRegistrationTx) error {
+	if tx.AppID == "" || tx.UserID == "" || tx.CredentialID == "" {
+		return errors.New("passkey registration is missing fields")
+	}
+	if _, err := webauthn.ParsePublicKey(tx.PublicKey, tx.Algorithm); err != nil {
+		return err
+	}
+	if _, err := app.retrievePasskeyCredential(tx.AppID, tx.CredentialID); err == nil {
+		return errors.New("passkey is already registered")
+	}
+	registered, err := app.userPasskeys(tx.AppID, tx.UserID)
+	if err != nil {
+		return err
+	}
+	if len(registered) == 0 {
+		if tx.Verifier == "" || tx.Verifier == common.PasskeyVerifier {
+			return errors.New("first passkey of a user is not registered through a primary verifier")
+		}
+		return nil
+	}
+
+	if tx.Approval == nil {
+		return errors.New("user has passkeys, registration needs the approval of one")
+	}
+	approver, err := app.retrievePasskeyCredential(tx.AppID, tx.Approval.CredentialID)
+	if err != nil || approver.UserID != tx.UserID {
+		return errors.New("approval is not by a passkey of the user")
+	}
+	if tx.RPID == "" {
+		return errors.New("approval has no relying party ID")
+	}
+	challenge := webauthn.ApprovalChallenge(tx.CredentialID, tx.PublicKey)
+	if _, err := tx.Approval.Verify(approver.PublicKey, approver.Algorithm, tx.RPID, challenge); err != nil {
+		return fmt.Errorf("invalid approval: %w", err)
+	}
+	return nil
+}
+
+func (app *ABCI) registerPasskey(tx PasskeyRegistrationTx) error {
+	registered, err := app.userPasskeys(tx.AppID, tx.UserID)
+	if err != nil {
+		return err
+	}
+	credential, err := bijson.Marshal(common.PasskeyCredential{
+		ID:        tx.CredentialID,
+		AppID:     tx.AppID,
+		UserID:    tx.UserID,
+		PublicKey: tx.PublicKey,
+		Algorithm: tx.Algorithm,
+	})
+	if err != nil {
+		return err
+	}
+	ids, err := bijson.Marshal(append(registered, tx.CredentialID))
+	if err != nil {
+		return err
+	}
+	if err := app.db.Set(passkeyKey(tx.AppID, tx.CredentialID), credential); err != nil {
+		return err
+	}
+	return app.db.Set(passkeyUserKey(tx.AppID, tx.UserID), ids)
+}
+
+// checkPasskeySignCount checks that the counter of the tx is above the one
+// recorded for the credential, or was recorded by the same assertion.
+func (app *ABCI) checkPasskeySignCount(tx PasskeySignCountTx) error {
+	credential, err := app.retrievePasskeyCredential(tx.AppID, tx.CredentialID)
+	if err != nil {
+		return err
+	}
+	return credential.CheckSignCount(tx.SignCount, tx.TokenCommitment)
+}
+
+func (app *ABCI) recordPasskeySignCount(tx PasskeySignCountTx) error {
+	credential, err := app.retrievePasskeyCredential(tx.AppID, tx.CredentialID)
+	if err != nil {
+		return err
+	}
+	credential.SignCount = tx.SignCount
+	credential.SignCountToken = tx.TokenCommitment
+	b, err := bijson.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	return app.db.Set(passkeyKey(tx.AppID, tx.CredentialID), b)
+}
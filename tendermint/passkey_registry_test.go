@@ -0,0 +1,81 @@
+package tendermint
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/webauthn"
+)
+
+func TestPasskeyRegistry(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	app := &ABCI{db: db, state: &State{}}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliver := func(tx PasskeyRegistrationTx) error {
+		if err := app.checkPasskeyRegistration(tx); err != nil {
+			return err
+		}
+		return app.registerPasskey(tx)
+	}
+	tx := PasskeyRegistrationTx{AppID: "app", UserID: "alice", CredentialID: "cred-1", PublicKey: spki, Algorithm: webauthn.AlgES256, RPID: "example.com"}
+
+	if err := deliver(tx); err == nil {
+		t.Error("first passkey was registered without a primary verifier")
+	}
+	withPasskey := tx
+	withPasskey.Verifier = common.PasskeyVerifier
+	if err := deliver(withPasskey); err == nil {
+		t.Error("first passkey was registered through the passkey verifier")
+	}
+	tx.Verifier = "google"
+	if err := deliver(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(tx); err == nil {
+		t.Error("passkey was registered twice")
+	}
+
+	// Further passkeys need the approval of a registered one.
+	second := tx
+	second.CredentialID = "cred-2"
+	if err := deliver(second); err == nil {
+		t.Error("second passkey was registered without an approval")
+	}
+
+	record := func(tx PasskeySignCountTx) error {
+		if err := app.checkPasskeySignCount(tx); err != nil {
+			return err
+		}
+		return app.recordPasskeySignCount(tx)
+	}
+	if err := record(PasskeySignCountTx{AppID: "app", CredentialID: "cred-1", SignCount: 5, TokenCommitment: "c1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := record(PasskeySignCountTx{AppID: "app", CredentialID: "cred-1", SignCount: 5, TokenCommitment: "c1"}); err != nil {
+		t.Errorf("counter of the same assertion from another node was refused: %v", err)
+	}
+	if err := record(PasskeySignCountTx{AppID: "app", CredentialID: "cred-1", SignCount: 5, TokenCommitment: "c2"}); err == nil {
+		t.Error("counter that did not increase was recorded")
+	}
+	if credential, err := app.retrievePasskeyCredential("app", "cred-1"); err != nil || credential.SignCount != 5 {
+		t.Errorf("credential = %+v, %v", credential, err)
+	}
+}
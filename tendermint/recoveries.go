@@ -0,0 +1,96 @@
+package tendermint
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+var recoveryPrefixKey = []byte("rr")
+
+func recoveryKey(keyIndex big.Int, curve common.CurveName) []byte {
+	return append(append([]byte(nil), recoveryPrefixKey...), strings.Join([]string{string(curve), keyIndex.Text(16)}, common.Delimiter1)...)
+}
+
+// retrieveRecovery returns the latest recovery of a key, the zero value if
+// the key has none.
+func (app *ABCI) retrieveRecovery(keyIndex big.Int, curve common.CurveName) (common.Recovery, error) {
+	var recovery common.Recovery
+	b, err := app.db.Get(recoveryKey(keyIndex, curve))
+	if err != nil || b == nil {
+		return recovery, err
+	}
+	err = bijson.Unmarshal(b, &recovery)
+	return recovery, err
+}
+
+// checkRecovery checks that the app of the key allows recovery, that a
+// request is made through a backup verifier and that a cancellation is made
+// through a primary verifier. Every node broadcasts the request it served,
+// so requests of an ongoing recovery and repeated cancellations are accepted
+// and change nothing.
+func (app *ABCI) checkRecovery(tx RecoveryTx) (*common.RecoveryPolicy, error) {
+	policy, err := app.retrieveAppPolicy(tx.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Recovery == nil {
+		return nil, errors.New("app does not allow recovery")
+	}
+	if _, err := app.retrieveKeyMapping(tx.KeyIndex, tx.Curve); err != nil {
+		return nil, errors.New("key is not assigned")
+	}
+	if !tx.Cancel {
+		if !policy.Recovery.IsBackup(tx.Verifier) {
+			return nil, errors.New("recovery is not requested through a backup verifier")
+		}
+		return policy.Recovery, nil
+	}
+	if policy.Recovery.IsBackup(tx.Verifier) {
+		return nil, errors.New("recovery is not cancelled through a primary verifier")
+	}
+	recovery, err := app.retrieveRecovery(tx.KeyIndex, tx.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if state := recovery.State(app.state.BlockTime); state == common.RecoveryNone || state == common.RecoveryExpired {
+		return nil, errors.New("key has no recovery")
+	}
+	return policy.Recovery, nil
+}
+
+func (app *ABCI) applyRecovery(tx RecoveryTx, policy *common.RecoveryPolicy) error {
+	recovery, err := app.retrieveRecovery(tx.KeyIndex, tx.Curve)
+	if err != nil {
+		return err
+	}
+	state := recovery.State(app.state.BlockTime)
+	switch {
+	case tx.Cancel && state == common.RecoveryCancelled:
+		return nil
+	case tx.Cancel:
+		recovery.Cancelled = true
+		recovery.CancelledBy = tx.Verifier
+	case recovery.Started(app.state.BlockTime):
+		return nil
+	default:
+		recovery = common.Recovery{
+			AppID:        tx.AppID,
+			KeyIndex:     tx.KeyIndex.Text(16),
+			Curve:        tx.Curve,
+			Verifier:     tx.Verifier,
+			RequestedAt:  app.state.BlockTime,
+			ReleasableAt: app.state.BlockTime.Add(time.Duration(policy.Delay) * time.Second),
+		}
+	}
+	b, err := bijson.Marshal(recovery)
+	if err != nil {
+		return err
+	}
+	return app.db.Set(recoveryKey(tx.KeyIndex, tx.Curve), b)
+}
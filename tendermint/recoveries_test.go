@@ -0,0 +1,144 @@
+package tendermint
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+func TestRecoveries(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	app := &ABCI{db: db, state: &State{BlockTime: start}}
+	index := *big.NewInt(7)
+
+	deliver := func(tx RecoveryTx) error {
+		policy, err := app.checkRecovery(tx)
+		if err != nil {
+			return err
+		}
+		return app.applyRecovery(tx, policy)
+	}
+	request := RecoveryTx{AppID: "app", KeyIndex: index, Curve: common.SECP256K1, Verifier: "email"}
+	cancel := RecoveryTx{Cancel: true, AppID: "app", KeyIndex: index, Curve: common.SECP256K1, Verifier: "google"}
+
+	if err := app.storeAppPolicy(common.AppPolicy{AppID: "app", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(request); err == nil {
+		t.Error("recovery was requested for an app without recovery")
+	}
+	err = app.storeAppPolicy(common.AppPolicy{AppID: "app", Version: 2, Recovery: &common.RecoveryPolicy{
+		BackupVerifiers: []string{"email"},
+		Delay:           60 * 60,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(request); err == nil {
+		t.Error("recovery was requested for an unassigned key")
+	}
+	if err := app.storeKeyMapping(index, common.SECP256K1, common.KeyAssignmentPublic{Index: index}); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(cancel); err == nil {
+		t.Error("key without recovery was cancelled")
+	}
+	if err := deliver(RecoveryTx{AppID: "app", KeyIndex: index, Curve: common.SECP256K1, Verifier: "google"}); err == nil {
+		t.Error("recovery was requested through a primary verifier")
+	}
+
+	if err := deliver(request); err != nil {
+		t.Fatal(err)
+	}
+	// Requests of every node for the ongoing recovery do not restart it.
+	app.state.BlockTime = start.Add(time.Minute)
+	if err := deliver(request); err != nil {
+		t.Fatal(err)
+	}
+	recovery, err := app.retrieveRecovery(index, common.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := recovery.State(start.Add(59 * time.Minute)); state != common.RecoveryPending {
+		t.Errorf("state before the delay = %q", state)
+	}
+	if state := recovery.State(start.Add(time.Hour)); state != common.RecoveryReleasable {
+		t.Errorf("state after the delay = %q", state)
+	}
+	if state := recovery.State(start.Add(time.Hour + common.RecoveryReleaseWindow)); state != common.RecoveryExpired {
+		t.Errorf("state after the release window = %q", state)
+	}
+
+	if err := deliver(RecoveryTx{Cancel: true, AppID: "app", KeyIndex: index, Curve: common.SECP256K1, Verifier: "email"}); err == nil {
+		t.Error("recovery was cancelled through a backup verifier")
+	}
+	if err := deliver(cancel); err != nil {
+		t.Fatal(err)
+	}
+	if recovery, _ := app.retrieveRecovery(index, common.SECP256K1); recovery.State(start.Add(2*time.Hour)) != common.RecoveryCancelled {
+		t.Errorf("cancelled recovery = %+v", recovery)
+	}
+
+	app.state.BlockTime = start.Add(3 * time.Hour)
+	if err := deliver(request); err != nil {
+		t.Fatal(err)
+	}
+	if recovery, _ := app.retrieveRecovery(index, common.SECP256K1); recovery.State(start.Add(3*time.Hour)) != common.RecoveryPending {
+		t.Errorf("recovery requested after a cancellation = %+v", recovery)
+	}
+
+	// A recovery that completed does not release the share for good: once
+	// its window passed, a second recovery waits out the delay again.
+	released := start.Add(4 * time.Hour)
+	app.state.BlockTime = released.Add(common.RecoveryReleaseWindow)
+	if err := deliver(cancel); err == nil {
+		t.Error("expired recovery was cancelled")
+	}
+	if err := deliver(request); err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.retrieveRecovery(index, common.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := second.State(app.state.BlockTime); state != common.RecoveryPending {
+		t.Errorf("second recovery = %q", state)
+	}
+	if !second.ReleasableAt.Equal(app.state.BlockTime.Add(time.Hour)) {
+		t.Errorf("second recovery is releasable at %s", second.ReleasableAt)
+	}
+}
+
+func TestLastBlockTimeSurvivesRestart(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	app := &ABCI{db: db, state: &State{}, info: &AppInfo{}}
+	if !app.lastBlockTime().IsZero() {
+		t.Errorf("block time before the first block = %s", app.lastBlockTime())
+	}
+
+	blockTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	app.state.BlockTime = blockTime
+	app.Commit()
+	if !app.lastBlockTime().Equal(blockTime) {
+		t.Errorf("block time after commit = %s", app.lastBlockTime())
+	}
+
+	restarted := &ABCI{db: db}
+	restarted.LoadState()
+	if !restarted.lastBlockTime().Equal(blockTime) {
+		t.Errorf("block time after restart = %s", restarted.lastBlockTime())
+	}
+}
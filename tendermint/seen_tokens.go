@@ -0,0 +1,92 @@
+package tendermint
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	tmdb "github.com/tendermint/tm-db"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+const (
+	// seenTokenTTL is how long a token use is remembered. Verifiers reject
+	// tokens long before that, so a forgotten token cannot be used again.
+	seenTokenTTL = 24 * time.Hour
+	// seenTokenPruneInterval is the number of blocks between removals of
+	// expired token uses.
+	seenTokenPruneInterval = 100
+)
+
+var seenTokenPrefixKey = []byte("ts")
+
+func seenTokenKey(tokenCommitment string) []byte {
+	return append(append([]byte(nil), seenTokenPrefixKey...), tokenCommitment...)
+}
+
+// retrieveSeenToken returns the use of a token, the zero value if the token
+// was not used.
+func (app *ABCI) retrieveSeenToken(tokenCommitment string) (common.SeenToken, error) {
+	var seen common.SeenToken
+	b, err := app.db.Get(seenTokenKey(tokenCommitment))
+	if err != nil || b == nil {
+		return seen, err
+	}
+	err = bijson.Unmarshal(b, &seen)
+	return seen, err
+}
+
+// checkTokenSeen accepts a use of a new token, or of a token in the flow it
+// was first used in.
+func (app *ABCI) checkTokenSeen(tx TokenSeenTx) error {
+	if tx.TokenCommitment == "" {
+		return errors.New("token commitment is empty")
+	}
+	seen, err := app.retrieveSeenToken(tx.TokenCommitment)
+	if err != nil {
+		return err
+	}
+	if seen.Seen() && !seen.SameFlow(tx.TempPubKey) {
+		return errors.New("token was already used in another flow")
+	}
+	return nil
+}
+
+func (app *ABCI) recordTokenSeen(tx TokenSeenTx) error {
+	seen, err := app.retrieveSeenToken(tx.TokenCommitment)
+	if err != nil || seen.Seen() {
+		return err
+	}
+	b, err := bijson.Marshal(common.SeenToken{
+		TempPubKey: tx.TempPubKey,
+		ExpiresAt:  app.state.BlockTime.Add(seenTokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+	return app.db.Set(seenTokenKey(tx.TokenCommitment), b)
+}
+
+// pruneSeenTokens removes the token uses that expired by the block time.
+func (app *ABCI) pruneSeenTokens() {
+	it, err := tmdb.IteratePrefix(app.db, seenTokenPrefixKey)
+	if err != nil {
+		log.WithError(err).Error("could not iterate seen tokens")
+		return
+	}
+	var expired [][]byte
+	for ; it.Valid(); it.Next() {
+		var seen common.SeenToken
+		if err := bijson.Unmarshal(it.Value(), &seen); err != nil || seen.ExpiresAt.Before(app.state.BlockTime) {
+			expired = append(expired, append([]byte(nil), it.Key()...))
+		}
+	}
+	it.Close()
+	for _, key := range expired {
+		if err := app.db.Delete(key); err != nil {
+			log.WithError(err).Error("could not remove seen token")
+		}
+	}
+}
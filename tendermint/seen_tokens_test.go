@@ -0,0 +1,56 @@
+package tendermint
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+func TestSeenTokens(t *testing.T) {
+	db, err := tmdb.NewGoLevelDB("tmstate", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	app := &ABCI{db: db, state: &State{BlockTime: start}}
+	flow := common.Point{X: *big.NewInt(1), Y: *big.NewInt(2)}
+	other := common.Point{X: *big.NewInt(3), Y: *big.NewInt(4)}
+
+	deliver := func(tx TokenSeenTx) error {
+		if err := app.checkTokenSeen(tx); err != nil {
+			return err
+		}
+		return app.recordTokenSeen(tx)
+	}
+	if err := deliver(TokenSeenTx{TokenCommitment: "c1", TempPubKey: flow}); err != nil {
+		t.Fatal(err)
+	}
+	// Every node records the use of the token in the same flow.
+	if err := deliver(TokenSeenTx{TokenCommitment: "c1", TempPubKey: flow}); err != nil {
+		t.Errorf("use in the same flow was refused: %v", err)
+	}
+	if err := deliver(TokenSeenTx{TokenCommitment: "c1", TempPubKey: other}); err == nil {
+		t.Error("use in another flow was accepted")
+	}
+	if seen, err := app.retrieveSeenToken("c2"); err != nil || seen.Seen() {
+		t.Errorf("unused token = %+v, %v", seen, err)
+	}
+
+	app.state.BlockTime = start.Add(seenTokenTTL / 2)
+	if err := deliver(TokenSeenTx{TokenCommitment: "c2", TempPubKey: other}); err != nil {
+		t.Fatal(err)
+	}
+	app.state.BlockTime = start.Add(seenTokenTTL + time.Minute)
+	app.pruneSeenTokens()
+	if seen, _ := app.retrieveSeenToken("c1"); seen.Seen() {
+		t.Error("expired token use was kept")
+	}
+	if seen, _ := app.retrieveSeenToken("c2"); !seen.SameFlow(other) {
+		t.Error("token use was removed before it expired")
+	}
+}
@@ -58,7 +58,7 @@ func (t *CustomProvider) Verify(rawPayload *bijson.RawMessage, params *common.Ve
 	}
 
 	// Fetch creds from params
-	u, err := url.Parse(config.GlobalConfig.GatewayURL)
+	u, err := url.Parse(config.Current().GatewayURL)
 	if err != nil {
 		return false, "", err
 	}
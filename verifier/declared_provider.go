@@ -0,0 +1,188 @@
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+// endpointTimeout bounds the calls to userinfo and introspection endpoints.
+const endpointTimeout = 10 * time.Second
+
+// NewDeclaredProvider builds the provider of a verifier declared in the
+// verifiers file.
+func NewDeclaredProvider(def config.VerifierDefinition) (Provider, error) {
+	switch def.Type {
+	case config.VerifierJWT:
+		return NewOIDCProvider(OIDCConfig{
+			ID:             def.ID,
+			Issuer:         def.Issuer,
+			JWKSURL:        def.JWKSURL,
+			Audience:       def.Audience,
+			UserIDClaim:    def.UserIDPath,
+			RequiredClaims: def.RequiredClaims,
+			Leeway:         time.Duration(def.LeewaySeconds) * time.Second,
+			MaxAge:         time.Duration(def.MaxAgeSeconds) * time.Second,
+		}), nil
+	case config.VerifierUserinfo, config.VerifierIntrospection:
+		return &EndpointProvider{
+			def:    def,
+			client: req.C().SetTimeout(endpointTimeout),
+		}, nil
+	}
+	return nil, fmt.Errorf("verifier %s has unknown type %q", def.ID, def.Type)
+}
+
+// EndpointProvider verifies opaque tokens by asking the service that issued
+// them, at its userinfo or token introspection endpoint.
+type EndpointProvider struct {
+	def    config.VerifierDefinition
+	client *req.Client
+}
+
+type EndpointVerifierParams struct {
+	IDToken string `json:"id_token"`
+	UserID  string `json:"user_id"`
+}
+
+func (e *EndpointProvider) ID() string {
+	return e.def.ID
+}
+
+func (e *EndpointProvider) CleanToken(token string) string {
+	return strings.Trim(token, " ")
+}
+
+func (e *EndpointProvider) Verify(rawPayload *bijson.RawMessage, params *common.VerifierParams) (bool, string, error) {
+	var p EndpointVerifierParams
+	if err := bijson.Unmarshal(*rawPayload, &p); err != nil {
+		return false, "", err
+	}
+	p.IDToken = e.CleanToken(p.IDToken)
+	if p.UserID == "" || p.IDToken == "" {
+		return false, "", errors.New("invalid payload parameters")
+	}
+
+	url := strings.NewReplacer("{client_id}", params.ClientID, "{domain}", params.Domain).Replace(e.def.URL)
+	var body map[string]interface{}
+	r := e.client.R().SetSuccessResult(&body)
+	var res *req.Response
+	var err error
+	if e.def.Type == config.VerifierIntrospection {
+		res, err = r.
+			SetBasicAuth(e.def.ClientID, os.Getenv(e.def.ClientSecretEnv)).
+			SetFormData(map[string]string{"token": p.IDToken}).
+			Post(url)
+	} else {
+		res, err = r.SetBearerAuthToken(p.IDToken).Get(url)
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if res.IsErrorState() {
+		return false, "", fmt.Errorf("%s: endpoint returned %s", e.def.ID, res.Status)
+	}
+	if e.def.Type == config.VerifierIntrospection && body["active"] != true {
+		return false, "", fmt.Errorf("%s: token is not active", e.def.ID)
+	}
+
+	if err := checkClaims(body, e.def.RequiredClaims); err != nil {
+		return false, "", err
+	}
+	if err := e.checkClientID(body, params.ClientID); err != nil {
+		return false, "", err
+	}
+	userID, err := claimString(body, e.def.UserIDPath)
+	if err != nil {
+		return false, "", err
+	}
+	if userID != p.UserID {
+		return false, "", errors.New("user id does not match the token")
+	}
+	return true, userID, nil
+}
+
+// checkClientID checks that the token was issued to the app, so that a token
+// obtained through another app of the same service cannot be used to log in
+// to this one.
+func (e *EndpointProvider) checkClientID(body map[string]interface{}, clientID string) error {
+	if clientID == "" {
+		return fmt.Errorf("%s: app has no client id", e.def.ID)
+	}
+	paths := []string{"client_id", "aud"}
+	if e.def.ClientIDPath != "" {
+		paths = []string{e.def.ClientIDPath}
+	}
+	for _, path := range paths {
+		value, _ := lookupPath(body, path)
+		switch value := value.(type) {
+		case string:
+			if value == clientID {
+				return nil
+			}
+		case []interface{}:
+			for _, v := range value {
+				if v == clientID {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%s: token was not issued to the client id", e.def.ID)
+}
+
+// lookupPath returns the value at a dotted path into decoded JSON.
+func lookupPath(claims map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func claimString(claims map[string]interface{}, path string) (string, error) {
+	value, _ := lookupPath(claims, path)
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("%s missing from token", path)
+	}
+	return s, nil
+}
+
+// checkClaims checks that claims hold the required values. Values are
+// compared as JSON, so that numbers decoded differently still match.
+func checkClaims(claims map[string]interface{}, required map[string]interface{}) error {
+	for path, want := range required {
+		got, ok := lookupPath(claims, path)
+		if !ok || !jsonEqual(got, want) {
+			return fmt.Errorf("claim %s not satisfied", path)
+		}
+	}
+	return nil
+}
+
+func jsonEqual(a, b interface{}) bool {
+	var x, y interface{}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil || json.Unmarshal(ja, &x) != nil || json.Unmarshal(jb, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
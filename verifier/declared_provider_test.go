@@ -0,0 +1,116 @@
+package verifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
+)
+
+func TestDeclaredVerifiers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/userinfo":
+			client := map[string]string{"Bearer good": "client", "Bearer other-app": "other"}[r.Header.Get("Authorization")]
+			if client == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "user-1", "verified": true, "level": 2, "client": client},
+			})
+		case "/introspect":
+			user, secret, _ := r.BasicAuth()
+			_ = r.ParseForm()
+			token := r.PostForm.Get("token")
+			active := user == "node" && secret == "s3cret" && (token == "good" || token == "other-app")
+			res := map[string]interface{}{"active": active, "sub": "user-1", "aud": []string{"client"}}
+			if token == "other-app" {
+				res = map[string]interface{}{"active": active, "sub": "user-1", "client_id": "other"}
+			}
+			_ = json.NewEncoder(w).Encode(res)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_INTROSPECTION_SECRET", "s3cret")
+
+	path := filepath.Join(t.TempDir(), "verifiers.yaml")
+	err := os.WriteFile(path, []byte(`
+verifiers:
+  - id: acme
+    type: userinfo
+    url: `+server.URL+`/userinfo
+    userIdPath: data.id
+    clientIdPath: data.client
+    requiredClaims:
+      data.verified: true
+      data.level: 2
+  - id: acme-introspect
+    type: introspection
+    url: `+server.URL+`/introspect
+    clientId: node
+    clientSecretEnv: TEST_INTROSPECTION_SECRET
+    userIdPath: sub
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := config.GlobalConfig
+	config.GlobalConfig = config.GetDefaultConfig()
+	config.GlobalConfig.VerifiersFile = path
+	defer func() { config.GlobalConfig = previous }()
+
+	v := &VerifierService{providerMap: NewProviderMap([]Provider{NewGoogleProvider()})}
+	if err := v.loadDeclared(path); err != nil {
+		t.Fatal(err)
+	}
+	verify := func(provider, token, userID string) error {
+		p, err := v.providerMap.Lookup(provider)
+		if err != nil {
+			return err
+		}
+		payload, _ := bijson.Marshal(EndpointVerifierParams{IDToken: token, UserID: userID})
+		raw := bijson.RawMessage(payload)
+		_, _, err = p.Verify(&raw, &common.VerifierParams{ClientID: "client"})
+		return err
+	}
+	for _, provider := range []string{"acme", "acme-introspect"} {
+		if err := verify(provider, "good", "user-1"); err != nil {
+			t.Errorf("%s: %v", provider, err)
+		}
+		if err := verify(provider, "bad", "user-1"); err == nil {
+			t.Errorf("%s: bad token was accepted", provider)
+		}
+		if err := verify(provider, "good", "user-2"); err == nil {
+			t.Errorf("%s: token of another user was accepted", provider)
+		}
+		if err := verify(provider, "other-app", "user-1"); err == nil {
+			t.Errorf("%s: token issued to another app was accepted", provider)
+		}
+	}
+
+	// Reloading drops the verifiers no longer declared.
+	if err := os.WriteFile(path, []byte("verifiers: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v.reloadDeclared(config.GlobalConfig, config.GlobalConfig)
+	if _, err := v.providerMap.Lookup("acme"); err == nil {
+		t.Error("removed verifier is still there")
+	}
+
+	// Built in verifiers cannot be replaced.
+	err = v.providerMap.SetDeclared([]Provider{&EndpointProvider{def: config.VerifierDefinition{ID: "google"}}})
+	if err == nil {
+		t.Error("built in verifier was replaced")
+	}
+	if _, ok := v.providerMap.Providers["google"].(*OIDCProvider); !ok {
+		t.Error("google verifier was changed")
+	}
+}
@@ -34,7 +34,7 @@ type GlobalKeyVerifier struct {
 
 func NewGlobalKeyVerifier(vs *VerifierService) *GlobalKeyVerifier {
 	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM([]byte(config.GlobalConfig.GlobalKeyCertPool)) {
+	if !certPool.AppendCertsFromPEM([]byte(config.Current().GlobalKeyCertPool)) {
 		panic(errors.New("invalid certificate pool data"))
 	}
 	return &GlobalKeyVerifier{
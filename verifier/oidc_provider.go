@@ -0,0 +1,308 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	log "github.com/sirupsen/logrus"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+const (
+	// discoveryTTL is how long a discovery document is used before it is
+	// fetched again.
+	discoveryTTL = 24 * time.Hour
+	// minKeyRefresh limits how often the keys of an issuer are refetched,
+	// on schedule or because a token names an unknown key.
+	minKeyRefresh = time.Minute
+)
+
+// OIDCConfig describes an OpenID Connect issuer.
+type OIDCConfig struct {
+	// ID is the provider name apps register verifiers under.
+	ID string
+	// Issuer is the issuer URL. "{client_id}" and "{domain}" are replaced
+	// with the verifier params of the app, for issuers per app.
+	Issuer string
+	// IssuerAliases are other iss values tokens of the issuer may carry.
+	IssuerAliases []string
+	// JWKSURL is the key set of the issuer, found by discovery if empty.
+	JWKSURL string
+	// Audience is the aud tokens have to carry, the client ID of the app
+	// if empty and no ClientIDClaim is set.
+	Audience string
+	// ClientIDClaim is a claim other than aud that has to carry the client
+	// ID of the app.
+	ClientIDClaim string
+	// UserIDClaim holds the user ID, "sub" if empty. Claims nested in
+	// objects are named by dotted paths.
+	UserIDClaim string
+	// RequiredClaims are claims that must have these values.
+	RequiredClaims map[string]interface{}
+	// RequireVerifiedEmail rejects tokens without a true email_verified.
+	RequireVerifiedEmail bool
+	// Leeway is the clock skew allowed on exp, nbf and iat.
+	Leeway time.Duration
+	// MaxAge rejects tokens issued longer ago, if set.
+	MaxAge time.Duration
+}
+
+// OIDCProvider verifies ID tokens locally, with the keys the issuer
+// publishes through OIDC discovery.
+type OIDCProvider struct {
+	config OIDCConfig
+	keys   *jwk.Cache
+
+	mu         sync.Mutex
+	discovered map[string]discovery
+	refreshed  map[string]time.Time
+}
+
+type discovery struct {
+	jwksURI   string
+	fetchedAt time.Time
+}
+
+type OIDCVerifierParams struct {
+	IDToken string `json:"id_token"`
+	UserID  string `json:"user_id"`
+	// Nonce, when given, has to match the nonce claim of the token.
+	Nonce string `json:"nonce"`
+}
+
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if config.UserIDClaim == "" {
+		config.UserIDClaim = "sub"
+	}
+	return &OIDCProvider{
+		config:     config,
+		keys:       jwk.NewCache(context.Background()),
+		discovered: make(map[string]discovery),
+		refreshed:  make(map[string]time.Time),
+	}
+}
+
+// NewGoogleProvider verifies Google ID tokens, identifying users by email.
+// The client ID of the app is checked against azp, as tokens requested by
+// mobile apps carry the client ID of their server in aud.
+func NewGoogleProvider() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		ID:                   "google",
+		Issuer:               "https://accounts.google.com",
+		IssuerAliases:        []string{"accounts.google.com"},
+		ClientIDClaim:        "azp",
+		UserIDClaim:          "email",
+		RequireVerifiedEmail: true,
+		Leeway:               30 * time.Second,
+		MaxAge:               600 * time.Second,
+	})
+}
+
+// NewFirebaseProvider verifies Firebase ID tokens of the project set as the
+// client ID of the app.
+func NewFirebaseProvider() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		ID:     "firebase",
+		Issuer: "https://securetoken.google.com/{client_id}",
+		Leeway: 120 * time.Second,
+		MaxAge: 120 * time.Second,
+	})
+}
+
+func (o *OIDCProvider) ID() string {
+	return o.config.ID
+}
+
+func (o *OIDCProvider) CleanToken(token string) string {
+	return strings.Trim(token, " ")
+}
+
+func (o *OIDCProvider) issuer(params *common.VerifierParams) string {
+	return strings.NewReplacer("{client_id}", params.ClientID, "{domain}", params.Domain).Replace(o.config.Issuer)
+}
+
+func (o *OIDCProvider) Verify(rawPayload *bijson.RawMessage, params *common.VerifierParams) (bool, string, error) {
+	var p OIDCVerifierParams
+	if err := bijson.Unmarshal(*rawPayload, &p); err != nil {
+		return false, "", err
+	}
+	p.IDToken = o.CleanToken(p.IDToken)
+	if p.IDToken == "" {
+		return false, "", errors.New("invalid payload parameters")
+	}
+
+	ctx := context.Background()
+	issuer := o.issuer(params)
+	audience := o.config.Audience
+	if audience == "" && o.config.ClientIDClaim == "" {
+		audience = params.ClientID
+	}
+	tok, err := o.parse(ctx, issuer, audience, p.IDToken)
+	if err != nil {
+		return false, "", fmt.Errorf("%s: %w", o.config.ID, err)
+	}
+	claims, err := tok.AsMap(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	if o.config.ClientIDClaim != "" {
+		if clientID, _ := claimString(claims, o.config.ClientIDClaim); clientID != params.ClientID {
+			return false, "", fmt.Errorf("%s does not match the client id", o.config.ClientIDClaim)
+		}
+	}
+	if o.config.MaxAge > 0 && time.Since(tok.IssuedAt()) > o.config.MaxAge+o.config.Leeway {
+		return false, "", errors.New("token was issued too long ago")
+	}
+	if p.Nonce != "" {
+		nonce, _ := tok.Get("nonce")
+		if nonce != p.Nonce {
+			return false, "", errors.New("nonce mismatch")
+		}
+	}
+	if o.config.RequireVerifiedEmail {
+		verified, _ := tok.Get("email_verified")
+		if verified != true && verified != "true" {
+			return false, "", ErrorIDNotVerified
+		}
+	}
+	if err := checkClaims(claims, o.config.RequiredClaims); err != nil {
+		return false, "", err
+	}
+	userID, err := claimString(claims, o.config.UserIDClaim)
+	if err != nil {
+		return false, "", err
+	}
+	if p.UserID != "" && p.UserID != userID {
+		return false, "", errors.New("user id does not match the token")
+	}
+	return true, userID, nil
+}
+
+// parse checks the signature and the registered claims of a token. If the
+// token is signed by a key not in the cached set, the keys are fetched again
+// in case the issuer rotated them. Keys published without an alg are used
+// with the algorithms their type allows.
+func (o *OIDCProvider) parse(ctx context.Context, issuer, audience, token string) (jwt.Token, error) {
+	jwksURI, err := o.jwksURI(issuer)
+	if err != nil {
+		return nil, err
+	}
+	set, err := o.keys.Get(ctx, jwksURI)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch keys: %w", err)
+	}
+	options := []jwt.ParseOption{
+		jwt.WithValidate(true),
+		jwt.WithAcceptableSkew(o.config.Leeway),
+		jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, tok jwt.Token) jwt.ValidationError {
+			if !o.acceptsIssuer(issuer, tok.Issuer()) {
+				return jwt.ErrInvalidIssuer()
+			}
+			return nil
+		})),
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	tok, err := jwt.Parse([]byte(token), append(options, jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)))...)
+	if err == nil || hasKey(set, token) || !o.mayRefresh(jwksURI) {
+		return tok, err
+	}
+	if set, err = o.keys.Refresh(ctx, jwksURI); err != nil {
+		return nil, fmt.Errorf("could not refresh keys: %w", err)
+	}
+	log.WithField("issuer", issuer).Info("OIDCProvider: refreshed signing keys")
+	return jwt.Parse([]byte(token), append(options, jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)))...)
+}
+
+func (o *OIDCProvider) acceptsIssuer(issuer, iss string) bool {
+	if iss == issuer {
+		return true
+	}
+	for _, alias := range o.config.IssuerAliases {
+		if iss == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKey reports whether set holds the key a token names.
+func hasKey(set jwk.Set, token string) bool {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil || len(msg.Signatures()) == 0 {
+		return true
+	}
+	_, ok := set.LookupKeyID(msg.Signatures()[0].ProtectedHeaders().KeyID())
+	return ok
+}
+
+func (o *OIDCProvider) mayRefresh(jwksURI string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if time.Since(o.refreshed[jwksURI]) < minKeyRefresh {
+		return false
+	}
+	o.refreshed[jwksURI] = time.Now()
+	return true
+}
+
+// jwksURI returns the key set URL of an issuer, from its discovery document
+// unless it is configured.
+func (o *OIDCProvider) jwksURI(issuer string) (string, error) {
+	if o.config.JWKSURL != "" {
+		return o.config.JWKSURL, o.register(o.config.JWKSURL)
+	}
+	o.mu.Lock()
+	d, ok := o.discovered[issuer]
+	o.mu.Unlock()
+	if ok && time.Since(d.fetchedAt) < discoveryTTL {
+		return d.jwksURI, nil
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	res, err := req.R().
+		SetSuccessResult(&doc).
+		Get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", fmt.Errorf("discovery failed: %w", err)
+	}
+	if res.IsErrorState() {
+		return "", fmt.Errorf("discovery failed: %s", res.Status)
+	}
+	if doc.Issuer != issuer || doc.JWKSURI == "" {
+		return "", errors.New("invalid discovery document")
+	}
+
+	if err := o.register(doc.JWKSURI); err != nil {
+		return "", err
+	}
+	o.mu.Lock()
+	o.discovered[issuer] = discovery{jwksURI: doc.JWKSURI, fetchedAt: time.Now()}
+	o.mu.Unlock()
+	return doc.JWKSURI, nil
+}
+
+// register adds a key set to the cache, which then keeps it fresh.
+func (o *OIDCProvider) register(jwksURI string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.keys.IsRegistered(jwksURI) {
+		return nil
+	}
+	return o.keys.Register(jwksURI, jwk.WithMinRefreshInterval(minKeyRefresh))
+}
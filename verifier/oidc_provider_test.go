@@ -0,0 +1,146 @@
+package verifier
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+// testIssuer is an OIDC issuer whose signing key can be rotated.
+type testIssuer struct {
+	*httptest.Server
+	mu  sync.Mutex
+	key jwk.Key
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	issuer := &testIssuer{}
+	issuer.rotate(t, "key-1")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL,
+			"jwks_uri": issuer.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		public, _ := issuer.key.PublicKey()
+		set := jwk.NewSet()
+		_ = set.AddKey(public)
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testIssuer) rotate(t *testing.T, kid string) {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := jwk.FromRaw(raw)
+	_ = key.Set(jwk.KeyIDKey, kid)
+	i.mu.Lock()
+	i.key = key
+	i.mu.Unlock()
+}
+
+func (i *testIssuer) token(t *testing.T, edit func(jwt.Token)) string {
+	tok := jwt.New()
+	_ = tok.Set(jwt.IssuerKey, i.URL)
+	_ = tok.Set(jwt.AudienceKey, "client")
+	_ = tok.Set(jwt.SubjectKey, "user-1")
+	_ = tok.Set(jwt.IssuedAtKey, time.Now())
+	_ = tok.Set(jwt.ExpirationKey, time.Now().Add(time.Hour))
+	_ = tok.Set("nonce", "n-1")
+	if edit != nil {
+		edit(tok)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, i.key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+func TestOIDCProviderVerify(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDCProvider(OIDCConfig{ID: "test", Issuer: "{domain}", Leeway: time.Second, MaxAge: time.Minute})
+	params := &common.VerifierParams{ClientID: "client", Domain: issuer.URL}
+	verify := func(token, userID, nonce string) (string, error) {
+		payload, _ := bijson.Marshal(OIDCVerifierParams{IDToken: token, UserID: userID, Nonce: nonce})
+		raw := bijson.RawMessage(payload)
+		_, id, err := provider.Verify(&raw, params)
+		return id, err
+	}
+
+	if id, err := verify(issuer.token(t, nil), "user-1", "n-1"); err != nil || id != "user-1" {
+		t.Fatalf("Verify() = %q, %v", id, err)
+	}
+	rejected := map[string]string{
+		"audience":   issuer.token(t, func(tok jwt.Token) { _ = tok.Set(jwt.AudienceKey, "other") }),
+		"issuer":     issuer.token(t, func(tok jwt.Token) { _ = tok.Set(jwt.IssuerKey, "https://other") }),
+		"expired":    issuer.token(t, func(tok jwt.Token) { _ = tok.Set(jwt.ExpirationKey, time.Now().Add(-time.Minute)) }),
+		"old":        issuer.token(t, func(tok jwt.Token) { _ = tok.Set(jwt.IssuedAtKey, time.Now().Add(-time.Hour)) }),
+		"nonce":      issuer.token(t, func(tok jwt.Token) { _ = tok.Set("nonce", "n-2") }),
+		"user":       issuer.token(t, func(tok jwt.Token) { _ = tok.Set(jwt.SubjectKey, "user-2") }),
+		"signature":  issuer.token(t, nil)[:10] + "x" + issuer.token(t, nil)[11:],
+		"empty user": issuer.token(t, func(tok jwt.Token) { _ = tok.Remove(jwt.SubjectKey) }),
+	}
+	for name, token := range rejected {
+		if _, err := verify(token, "user-1", "n-1"); err == nil {
+			t.Errorf("%s: token was accepted", name)
+		}
+	}
+
+	// A token signed with a new key is accepted once the keys are refetched.
+	issuer.rotate(t, "key-2")
+	if _, err := verify(issuer.token(t, nil), "user-1", ""); err != nil {
+		t.Fatalf("Verify() after key rotation: %v", err)
+	}
+}
+
+func TestOIDCProviderClientIDClaim(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDCProvider(OIDCConfig{ID: "test", Issuer: "{domain}", ClientIDClaim: "azp"})
+	params := &common.VerifierParams{ClientID: "client", Domain: issuer.URL}
+	verify := func(token string) error {
+		payload, _ := bijson.Marshal(OIDCVerifierParams{IDToken: token, UserID: "user-1"})
+		raw := bijson.RawMessage(payload)
+		_, _, err := provider.Verify(&raw, params)
+		return err
+	}
+
+	serverAudience := func(azp string) func(jwt.Token) {
+		return func(tok jwt.Token) {
+			_ = tok.Set(jwt.AudienceKey, "server")
+			_ = tok.Set("azp", azp)
+		}
+	}
+	if err := verify(issuer.token(t, serverAudience("client"))); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if err := verify(issuer.token(t, serverAudience("other"))); err == nil {
+		t.Error("token issued to another client was accepted")
+	}
+	if err := verify(issuer.token(t, nil)); err == nil {
+		t.Error("token without azp was accepted")
+	}
+}
@@ -0,0 +1,107 @@
+package verifier
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/webauthn"
+)
+
+// credentialRegistry is where registered passkeys are looked up.
+type credentialRegistry interface {
+	RetrievePasskeyCredential(appID, credentialID string) (common.PasskeyCredential, error)
+}
+
+// txBroadcaster sends BFT txs.
+type txBroadcaster interface {
+	Broadcast(tx interface{}) (common.Hash, error)
+}
+
+type PasskeySignCountTx struct {
+	AppID           string
+	CredentialID    string
+	SignCount       uint32
+	TokenCommitment string
+}
+
+// PasskeyProvider verifies WebAuthn assertions against the passkeys
+// registered for users. The token is a random value the client commits to in
+// KeyCommitmentRequest; the assertion has to be made over its keccak256, the
+// token commitment, so an assertion is only good for one share request. The
+// signature counters of credentials are kept in the BFT state.
+type PasskeyProvider struct {
+	registry credentialRegistry
+	txs      txBroadcaster
+}
+
+type PasskeyVerifierParams struct {
+	IDToken   string             `json:"id_token"`
+	UserID    string             `json:"user_id"`
+	AppID     string             `json:"app_id"`
+	Assertion webauthn.Assertion `json:"assertion"`
+}
+
+func NewPasskeyProvider(registry credentialRegistry, txs txBroadcaster) *PasskeyProvider {
+	return &PasskeyProvider{
+		registry: registry,
+		txs:      txs,
+	}
+}
+
+func (p *PasskeyProvider) ID() string {
+	return common.PasskeyVerifier
+}
+
+func (p *PasskeyProvider) CleanToken(token string) string {
+	return strings.Trim(token, " ")
+}
+
+// Verify checks the assertion with the relying party ID the app registered as
+// the domain of its passkey verifier.
+func (p *PasskeyProvider) Verify(rawPayload *bijson.RawMessage, params *common.VerifierParams) (bool, string, error) {
+	var payload PasskeyVerifierParams
+	if err := bijson.Unmarshal(*rawPayload, &payload); err != nil {
+		return false, "", err
+	}
+	payload.IDToken = p.CleanToken(payload.IDToken)
+	if payload.IDToken == "" || payload.UserID == "" || payload.AppID == "" || params == nil || params.Domain == "" {
+		return false, "", errors.New("invalid payload parameters")
+	}
+
+	credential, err := p.registry.RetrievePasskeyCredential(payload.AppID, payload.Assertion.CredentialID)
+	if err != nil {
+		return false, "", fmt.Errorf("passkey: %w", err)
+	}
+	if credential.UserID != payload.UserID {
+		return false, "", errors.New("passkey is not registered for the user")
+	}
+	challenge := secp256k1.Keccak256([]byte(payload.IDToken))
+	count, err := payload.Assertion.Verify(credential.PublicKey, credential.Algorithm, params.Domain, challenge)
+	if err != nil {
+		return false, "", fmt.Errorf("passkey: %w", err)
+	}
+	tokenCommitment := hex.EncodeToString(challenge)
+	if err := credential.CheckSignCount(count, tokenCommitment); err != nil {
+		return false, "", err
+	}
+	// Authenticators without a counter always report zero, which needs no
+	// record.
+	if count != 0 && count != credential.SignCount {
+		_, err := p.txs.Broadcast(PasskeySignCountTx{
+			AppID:           payload.AppID,
+			CredentialID:    credential.ID,
+			SignCount:       count,
+			TokenCommitment: tokenCommitment,
+		})
+		if err != nil {
+			return false, "", fmt.Errorf("passkey: could not record signature counter: %w", err)
+		}
+	}
+	return true, credential.UserID, nil
+}
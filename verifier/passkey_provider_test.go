@@ -0,0 +1,106 @@
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/secp256k1"
+	"github.com/arcana-network/dkgnode/webauthn"
+)
+
+type testRegistry map[string]common.PasskeyCredential
+
+func (r testRegistry) RetrievePasskeyCredential(appID, credentialID string) (common.PasskeyCredential, error) {
+	credential, ok := r[appID+"/"+credentialID]
+	if !ok {
+		return credential, errors.New("passkey is not registered")
+	}
+	return credential, nil
+}
+
+// Broadcast records the signature counter as the BFT app would.
+func (r testRegistry) Broadcast(tx interface{}) (common.Hash, error) {
+	countTx := tx.(PasskeySignCountTx)
+	key := countTx.AppID + "/" + countTx.CredentialID
+	credential := r[key]
+	if err := credential.CheckSignCount(countTx.SignCount, countTx.TokenCommitment); err != nil {
+		return common.Hash{}, err
+	}
+	credential.SignCount = countTx.SignCount
+	credential.SignCountToken = countTx.TokenCommitment
+	r[key] = credential
+	return common.Hash{}, nil
+}
+
+// passkeyAssertion signs an ES256 assertion for example.com over the
+// commitment of token.
+func passkeyAssertion(t *testing.T, key *ecdsa.PrivateKey, token string, count byte) webauthn.Assertion {
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+	authData := append(rpIDHash[:], 0x05, 0, 0, 0, count)
+	clientDataJSON, _ := json.Marshal(map[string]string{
+		"type":      "webauthn.get",
+		"challenge": base64.RawURLEncoding.EncodeToString(secp256k1.Keccak256([]byte(token))),
+		"origin":    "https://example.com",
+	})
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webauthn.Assertion{
+		CredentialID:      "cred-1",
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(authData),
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		Signature:         base64.RawURLEncoding.EncodeToString(sig),
+	}
+}
+
+func TestPasskeyProviderVerify(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	spki, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	registry := testRegistry{
+		"app/cred-1": {ID: "cred-1", AppID: "app", UserID: "alice", PublicKey: spki, Algorithm: webauthn.AlgES256},
+	}
+	provider := NewPasskeyProvider(registry, registry)
+	params := &common.VerifierParams{ClientID: "client", Domain: "example.com"}
+	verify := func(token, userID, appID string, assertion webauthn.Assertion) (string, error) {
+		payload, _ := bijson.Marshal(PasskeyVerifierParams{IDToken: token, UserID: userID, AppID: appID, Assertion: assertion})
+		raw := bijson.RawMessage(payload)
+		_, id, err := provider.Verify(&raw, params)
+		return id, err
+	}
+
+	if id, err := verify("token-1", "alice", "app", passkeyAssertion(t, key, "token-1", 1)); err != nil || id != "alice" {
+		t.Fatalf("Verify() = %q, %v", id, err)
+	}
+	// Every node verifies the same assertion of a share request.
+	if _, err := verify("token-1", "alice", "app", passkeyAssertion(t, key, "token-1", 1)); err != nil {
+		t.Errorf("assertion that set the counter was refused by another node: %v", err)
+	}
+	if _, err := verify("token-2", "alice", "app", passkeyAssertion(t, key, "token-2", 1)); err == nil {
+		t.Error("assertion with a replayed counter was accepted")
+	}
+	if _, err := verify("token-3", "alice", "app", passkeyAssertion(t, key, "token-2", 3)); err == nil {
+		t.Error("assertion over another token was accepted")
+	}
+	if _, err := verify("token-4", "bob", "app", passkeyAssertion(t, key, "token-4", 4)); err == nil {
+		t.Error("passkey of another user was accepted")
+	}
+	if _, err := verify("token-5", "alice", "other-app", passkeyAssertion(t, key, "token-5", 5)); err == nil {
+		t.Error("passkey of another app was accepted")
+	}
+	if _, err := verify("token-6", "alice", "app", passkeyAssertion(t, key, "token-6", 6)); err != nil {
+		t.Errorf("Verify() with a higher counter: %v", err)
+	}
+}
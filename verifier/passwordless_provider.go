@@ -21,7 +21,7 @@ type PasswordlessVerifier struct {
 }
 
 func NewPasswordlessProvider() *PasswordlessVerifier {
-	verifyUrl, err := url.Parse(config.GlobalConfig.PasswordlessUrl)
+	verifyUrl, err := url.Parse(config.Current().PasswordlessUrl)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,284 @@
+package verifier
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+const (
+	// siweMaxAge rejects messages issued longer ago, whatever their
+	// expiration time.
+	siweMaxAge = 10 * time.Minute
+	siweLeeway = 30 * time.Second
+	siweHeader = " wants you to sign in with your Ethereum account:"
+)
+
+var (
+	siweNonce = regexp.MustCompile(`^[A-Za-z0-9]{8,}$`)
+	// eip1271Selector is isValidSignature(bytes32,bytes), which is also the
+	// value contract wallets return for a valid signature.
+	eip1271Selector = crypto.Keccak256([]byte("isValidSignature(bytes32,bytes)"))[:4]
+)
+
+// chainReader is the part of the chain service needed to check signatures of
+// contract wallets.
+type chainReader interface {
+	ChainID() (*big.Int, error)
+	CallContract(to ethCommon.Address, data []byte) ([]byte, error)
+}
+
+// SIWEMessage is a Sign-In with Ethereum message, as defined by EIP-4361.
+type SIWEMessage struct {
+	Domain         string
+	Address        ethCommon.Address
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// SIWEProvider verifies Sign-In with Ethereum messages. The app registers the
+// domain users sign in to and, as client ID, the chain ID messages have to
+// name. Signatures of contract wallets are checked with EIP-1271 when the
+// message names the chain the node is connected to. Nonces are not tracked
+// per node: the message is the token of the share request, whose reuse the
+// token commitment flow refuses on every node, while retries of the same
+// flow are served.
+type SIWEProvider struct {
+	chain chainReader
+	now   func() time.Time
+}
+
+type SIWEVerifierParams struct {
+	Message   string `json:"id_token"`
+	Signature string `json:"signature"`
+	UserID    string `json:"user_id"`
+}
+
+func NewSIWEProvider(chain chainReader) *SIWEProvider {
+	return &SIWEProvider{
+		chain: chain,
+		now:   time.Now,
+	}
+}
+
+func (s *SIWEProvider) ID() string {
+	return "siwe"
+}
+
+// CleanToken leaves the message as is, since it is signed byte for byte.
+func (s *SIWEProvider) CleanToken(token string) string {
+	return token
+}
+
+func (s *SIWEProvider) Verify(rawPayload *bijson.RawMessage, params *common.VerifierParams) (bool, string, error) {
+	var p SIWEVerifierParams
+	if err := bijson.Unmarshal(*rawPayload, &p); err != nil {
+		return false, "", err
+	}
+	if p.Message == "" || p.Signature == "" || params == nil || params.Domain == "" {
+		return false, "", errors.New("invalid payload parameters")
+	}
+	msg, err := ParseSIWEMessage(p.Message)
+	if err != nil {
+		return false, "", err
+	}
+
+	if !strings.EqualFold(msg.Domain, params.Domain) {
+		return false, "", errors.New("message is for another domain")
+	}
+	if strconv.FormatInt(msg.ChainID, 10) != params.ClientID {
+		return false, "", errors.New("message is for another chain")
+	}
+	now := s.now()
+	if msg.IssuedAt.After(now.Add(siweLeeway)) || now.Sub(msg.IssuedAt) > siweMaxAge+siweLeeway {
+		return false, "", errors.New("message was not issued recently")
+	}
+	if !msg.ExpirationTime.IsZero() && now.After(msg.ExpirationTime.Add(siweLeeway)) {
+		return false, "", errors.New("message has expired")
+	}
+	if !msg.NotBefore.IsZero() && now.Add(siweLeeway).Before(msg.NotBefore) {
+		return false, "", errors.New("message is not valid yet")
+	}
+	verifierID := msg.Address.Hex()
+	if p.UserID != "" && !strings.EqualFold(p.UserID, verifierID) {
+		return false, "", errors.New("user id does not match the message")
+	}
+
+	signature, err := hexutil.Decode(p.Signature)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := s.checkSignature(msg, accounts.TextHash([]byte(p.Message)), signature); err != nil {
+		return false, "", err
+	}
+	return true, verifierID, nil
+}
+
+// checkSignature accepts signatures by the key of the address or, failing
+// that, signatures the contract at the address approves of.
+func (s *SIWEProvider) checkSignature(msg *SIWEMessage, hash, signature []byte) error {
+	if len(signature) == crypto.SignatureLength {
+		sig := append([]byte(nil), signature...)
+		if sig[crypto.RecoveryIDOffset] >= 27 {
+			sig[crypto.RecoveryIDOffset] -= 27
+		}
+		if pub, err := crypto.SigToPub(hash, sig); err == nil && crypto.PubkeyToAddress(*pub) == msg.Address {
+			return nil
+		}
+	}
+	if s.chain == nil {
+		return errors.New("invalid signature")
+	}
+	chainID, err := s.chain.ChainID()
+	if err != nil {
+		return fmt.Errorf("could not check contract wallet: %w", err)
+	}
+	if chainID.Cmp(big.NewInt(msg.ChainID)) != 0 {
+		return errors.New("invalid signature")
+	}
+	result, err := s.chain.CallContract(msg.Address, eip1271Call(hash, signature))
+	if err != nil {
+		return fmt.Errorf("could not check contract wallet: %w", err)
+	}
+	if len(result) < 4 || !bytes.Equal(result[:4], eip1271Selector) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// eip1271Call encodes isValidSignature(hash, signature).
+func eip1271Call(hash, signature []byte) []byte {
+	data := append([]byte(nil), eip1271Selector...)
+	data = append(data, ethCommon.LeftPadBytes(hash, 32)...)
+	data = append(data, ethCommon.LeftPadBytes(big.NewInt(64).Bytes(), 32)...)
+	data = append(data, ethCommon.LeftPadBytes(big.NewInt(int64(len(signature))).Bytes(), 32)...)
+	padded := make([]byte, (len(signature)+31)/32*32)
+	copy(padded, signature)
+	return append(data, padded...)
+}
+
+// ParseSIWEMessage parses and checks the syntax of an EIP-4361 message.
+func ParseSIWEMessage(message string) (*SIWEMessage, error) {
+	lines := strings.Split(message, "\n")
+	invalid := func(reason string) (*SIWEMessage, error) {
+		return nil, fmt.Errorf("invalid siwe message: %s", reason)
+	}
+	if len(lines) < 8 || !strings.HasSuffix(lines[0], siweHeader) {
+		return invalid("missing header")
+	}
+	msg := &SIWEMessage{Domain: strings.TrimSuffix(lines[0], siweHeader)}
+	if i := strings.Index(msg.Domain, "://"); i >= 0 {
+		msg.Domain = msg.Domain[i+3:]
+	}
+	if msg.Domain == "" || strings.ContainsAny(msg.Domain, " /") {
+		return invalid("bad domain")
+	}
+	if !ethCommon.IsHexAddress(lines[1]) || ethCommon.HexToAddress(lines[1]).Hex() != lines[1] {
+		return invalid("address is not checksummed")
+	}
+	msg.Address = ethCommon.HexToAddress(lines[1])
+	if lines[2] != "" {
+		return invalid("missing empty line after address")
+	}
+
+	// The statement is optional and followed by an empty line.
+	rest := lines[3:]
+	if len(rest) > 0 && rest[0] == "" {
+		rest = rest[1:]
+	} else if len(rest) > 1 && !strings.HasPrefix(rest[0], "URI: ") {
+		if rest[1] != "" || strings.Contains(rest[0], "\r") {
+			return invalid("bad statement")
+		}
+		msg.Statement = rest[0]
+		rest = rest[2:]
+	}
+
+	fields := []struct {
+		tag      string
+		required bool
+		value    *string
+	}{
+		{tag: "URI", required: true, value: &msg.URI},
+		{tag: "Version", required: true, value: &msg.Version},
+		{tag: "Chain ID", required: true},
+		{tag: "Nonce", required: true, value: &msg.Nonce},
+		{tag: "Issued At", required: true},
+		{tag: "Expiration Time"},
+		{tag: "Not Before"},
+		{tag: "Request ID", value: &msg.RequestID},
+	}
+	values := make(map[string]string)
+	for _, field := range fields {
+		if len(rest) > 0 && strings.HasPrefix(rest[0], field.tag+": ") {
+			values[field.tag] = strings.TrimPrefix(rest[0], field.tag+": ")
+			rest = rest[1:]
+		} else if field.required {
+			return invalid("missing " + field.tag)
+		}
+		if field.value != nil {
+			*field.value = values[field.tag]
+		}
+	}
+	if len(rest) > 0 && rest[0] == "Resources:" {
+		for _, line := range rest[1:] {
+			if !strings.HasPrefix(line, "- ") {
+				return invalid("bad resource")
+			}
+			msg.Resources = append(msg.Resources, strings.TrimPrefix(line, "- "))
+		}
+		rest = nil
+	}
+	if len(rest) > 0 {
+		return invalid("unexpected line " + strconv.Quote(rest[0]))
+	}
+
+	if msg.URI == "" {
+		return invalid("empty URI")
+	}
+	if msg.Version != "1" {
+		return invalid("unsupported version")
+	}
+	chainID, err := strconv.ParseInt(values["Chain ID"], 10, 64)
+	if err != nil || chainID <= 0 {
+		return invalid("bad chain id")
+	}
+	msg.ChainID = chainID
+	if !siweNonce.MatchString(msg.Nonce) {
+		return invalid("nonce must be at least 8 alphanumeric characters")
+	}
+	times := map[string]*time.Time{
+		"Issued At":       &msg.IssuedAt,
+		"Expiration Time": &msg.ExpirationTime,
+		"Not Before":      &msg.NotBefore,
+	}
+	for tag, t := range times {
+		if values[tag] == "" {
+			continue
+		}
+		if *t, err = time.Parse(time.RFC3339Nano, values[tag]); err != nil {
+			return invalid("bad " + tag)
+		}
+	}
+	return msg, nil
+}
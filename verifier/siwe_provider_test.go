@@ -0,0 +1,117 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/torusresearch/bijson"
+
+	"github.com/arcana-network/dkgnode/common"
+)
+
+// testWallet is a contract wallet that approves signatures of its owner.
+type testWallet struct {
+	address ethCommon.Address
+	owner   ethCommon.Address
+}
+
+func (w *testWallet) ChainID() (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (w *testWallet) CallContract(to ethCommon.Address, data []byte) ([]byte, error) {
+	if to != w.address || !bytes.Equal(data[:4], eip1271Selector) {
+		return nil, fmt.Errorf("unexpected call")
+	}
+	hash, size := data[4:36], new(big.Int).SetBytes(data[68:100]).Int64()
+	sig := append([]byte(nil), data[100:100+size]...)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != w.owner {
+		return make([]byte, 32), nil
+	}
+	return ethCommon.RightPadBytes(eip1271Selector, 32), nil
+}
+
+func siweMessage(address ethCommon.Address, nonce string, issuedAt time.Time, extra string) string {
+	return "example.com wants you to sign in with your Ethereum account:\n" +
+		address.Hex() + "\n\nSign in to Example.\n\n" +
+		"URI: https://example.com/login\nVersion: 1\nChain ID: 1\n" +
+		"Nonce: " + nonce + "\nIssued At: " + issuedAt.Format(time.RFC3339) + extra
+}
+
+func signSIWE(t *testing.T, key *ecdsa.PrivateKey, message string) string {
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig)
+}
+
+func TestSIWEProviderVerify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	now := time.Now().Truncate(time.Second)
+	wallet := &testWallet{address: ethCommon.HexToAddress("0x00000000000000000000000000000000000000aa"), owner: address}
+	provider := NewSIWEProvider(wallet)
+	params := &common.VerifierParams{ClientID: "1", Domain: "example.com"}
+	verify := func(message, signature, userID string) (string, error) {
+		payload, _ := bijson.Marshal(SIWEVerifierParams{Message: message, Signature: signature, UserID: userID})
+		raw := bijson.RawMessage(payload)
+		_, id, err := provider.Verify(&raw, params)
+		return id, err
+	}
+
+	message := siweMessage(address, "abcdef0001", now, "\nExpiration Time: "+now.Add(time.Minute).Format(time.RFC3339)+"\nResources:\n- https://example.com/terms")
+	if id, err := verify(message, signSIWE(t, key, message), strings.ToLower(address.Hex())); err != nil || id != address.Hex() {
+		t.Fatalf("Verify() = %q, %v", id, err)
+	}
+	// Retries of a request whose share was not released yet are served.
+	if _, err := verify(message, signSIWE(t, key, message), ""); err != nil {
+		t.Errorf("retried message was refused: %v", err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	rejected := map[string]string{
+		"domain":  strings.Replace(siweMessage(address, "abcdef0002", now, ""), "example.com wants", "evil.com wants", 1),
+		"chain":   strings.Replace(siweMessage(address, "abcdef0003", now, ""), "Chain ID: 1", "Chain ID: 5", 1),
+		"old":     siweMessage(address, "abcdef0004", now.Add(-time.Hour), ""),
+		"expired": siweMessage(address, "abcdef0005", now, "\nExpiration Time: "+now.Add(-time.Minute).Format(time.RFC3339)),
+		"early":   siweMessage(address, "abcdef0006", now, "\nNot Before: "+now.Add(time.Hour).Format(time.RFC3339)),
+		"nonce":   siweMessage(address, "short", now, ""),
+		"version": strings.Replace(siweMessage(address, "abcdef0007", now, ""), "Version: 1", "Version: 2", 1),
+		"address": strings.Replace(siweMessage(address, "abcdef0008", now, ""), address.Hex(), strings.ToLower(address.Hex()), 1),
+	}
+	for name, message := range rejected {
+		if _, err := verify(message, signSIWE(t, key, message), ""); err == nil {
+			t.Errorf("%s: message was accepted", name)
+		}
+	}
+	message = siweMessage(address, "abcdef0009", now, "")
+	if _, err := verify(message, signSIWE(t, other, message), ""); err == nil {
+		t.Error("message signed by another key was accepted")
+	}
+	if _, err := verify(message, signSIWE(t, key, message), "0x0000000000000000000000000000000000000001"); err == nil {
+		t.Error("message of another user was accepted")
+	}
+
+	// Contract wallets are checked through EIP-1271.
+	message = siweMessage(wallet.address, "abcdef0010", now, "")
+	if id, err := verify(message, signSIWE(t, key, message), ""); err != nil || id != wallet.address.Hex() {
+		t.Fatalf("Verify(contract wallet) = %q, %v", id, err)
+	}
+	message = siweMessage(wallet.address, "abcdef0011", now, "")
+	if _, err := verify(message, signSIWE(t, other, message), ""); err == nil {
+		t.Error("contract wallet signature of a stranger was accepted")
+	}
+}
@@ -19,7 +19,7 @@ type SteamProvider struct {
 }
 
 func NewSteamProvider() *SteamProvider {
-	endpoint, _ := url.Parse(config.GlobalConfig.OAuthUrl)
+	endpoint, _ := url.Parse(config.Current().OAuthUrl)
 	endpoint.Path = "/api/steam/verify"
 	endpoint.RawQuery = "token="
 
@@ -14,11 +14,10 @@ import (
 	"github.com/torusresearch/bijson"
 
 	"github.com/arcana-network/dkgnode/config"
-	"github.com/arcana-network/dkgnode/keygen"
 )
 
 func NewTwitterProvider() *TwitterVerifier {
-	signatureUrl, err := url.Parse(config.GlobalConfig.OAuthUrl)
+	signatureUrl, err := url.Parse(config.Current().OAuthUrl)
 	if err != nil {
 		panic(err)
 	}
@@ -120,8 +119,10 @@ func (c *GetSignatureMessage) String() string {
 }
 
 func getSignatureParams(token, secret, appID string) ([]byte, error) {
-	pubKey := serviceMapper.ChainMethods().GetSelfPublicKey()
-	privKey := serviceMapper.ChainMethods().GetSelfPrivateKey()
+	pubKey, err := serviceMapper.ChainMethods().GetSelfPublicKey()
+	if err != nil {
+		return nil, err
+	}
 	getSignatureMessage := GetSignatureMessage{
 		OauthToken:       token,
 		OauthTokenSecret: secret,
@@ -129,7 +130,10 @@ func getSignatureParams(token, secret, appID string) ([]byte, error) {
 		Timestamp:        strconv.FormatInt(time.Now().Unix(), 10),
 		NodeAddress:      serviceMapper.ChainMethods().GetSelfAddress(),
 	}
-	sig := keygen.ECDSASign(getSignatureMessage.String(), &privKey)
+	sig, err := serviceMapper.ChainMethods().SelfSignData([]byte(getSignatureMessage.String()))
+	if err != nil {
+		return nil, err
+	}
 	getSigParams := GetSignatureParams{
 		PubKeyX:             pubKey.X.Text(16),
 		PubKeyY:             pubKey.Y.Text(16),
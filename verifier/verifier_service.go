@@ -4,9 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/arcana-network/dkgnode/common"
+	"github.com/arcana-network/dkgnode/config"
 	"github.com/arcana-network/dkgnode/eventbus"
+	log "github.com/sirupsen/logrus"
 	"github.com/torusresearch/bijson"
 )
 
@@ -15,6 +19,7 @@ var ErrorIDNotVerified = errors.New("user_not_verified")
 type VerifierService struct {
 	providerMap *ProviderMap
 	bus         eventbus.Bus
+	running     atomic.Bool
 }
 
 type VerifyMessage struct {
@@ -30,7 +35,10 @@ type Provider interface {
 }
 
 type ProviderMap struct {
+	mu        sync.RWMutex
 	Providers map[string]Provider
+	// declared are the IDs of the providers from the verifiers file.
+	declared map[string]bool
 }
 
 var serviceMapper *common.MessageBroker
@@ -70,6 +78,8 @@ func getVerifierParams(serviceMapper *common.MessageBroker, appID, verifier stri
 }
 
 func (tgv *ProviderMap) Lookup(provider string) (Provider, error) {
+	tgv.mu.RLock()
+	defer tgv.mu.RUnlock()
 	if tgv.Providers == nil {
 		return nil, errors.New("providers mapping not initialized")
 	}
@@ -92,24 +102,100 @@ func NewProviderMap(providers []Provider) *ProviderMap {
 	return providerMap
 }
 
+// Replace swaps in providers, keyed by their ID, while verifications run.
+func (tgv *ProviderMap) Replace(providers ...Provider) {
+	tgv.mu.Lock()
+	defer tgv.mu.Unlock()
+	for _, provider := range providers {
+		tgv.Providers[provider.ID()] = provider
+	}
+}
+
+// SetDeclared replaces the providers declared in the verifiers file. They
+// cannot take the ID of a built in provider.
+func (tgv *ProviderMap) SetDeclared(providers []Provider) error {
+	tgv.mu.Lock()
+	defer tgv.mu.Unlock()
+	for _, provider := range providers {
+		if tgv.Providers[provider.ID()] != nil && !tgv.declared[provider.ID()] {
+			return fmt.Errorf("verifier %s is built in", provider.ID())
+		}
+	}
+	for id := range tgv.declared {
+		delete(tgv.Providers, id)
+	}
+	tgv.declared = make(map[string]bool)
+	for _, provider := range providers {
+		tgv.Providers[provider.ID()] = provider
+		tgv.declared[provider.ID()] = true
+	}
+	return nil
+}
+
 func New(bus eventbus.Bus) *VerifierService {
 	verifierService := VerifierService{
 		bus: bus,
 	}
 	serviceMapper = common.NewServiceBroker(bus, common.VERIFIER_SERVICE_NAME)
+	config.OnReload(verifierService.reloadEndpoints)
+	config.OnReload(verifierService.reloadDeclared)
 	return &verifierService
 }
 
-func (*VerifierService) ID() string {
-	return common.VERIFIER_SERVICE_NAME
+// reloadEndpoints rebuilds the providers that call the configured passwordless
+// and oauth services. The custom provider reads the gateway url on every call.
+func (v *VerifierService) reloadEndpoints(old, new *config.Config) {
+	if v.providerMap == nil || (old.PasswordlessUrl == new.PasswordlessUrl && old.OAuthUrl == new.OAuthUrl) {
+		return
+	}
+	v.providerMap.Replace(
+		NewTwitterProvider(),
+		NewPasswordlessProvider(),
+		NewSteamProvider(),
+	)
 }
 
-func (v *VerifierService) Start() error {
-	firebaseProvider, err := NewFirebaseProvider()
-	if err != nil {
+// reloadDeclared rereads the verifiers file, which may have changed even if
+// its path did not. On error the providers loaded before are kept.
+func (v *VerifierService) reloadDeclared(old, new *config.Config) {
+	if v.providerMap == nil || (old.VerifiersFile == "" && new.VerifiersFile == "") {
+		return
+	}
+	if err := v.loadDeclared(new.VerifiersFile); err != nil {
+		log.WithError(err).Error("could not reload verifiers")
+	}
+}
+
+// loadDeclared builds the providers declared in the verifiers file, none if
+// path is empty.
+func (v *VerifierService) loadDeclared(path string) error {
+	var defs []config.VerifierDefinition
+	if path != "" {
+		var err error
+		if defs, err = config.ReadVerifiersFile(path); err != nil {
+			return err
+		}
+	}
+	providers := make([]Provider, 0, len(defs))
+	for _, def := range defs {
+		provider, err := NewDeclaredProvider(def)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, provider)
+	}
+	if err := v.providerMap.SetDeclared(providers); err != nil {
 		return err
 	}
+	log.WithField("count", len(providers)).Info("declared verifiers loaded")
+	return nil
+}
+
+func (*VerifierService) ID() string {
+	return common.VERIFIER_SERVICE_NAME
+}
 
+func (v *VerifierService) Start() error {
 	providers := []Provider{
 		NewGoogleProvider(),
 		NewDiscordProvider(),
@@ -117,22 +203,28 @@ func (v *VerifierService) Start() error {
 		NewGithubProvider(),
 		NewTwitterProvider(),
 		NewPasswordlessProvider(),
-		NewAWSCognitoVerifier(),
+		NewAWSCognitoProvider(),
 		NewSteamProvider(),
-		firebaseProvider,
+		NewFirebaseProvider(),
 		NewGlobalKeyVerifier(v),
 		NewCustomProvider(),
+		NewSIWEProvider(serviceMapper.ChainMethods()),
+		NewPasskeyProvider(serviceMapper.ABCIMethods(), serviceMapper.TendermintMethods()),
 		// NewXProvider(),
 	}
 	v.providerMap = NewProviderMap(providers)
+	if err := v.loadDeclared(config.Current().VerifiersFile); err != nil {
+		return err
+	}
+	v.running.Store(true)
 	return nil
-
 }
 func (v *VerifierService) Stop() error {
+	v.running.Store(false)
 	return nil
 }
 func (v *VerifierService) IsRunning() bool {
-	return true
+	return v.running.Load()
 }
 func (v *VerifierService) Call(method string, args ...interface{}) (interface{}, error) {
 	switch method {
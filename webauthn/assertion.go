@@ -0,0 +1,181 @@
+// Package webauthn verifies WebAuthn assertions made with passkeys.
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// COSE algorithms of the supported credentials.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+)
+
+// Assertion is the response of an authenticator to navigator.credentials.get,
+// with the binary fields encoded as base64url.
+type Assertion struct {
+	CredentialID      string `json:"credential_id"`
+	AuthenticatorData string `json:"authenticator_data"`
+	ClientDataJSON    string `json:"client_data_json"`
+	Signature         string `json:"signature"`
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParsePublicKey parses a credential public key in the SubjectPublicKeyInfo
+// form browsers return from getPublicKey(), checking that it suits the COSE
+// algorithm of the credential.
+func ParsePublicKey(spki []byte, alg int) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(spki)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if alg == AlgES256 && k.Curve == elliptic.P256() {
+			return k, nil
+		}
+	case *rsa.PublicKey:
+		if alg == AlgRS256 && k.N.BitLen() >= 2048 {
+			return k, nil
+		}
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("public key does not suit algorithm %d", alg)
+}
+
+// Verify checks an assertion made for the relying party rpID over challenge,
+// with user presence and verification, and returns its signature counter.
+func (a *Assertion) Verify(spki []byte, alg int, rpID string, challenge []byte) (uint32, error) {
+	key, err := ParsePublicKey(spki, alg)
+	if err != nil {
+		return 0, err
+	}
+	authData, err := Decode(a.AuthenticatorData)
+	if err != nil {
+		return 0, fmt.Errorf("invalid authenticator data: %w", err)
+	}
+	clientDataJSON, err := Decode(a.ClientDataJSON)
+	if err != nil {
+		return 0, fmt.Errorf("invalid client data: %w", err)
+	}
+	signature, err := Decode(a.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	var client clientData
+	if err := json.Unmarshal(clientDataJSON, &client); err != nil {
+		return 0, fmt.Errorf("invalid client data: %w", err)
+	}
+	if client.Type != "webauthn.get" {
+		return 0, errors.New("client data is not for an assertion")
+	}
+	if got, err := Decode(client.Challenge); err != nil || !bytes.Equal(got, challenge) {
+		return 0, errors.New("challenge mismatch")
+	}
+	if client.CrossOrigin || !originOf(client.Origin, rpID) {
+		return 0, errors.New("origin is not of the relying party")
+	}
+
+	if len(authData) < 37 {
+		return 0, errors.New("authenticator data is too short")
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(authData[:32], rpIDHash[:]) {
+		return 0, errors.New("assertion is for another relying party")
+	}
+	flags := authData[32]
+	if flags&flagUserPresent == 0 || flags&flagUserVerified == 0 {
+		return 0, errors.New("user was not present and verified")
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+	if !verifySignature(key, signed, signature) {
+		return 0, errors.New("invalid signature")
+	}
+	return binary.BigEndian.Uint32(authData[33:37]), nil
+}
+
+func verifySignature(key crypto.PublicKey, signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, signed, signature)
+	}
+	return false
+}
+
+// originOf reports whether origin is served by the relying party, on its
+// domain or a subdomain. Plain http is only accepted for localhost.
+func originOf(origin, rpID string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if u.Scheme != "https" && !(u.Scheme == "http" && host == "localhost") {
+		return false
+	}
+	return host == rpID || strings.HasSuffix(host, "."+rpID)
+}
+
+// CheckSignCount checks that the counter of a credential went up since the
+// last assertion. Authenticators without a counter always report zero.
+func CheckSignCount(last, current uint32) error {
+	if current == 0 && last == 0 {
+		return nil
+	}
+	if current <= last {
+		return errors.New("signature counter did not increase, the credential may be cloned")
+	}
+	return nil
+}
+
+// ApprovalChallenge is the challenge a registered credential signs to approve
+// registering another credential for the same user.
+func ApprovalChallenge(credentialID string, publicKey []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(credentialID))
+	h.Write(publicKey)
+	return h.Sum(nil)
+}
+
+// Decode decodes base64url, with or without padding.
+func Decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,115 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+type testAuthenticator struct {
+	signer crypto.Signer
+	alg    int
+	spki   []byte
+}
+
+func newTestAuthenticator(t *testing.T, alg int) *testAuthenticator {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{signer: signer, alg: alg, spki: spki}
+}
+
+func (a *testAuthenticator) assert(t *testing.T, rpID, origin string, challenge []byte, flags byte, count uint32) Assertion {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], count)
+	clientDataJSON, _ := json.Marshal(map[string]interface{}{
+		"type":      "webauthn.get",
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+
+	var sig []byte
+	var err error
+	if a.alg == AlgEdDSA {
+		sig, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		sig, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Assertion{
+		CredentialID:      "cred",
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(authData),
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		Signature:         base64.RawURLEncoding.EncodeToString(sig),
+	}
+}
+
+func TestAssertionVerify(t *testing.T) {
+	challenge := []byte("challenge")
+	for _, alg := range []int{AlgES256, AlgRS256, AlgEdDSA} {
+		a := newTestAuthenticator(t, alg)
+		assertion := a.assert(t, "example.com", "https://login.example.com", challenge, flagUserPresent|flagUserVerified, 7)
+		if count, err := assertion.Verify(a.spki, alg, "example.com", challenge); err != nil || count != 7 {
+			t.Errorf("alg %d: Verify() = %d, %v", alg, count, err)
+		}
+	}
+
+	a := newTestAuthenticator(t, AlgES256)
+	other := newTestAuthenticator(t, AlgES256)
+	good := a.assert(t, "example.com", "https://example.com", challenge, flagUserPresent|flagUserVerified, 1)
+	rejected := map[string]func() (uint32, error){
+		"challenge": func() (uint32, error) { return good.Verify(a.spki, AlgES256, "example.com", []byte("other")) },
+		"rp id":     func() (uint32, error) { return good.Verify(a.spki, AlgES256, "evil.com", challenge) },
+		"key":       func() (uint32, error) { return good.Verify(other.spki, AlgES256, "example.com", challenge) },
+		"algorithm": func() (uint32, error) { return good.Verify(a.spki, AlgRS256, "example.com", challenge) },
+		"origin": func() (uint32, error) {
+			assertion := a.assert(t, "example.com", "https://example.com.evil.com", challenge, flagUserPresent|flagUserVerified, 1)
+			return assertion.Verify(a.spki, AlgES256, "example.com", challenge)
+		},
+		"not verified": func() (uint32, error) {
+			assertion := a.assert(t, "example.com", "https://example.com", challenge, flagUserPresent, 1)
+			return assertion.Verify(a.spki, AlgES256, "example.com", challenge)
+		},
+	}
+	for name, verify := range rejected {
+		if _, err := verify(); err == nil {
+			t.Errorf("%s: assertion was accepted", name)
+		}
+	}
+
+	if CheckSignCount(0, 0) != nil || CheckSignCount(3, 4) != nil {
+		t.Error("increasing counter was rejected")
+	}
+	if CheckSignCount(4, 4) == nil || CheckSignCount(4, 0) == nil {
+		t.Error("counter that did not increase was accepted")
+	}
+}